@@ -0,0 +1,224 @@
+package qb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AlterTableActionKind is the kind of an AlterTableAction
+type AlterTableActionKind int
+
+// The kinds of ALTER TABLE actions
+const (
+	AlterAddColumn AlterTableActionKind = iota
+	AlterDropColumn
+	AlterColumnType
+	AlterSetDefault
+	AlterDropDefault
+	AlterSetNotNull
+	AlterRenameColumn
+	AlterRenameTo
+	AlterAddConstraint
+	AlterDropConstraint
+)
+
+// AlterTableAction is a single action of an ALTER TABLE statement
+type AlterTableAction struct {
+	Kind AlterTableActionKind
+	// Column is the added column for AlterAddColumn, and only holds the
+	// name of the column for the other column actions
+	Column ColumnElem
+	// Name is the new name of AlterRenameColumn and AlterRenameTo, and the
+	// constraint name of AlterDropConstraint
+	Name string
+	// Type is the new type of AlterColumnType
+	Type TypeElem
+	// Default is the default value expression of AlterSetDefault
+	Default string
+	// Constraint is the added ForeignKeyConstraint or UniqueKeyConstraint
+	Constraint TableSQLClause
+}
+
+// AlterTableCompiler is an optional Dialect capability: the dialects
+// implementing it have their own rendering of the ALTER TABLE statements.
+// The others are rendered by DefaultCompileAlterTable
+type AlterTableCompiler interface {
+	CompileAlterTable(stmt AlterTableStmt) (string, error)
+}
+
+// AlterTable generates an alter table statement and returns it for chaining
+// qb.AlterTable(usersTable).AddColumn(qb.Column("email", qb.Varchar()))
+func AlterTable(table TableElem) AlterTableStmt {
+	return AlterTableStmt{Table: table}
+}
+
+// AlterTableStmt is the base struct for building alter table statements
+type AlterTableStmt struct {
+	Table   TableElem
+	Actions []AlterTableAction
+}
+
+func (s AlterTableStmt) add(action AlterTableAction) AlterTableStmt {
+	actions := make([]AlterTableAction, len(s.Actions), len(s.Actions)+1)
+	copy(actions, s.Actions)
+	s.Actions = append(actions, action)
+	return s
+}
+
+// Col returns the definition of a column of the altered table, or a
+// column without type if the table does not define it
+func (s AlterTableStmt) Col(name string) ColumnElem {
+	if col, ok := s.Table.Lookup(name); ok {
+		return col
+	}
+	return ColumnElem{Name: name, Table: s.Table.Name}
+}
+
+// AddColumn adds a column to the table
+func (s AlterTableStmt) AddColumn(column ColumnElem) AlterTableStmt {
+	return s.add(AlterTableAction{Kind: AlterAddColumn, Column: column})
+}
+
+// DropColumn drops a column of the table
+func (s AlterTableStmt) DropColumn(name string) AlterTableStmt {
+	return s.add(AlterTableAction{Kind: AlterDropColumn, Column: s.Col(name)})
+}
+
+// AlterColumnType changes the type of a column
+func (s AlterTableStmt) AlterColumnType(name string, t TypeElem) AlterTableStmt {
+	return s.add(AlterTableAction{Kind: AlterColumnType, Column: s.Col(name), Type: t})
+}
+
+// SetDefault sets the default value of a column
+func (s AlterTableStmt) SetDefault(name string, value interface{}) AlterTableStmt {
+	def := strings.TrimPrefix(Default(value).String(), "DEFAULT ")
+	return s.add(AlterTableAction{Kind: AlterSetDefault, Column: s.Col(name), Default: def})
+}
+
+// DropDefault drops the default value of a column
+func (s AlterTableStmt) DropDefault(name string) AlterTableStmt {
+	return s.add(AlterTableAction{Kind: AlterDropDefault, Column: s.Col(name)})
+}
+
+// SetNotNull adds a not null constraint to a column
+func (s AlterTableStmt) SetNotNull(name string) AlterTableStmt {
+	return s.add(AlterTableAction{Kind: AlterSetNotNull, Column: s.Col(name)})
+}
+
+// RenameColumn renames a column
+func (s AlterTableStmt) RenameColumn(name string, newName string) AlterTableStmt {
+	return s.add(AlterTableAction{Kind: AlterRenameColumn, Column: s.Col(name), Name: newName})
+}
+
+// RenameTo renames the table
+func (s AlterTableStmt) RenameTo(name string) AlterTableStmt {
+	return s.add(AlterTableAction{Kind: AlterRenameTo, Name: name})
+}
+
+// AddConstraint adds a ForeignKeyConstraint or a UniqueKeyConstraint to the
+// table. A unique key without name is named after the table.
+func (s AlterTableStmt) AddConstraint(constraint TableSQLClause) AlterTableStmt {
+	if uniqueKey, ok := constraint.(UniqueKeyConstraint); ok {
+		constraint = uniqueKey.Table(s.Table.Name)
+	}
+	return s.add(AlterTableAction{Kind: AlterAddConstraint, Constraint: constraint})
+}
+
+// DropConstraint drops a named constraint of the table
+func (s AlterTableStmt) DropConstraint(name string) AlterTableStmt {
+	return s.add(AlterTableAction{Kind: AlterDropConstraint, Name: name})
+}
+
+// Build generates a statement out of AlterTableStmt object. If the dialect
+// cannot render the actions, the statement holds an ErrNotSupported Error
+func (s AlterTableStmt) Build(dialect Dialect) *Stmt {
+	statement := Statement()
+	var sql string
+	var err error
+	if compiler, ok := dialect.(AlterTableCompiler); ok {
+		sql, err = compiler.CompileAlterTable(s)
+	} else {
+		sql, err = DefaultCompileAlterTable(dialect, s)
+	}
+	if err != nil {
+		statement.SetError(err)
+		return statement
+	}
+	statement.AddSQLClause(sql)
+	return statement
+}
+
+// errAlterTableNotSupported returns a ErrNotSupported Error for an action
+// the dialect cannot render
+func errAlterTableNotSupported(dialect Dialect, stmt AlterTableStmt, action AlterTableAction, reason string) Error {
+	return Error{
+		Code:   ErrNotSupported,
+		Orig:   fmt.Errorf("Cannot alter table %s with the %s dialect: %s", stmt.Table.Name, dialect.Driver(), reason),
+		Table:  stmt.Table.Name,
+		Column: action.Column.Name,
+	}
+}
+
+// DefaultCompileAlterTable is a default implementation of the ALTER TABLE
+// rendering, with the syntax of the SQL standard. The actions are separated
+// by commas, except the renames which cannot be combined with other actions
+func DefaultCompileAlterTable(dialect Dialect, stmt AlterTableStmt) (string, error) {
+	if len(stmt.Actions) == 0 {
+		return "", errAlterTableNotSupported(dialect, stmt, AlterTableAction{}, "no action")
+	}
+	var actions []string
+	for _, action := range stmt.Actions {
+		if len(stmt.Actions) > 1 && (action.Kind == AlterRenameColumn || action.Kind == AlterRenameTo) {
+			return "", errAlterTableNotSupported(dialect, stmt, action, "a rename cannot be combined with other actions")
+		}
+		sql, err := DefaultCompileAlterTableAction(dialect, stmt, action)
+		if err != nil {
+			return "", err
+		}
+		actions = append(actions, sql)
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s", dialect.Escape(stmt.Table.Name), strings.Join(actions, ", ")), nil
+}
+
+// DefaultCompileAlterTableAction renders an action of an ALTER TABLE
+// statement with the syntax of the SQL standard
+func DefaultCompileAlterTableAction(dialect Dialect, stmt AlterTableStmt, action AlterTableAction) (string, error) {
+	column := dialect.Escape(action.Column.Name)
+	switch action.Kind {
+	case AlterAddColumn:
+		if err := ColumnErr(dialect, action.Column); err != nil {
+			return "", err
+		}
+		return "ADD COLUMN " + action.Column.String(dialect), nil
+	case AlterDropColumn:
+		return "DROP COLUMN " + column, nil
+	case AlterColumnType:
+		return "ALTER COLUMN " + column + " TYPE " + dialect.CompileType(action.Type), nil
+	case AlterSetDefault:
+		return "ALTER COLUMN " + column + " SET DEFAULT " + action.Default, nil
+	case AlterDropDefault:
+		return "ALTER COLUMN " + column + " DROP DEFAULT", nil
+	case AlterSetNotNull:
+		return "ALTER COLUMN " + column + " SET NOT NULL", nil
+	case AlterRenameColumn:
+		return "RENAME COLUMN " + column + " TO " + dialect.Escape(action.Name), nil
+	case AlterRenameTo:
+		return "RENAME TO " + dialect.Escape(action.Name), nil
+	case AlterAddConstraint:
+		switch constraint := action.Constraint.(type) {
+		case ForeignKeyConstraint:
+			if err := constraint.Err(); err != nil {
+				return "", err
+			}
+			return "ADD " + strings.TrimSpace(constraint.String(dialect)), nil
+		case UniqueKeyConstraint:
+			return "ADD " + constraint.String(dialect), nil
+		default:
+			return "", errAlterTableNotSupported(dialect, stmt, action, fmt.Sprintf("cannot add a %T constraint", constraint))
+		}
+	case AlterDropConstraint:
+		return "DROP CONSTRAINT " + dialect.Escape(action.Name), nil
+	default:
+		return "", errAlterTableNotSupported(dialect, stmt, action, fmt.Sprintf("unknown action %d", action.Kind))
+	}
+}
@@ -0,0 +1,78 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlterTable(t *testing.T) {
+	dialect := NewDefaultDialect()
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("email", Varchar()),
+	)
+
+	sql := AlterTable(users).AddColumn(Column("age", Int()).NotNull()).Build(dialect).SQL()
+	assert.Equal(t, "ALTER TABLE users ADD COLUMN age INT NOT NULL;", sql)
+
+	sql = AlterTable(users).
+		DropColumn("email").
+		AlterColumnType("id", BigInt()).
+		SetDefault("age", 18).
+		DropDefault("name").
+		SetNotNull("name").
+		Build(dialect).SQL()
+	assert.Equal(t, "ALTER TABLE users DROP COLUMN email, "+
+		"ALTER COLUMN id TYPE BIGINT, "+
+		"ALTER COLUMN age SET DEFAULT '18', "+
+		"ALTER COLUMN name DROP DEFAULT, "+
+		"ALTER COLUMN name SET NOT NULL;", sql)
+
+	sql = AlterTable(users).RenameColumn("email", "mail").Build(dialect).SQL()
+	assert.Equal(t, "ALTER TABLE users RENAME COLUMN email TO mail;", sql)
+
+	sql = AlterTable(users).RenameTo("members").Build(dialect).SQL()
+	assert.Equal(t, "ALTER TABLE users RENAME TO members;", sql)
+
+	fkey := ForeignKey("group_id").References("groups", "id").OnDelete("CASCADE")
+	fkey.Name = "fk_group"
+	sql = AlterTable(users).AddConstraint(fkey).Build(dialect).SQL()
+	assert.Equal(t, "ALTER TABLE users ADD CONSTRAINT fk_group FOREIGN KEY(group_id) REFERENCES groups(id) ON DELETE CASCADE;", sql)
+
+	sql = AlterTable(users).AddConstraint(UniqueKey("email", "name")).Build(dialect).SQL()
+	assert.Equal(t, "ALTER TABLE users ADD CONSTRAINT u_users_email_name UNIQUE(email, name);", sql)
+
+	sql = AlterTable(users).DropConstraint("fk_group").Build(dialect).SQL()
+	assert.Equal(t, "ALTER TABLE users DROP CONSTRAINT fk_group;", sql)
+}
+
+func TestAlterTableNotSupported(t *testing.T) {
+	dialect := NewDefaultDialect()
+	users := Table("users", Column("id", Int()))
+
+	base := AlterTable(users)
+	stmt := base.RenameTo("members").DropColumn("id").Build(dialect)
+	assert.Equal(t, "", stmt.SQL())
+	assert.Equal(t, ErrNotSupported, stmt.Err().(Error).Code)
+	assert.Equal(t, "users", stmt.Err().(Error).Table)
+
+	// the builder is not modified by the chained calls
+	assert.Len(t, base.Actions, 0)
+
+	stmt = base.Build(dialect)
+	assert.Equal(t, ErrNotSupported, stmt.Err().(Error).Code)
+
+	stmt = base.AddConstraint(PrimaryKey("id")).Build(dialect)
+	assert.Equal(t, ErrNotSupported, stmt.Err().(Error).Code)
+
+	stmt = base.AddColumn(users.C("missing")).Build(dialect)
+	assert.Equal(t, ErrInterface, stmt.Err().(Error).Code)
+
+	stmt = base.AddConstraint(ForeignKey("id").References("members", "id").OnUpdate("bogus")).Build(dialect)
+	assert.Equal(t, ErrInterface, stmt.Err().(Error).Code)
+
+	stmt = base.DropColumn("id").Build(dialect)
+	assert.Nil(t, stmt.Err())
+}
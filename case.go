@@ -0,0 +1,50 @@
+package qb
+
+// Case starts a CASE expression. Without a value, the WHEN conditions are
+// boolean clauses
+// Case().When(users.C("age").Lt(18), "minor").Else("adult")
+// Given a value, the WHEN values are compared to it
+// Case(users.C("status")).When(1, "active").When(2, "banned")
+// The values that are not clauses are bound
+func Case(value ...Clause) CaseClause {
+	c := CaseClause{}
+	if len(value) > 0 {
+		c.Value = value[0]
+	}
+	return c
+}
+
+// CaseClause is a CASE [value] WHEN ... THEN ... [ELSE ...] END expression
+type CaseClause struct {
+	// Value is the value compared to the WHEN values, if any
+	Value Clause
+	Whens []WhenClause
+	// ElseResult is the result if no WHEN matches, NULL if nil
+	ElseResult Clause
+}
+
+// WhenClause is a WHEN ... THEN ... branch of a CASE expression
+type WhenClause struct {
+	Condition Clause
+	Result    Clause
+}
+
+// When appends a WHEN condition THEN result branch
+func (c CaseClause) When(condition interface{}, result interface{}) CaseClause {
+	c.Whens = append(c.Whens[:len(c.Whens):len(c.Whens)], WhenClause{
+		GetClauseFrom(condition),
+		GetClauseFrom(result),
+	})
+	return c
+}
+
+// Else sets the result of the expression when no WHEN matches
+func (c CaseClause) Else(result interface{}) CaseClause {
+	c.ElseResult = GetClauseFrom(result)
+	return c
+}
+
+// Accept calls the compiler VisitCase function
+func (c CaseClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitCase(context, c)
+}
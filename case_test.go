@@ -0,0 +1,89 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCase(t *testing.T) {
+	dialect := NewDefaultDialect()
+	orders := Table(
+		"orders",
+		Column("id", Int()).PrimaryKey(),
+		Column("status", Int()),
+		Column("amount", Int()),
+	)
+
+	bucket := Case().
+		When(orders.C("amount").Lt(100), "small").
+		When(orders.C("amount").Lt(1000), "medium").
+		Else("large")
+	sel := Select(bucket, Count(orders.C("id"))).
+		From(orders).
+		GroupBy(bucket).
+		OrderBy(bucket)
+	statement := sel.Build(dialect)
+	assert.Nil(t, statement.Err())
+	assert.Equal(t, "SELECT "+
+		"CASE WHEN amount < ? THEN ? WHEN amount < ? THEN ? ELSE ? END, COUNT(id)\n"+
+		"FROM orders\n"+
+		"GROUP BY 1\n"+
+		"ORDER BY 1 ASC;", statement.SQL())
+	bindings := []interface{}{100, "small", 1000, "medium", "large"}
+	assert.Equal(t, bindings, statement.Bindings())
+
+	// the emulations of NULLS LAST need the expression
+	statement = sel.OrderBy(Desc(bucket).NullsLast()).Build(dialect)
+	assert.Equal(t, "SELECT "+
+		"CASE WHEN amount < ? THEN ? WHEN amount < ? THEN ? ELSE ? END, COUNT(id)\n"+
+		"FROM orders\n"+
+		"GROUP BY 1\n"+
+		"ORDER BY CASE WHEN amount < ? THEN ? WHEN amount < ? THEN ? ELSE ? END DESC NULLS LAST;", statement.SQL())
+	assert.Equal(t, append(bindings, bindings...), statement.Bindings())
+
+	// the expressions having other bound values are not in the select list
+	other := Case().
+		When(orders.C("amount").Lt(10), "small").
+		When(orders.C("amount").Lt(1000), "medium").
+		Else("large")
+	statement = sel.GroupBy(other).Build(dialect)
+	assert.Equal(t, "SELECT "+
+		"CASE WHEN amount < ? THEN ? WHEN amount < ? THEN ? ELSE ? END, COUNT(id)\n"+
+		"FROM orders\n"+
+		"GROUP BY 1, CASE WHEN amount < ? THEN ? WHEN amount < ? THEN ? ELSE ? END\n"+
+		"ORDER BY 1 ASC;", statement.SQL())
+	assert.Equal(t, append(bindings, 10, "small", 1000, "medium", "large"), statement.Bindings())
+
+	// the expressions that are not in the select list are compiled
+	statement = Select(Count(orders.C("id"))).From(orders).GroupBy(bucket).Build(dialect)
+	assert.Equal(t, "SELECT COUNT(id)\n"+
+		"FROM orders\n"+
+		"GROUP BY CASE WHEN amount < ? THEN ? WHEN amount < ? THEN ? ELSE ? END;", statement.SQL())
+	assert.Equal(t, bindings, statement.Bindings())
+
+	sel = Select(
+		orders.C("id"),
+		Case(orders.C("status")).When(1, "active").When(2, "banned"),
+		Sum(Case().When(orders.C("status").Eq(1), orders.C("amount")).Else(0)),
+	).From(orders).GroupBy(orders.C("id"))
+	statement = sel.Build(dialect)
+	assert.Equal(t, "SELECT "+
+		"id, "+
+		"CASE status WHEN ? THEN ? WHEN ? THEN ? END, "+
+		"SUM(CASE WHEN status = ? THEN amount ELSE ? END)\n"+
+		"FROM orders\n"+
+		"GROUP BY id;", statement.SQL())
+	assert.Equal(t, []interface{}{1, "active", 2, "banned", 1, 0}, statement.Bindings())
+
+	upd := Update(orders).Values(map[string]interface{}{
+		"status": Case(orders.C("status")).When(1, 2).Else(orders.C("status")),
+	})
+	statement = upd.Build(dialect)
+	assert.Equal(t, "UPDATE orders\nSET status = CASE status WHEN ? THEN ? ELSE status END;", statement.SQL())
+	assert.Equal(t, []interface{}{1, 2}, statement.Bindings())
+
+	statement = Select(Case().Else(1)).From(orders).Build(dialect)
+	assert.Equal(t, ErrInterface, statement.Err().(Error).Code)
+	assert.Equal(t, "", statement.SQL())
+}
@@ -61,8 +61,8 @@ func GetClauseFrom(value interface{}) Clause {
 
 // GetListFrom returns a list clause from any list
 //
-// If only one value is passed and is a ListClause, it is returned
-// as-is.
+// If only one value is passed and is a ListClause or a select statement,
+// it is returned as-is.
 // In any other case, a ListClause is built with each value wrapped
 // by a Bind() if not already a Clause
 func GetListFrom(values ...interface{}) Clause {
@@ -70,6 +70,9 @@ func GetListFrom(values ...interface{}) Clause {
 		if clause, ok := values[0].(ListClause); ok {
 			return clause
 		}
+		if clause, ok := values[0].(Clause); ok && isSubQuery(clause) {
+			return clause
+		}
 	}
 
 	var clauses []Clause
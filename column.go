@@ -28,6 +28,23 @@ type ColumnElem struct {
 	Table       string // This field should be lazily set by Table() function
 	Constraints []ConstraintElem
 	Options     ColumnOptions
+
+	err error
+}
+
+// unknownColumn returns the column of a failed lookup. Its error is
+// returned by the Build of the statements using it.
+func unknownColumn(name string, table string, format string, args ...interface{}) ColumnElem {
+	return ColumnElem{
+		Name:  name,
+		Table: table,
+		err:   Error{Code: ErrInterface, Orig: fmt.Errorf(format, args...)},
+	}
+}
+
+// Err returns the error of the lookup of an unknown column, if any
+func (c ColumnElem) Err() error {
+	return c.err
 }
 
 // AutoIncrement set up “auto increment” semantics for an integer column.
@@ -118,6 +135,16 @@ func (c ColumnElem) Like(pattern string) Clause {
 	return Like(c, pattern)
 }
 
+// NotLike wraps the NotLike(col ColumnElem, pattern string)
+func (c ColumnElem) NotLike(pattern string) Clause {
+	return NotLike(c, pattern)
+}
+
+// ILike wraps the ILike(col ColumnElem, pattern string)
+func (c ColumnElem) ILike(pattern string) Clause {
+	return ILike(c, pattern)
+}
+
 // NotIn wraps the NotIn(col ColumnElem, values ...interface{})
 func (c ColumnElem) NotIn(values ...interface{}) Clause {
 	return NotIn(c, values...)
@@ -157,3 +184,56 @@ func (c ColumnElem) Gte(value interface{}) Clause {
 func (c ColumnElem) Lte(value interface{}) Clause {
 	return Lte(c, value)
 }
+
+// IsNull wraps the IsNull(col ColumnElem)
+func (c ColumnElem) IsNull() Clause {
+	return IsNull(c)
+}
+
+// IsNotNull wraps the IsNotNull(col ColumnElem)
+func (c ColumnElem) IsNotNull() Clause {
+	return IsNotNull(c)
+}
+
+// Between wraps the Between(col ColumnElem, low interface{}, high interface{})
+func (c ColumnElem) Between(low interface{}, high interface{}) Clause {
+	return Between(c, low, high)
+}
+
+// NotBetween wraps the NotBetween(col ColumnElem, low interface{}, high interface{})
+func (c ColumnElem) NotBetween(low interface{}, high interface{}) Clause {
+	return NotBetween(c, low, high)
+}
+
+// arithmetic and concatenation wrappers
+
+// Add wraps the Add(col ColumnElem, value interface{})
+func (c ColumnElem) Add(value interface{}) ArithmeticClause {
+	return Add(c, value)
+}
+
+// Sub wraps the Sub(col ColumnElem, value interface{})
+func (c ColumnElem) Sub(value interface{}) ArithmeticClause {
+	return Sub(c, value)
+}
+
+// Mul wraps the Mul(col ColumnElem, value interface{})
+func (c ColumnElem) Mul(value interface{}) ArithmeticClause {
+	return Mul(c, value)
+}
+
+// Div wraps the Div(col ColumnElem, value interface{})
+func (c ColumnElem) Div(value interface{}) ArithmeticClause {
+	return Div(c, value)
+}
+
+// Mod wraps the Mod(col ColumnElem, value interface{})
+func (c ColumnElem) Mod(value interface{}) ArithmeticClause {
+	return Mod(c, value)
+}
+
+// Concat wraps the Concat(args ...interface{}), the column being the first
+// string
+func (c ColumnElem) Concat(values ...interface{}) FuncClause {
+	return Concat(append([]interface{}{c}, values...)...)
+}
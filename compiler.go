@@ -2,17 +2,25 @@ package qb
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 )
 
 // NewCompilerContext initialize a new compiler context
 func NewCompilerContext(dialect Dialect) *CompilerContext {
-	return &CompilerContext{
+	context := &CompilerContext{
 		Dialect:  dialect,
 		Compiler: dialect.GetCompiler(),
 		Vars:     make(map[string]interface{}),
+		Params:   make(map[string]int),
 		Binds:    []interface{}{},
 	}
+	if d, ok := dialect.(*DefaultDialect); ok && d.err != nil {
+		// the dialect of an unknown driver
+		context.AddError(d.err)
+	}
+	return context
 }
 
 // CompilerContext is a data structure passed to all the Compiler visit
@@ -24,35 +32,69 @@ type CompilerContext struct {
 	DefaultTableName string
 	InSubQuery       bool
 	Vars             map[string]interface{}
+	// Params are the bind indexes of the named parameters, for the
+	// dialects having numbered placeholders
+	Params map[string]int
+	// Errors are the errors met by the compiler, which make the statement
+	// invalid
+	Errors []error
+
+	// anonymousAliases counts the aliases generated for the derived tables
+	anonymousAliases int
 
 	Dialect  Dialect
 	Compiler Compiler
 }
 
+// AddError records an error met by the compiler, for instance a clause the
+// dialect does not support
+func (c *CompilerContext) AddError(err error) {
+	c.Errors = append(c.Errors, err)
+}
+
+// Err returns the first error met by the compiler, if any
+func (c *CompilerContext) Err() error {
+	if len(c.Errors) != 0 {
+		return c.Errors[0]
+	}
+	return nil
+}
+
 // Compiler is a visitor that produce SQL from various types of Clause
 type Compiler interface {
 	VisitAggregate(*CompilerContext, AggregateClause) string
 	VisitAlias(*CompilerContext, AliasClause) string
+	VisitBetween(*CompilerContext, BetweenClause) string
 	VisitBinary(*CompilerContext, BinaryExpressionClause) string
 	VisitBind(*CompilerContext, BindClause) string
+	VisitCase(*CompilerContext, CaseClause) string
+	VisitCast(*CompilerContext, CastClause) string
 	VisitColumn(*CompilerContext, ColumnElem) string
 	VisitCombiner(*CompilerContext, CombinerClause) string
+	VisitCompoundSelect(*CompilerContext, CompoundSelectStmt) string
+	VisitCTE(*CompilerContext, CTEClause) string
 	VisitDelete(*CompilerContext, DeleteStmt) string
 	VisitExists(*CompilerContext, ExistsClause) string
 	VisitForUpdate(*CompilerContext, ForUpdateClause) string
+	VisitFunc(*CompilerContext, FuncClause) string
 	VisitHaving(*CompilerContext, HavingClause) string
+	VisitILike(*CompilerContext, ILikeClause) string
 	VisitIn(*CompilerContext, InClause) string
 	VisitInsert(*CompilerContext, InsertStmt) string
 	VisitJoin(*CompilerContext, JoinClause) string
 	VisitLabel(*CompilerContext, string) string
+	VisitLabeled(*CompilerContext, LabeledClause) string
 	VisitList(*CompilerContext, ListClause) string
+	VisitNot(*CompilerContext, NotClause) string
 	VisitOrderBy(*CompilerContext, OrderByClause) string
+	VisitOrderTerm(*CompilerContext, OrderTermClause) string
 	VisitSelect(*CompilerContext, SelectStmt) string
 	VisitTable(*CompilerContext, TableElem) string
 	VisitText(*CompilerContext, TextClause) string
 	VisitUpdate(*CompilerContext, UpdateStmt) string
 	VisitUpsert(*CompilerContext, UpsertStmt) string
 	VisitWhere(*CompilerContext, WhereClause) string
+	VisitWindow(*CompilerContext, WindowClause) string
 }
 
 // NewSQLCompiler returns a new SQLCompiler
@@ -74,18 +116,33 @@ func (c SQLCompiler) VisitAggregate(context *CompilerContext, aggregate Aggregat
 func (SQLCompiler) VisitAlias(context *CompilerContext, alias AliasClause) string {
 	return fmt.Sprintf(
 		"%s AS %s",
-		alias.Selectable.Accept(context),
+		CompileClause(context, alias.Selectable),
 		context.Dialect.Escape(alias.Name),
 	)
 }
 
+// VisitBetween compiles LEFT [NOT] BETWEEN LOW AND HIGH expressions
+func (c SQLCompiler) VisitBetween(context *CompilerContext, between BetweenClause) string {
+	op := "BETWEEN"
+	if between.Not {
+		op = "NOT BETWEEN"
+	}
+	return fmt.Sprintf(
+		"%s %s %s AND %s",
+		CompileClause(context, between.Left),
+		op,
+		CompileClause(context, between.Low),
+		CompileClause(context, between.High),
+	)
+}
+
 // VisitBinary compiles LEFT <op> RIGHT expressions
 func (c SQLCompiler) VisitBinary(context *CompilerContext, binary BinaryExpressionClause) string {
 	return fmt.Sprintf(
 		"%s %s %s",
-		binary.Left.Accept(context),
+		CompileClause(context, binary.Left),
 		binary.Op,
-		binary.Right.Accept(context),
+		CompileClause(context, binary.Right),
 	)
 }
 
@@ -95,11 +152,49 @@ func (SQLCompiler) VisitBind(context *CompilerContext, bind BindClause) string {
 	return "?"
 }
 
+// VisitCase compiles a CASE [value] WHEN ... THEN ... [ELSE ...] END
+// expression. A CASE without any WHEN is an error
+func (c SQLCompiler) VisitCase(context *CompilerContext, caseClause CaseClause) string {
+	if len(caseClause.Whens) == 0 {
+		context.AddError(Error{
+			Code: ErrInterface,
+			Orig: fmt.Errorf("A CASE expression needs at least one WHEN"),
+		})
+	}
+	sql := "CASE"
+	if caseClause.Value != nil {
+		sql += " " + CompileClause(context, caseClause.Value)
+	}
+	for _, when := range caseClause.Whens {
+		sql += fmt.Sprintf(
+			" WHEN %s THEN %s",
+			CompileClause(context, when.Condition),
+			CompileClause(context, when.Result),
+		)
+	}
+	if caseClause.ElseResult != nil {
+		sql += " ELSE " + CompileClause(context, caseClause.ElseResult)
+	}
+	return sql + " END"
+}
+
+// VisitCast compiles a CAST(<clause> AS <type>) expression
+func (c SQLCompiler) VisitCast(context *CompilerContext, cast CastClause) string {
+	return fmt.Sprintf(
+		"CAST(%s AS %s)",
+		CompileClause(context, cast.Clause),
+		context.Dialect.CompileType(cast.Type),
+	)
+}
+
 // VisitColumn returns a column name, optionnaly escaped depending on the dialect
 // configuration
 func (c SQLCompiler) VisitColumn(context *CompilerContext, column ColumnElem) string {
+	if column.err != nil {
+		context.AddError(column.err)
+	}
 	sql := ""
-	if context.InSubQuery || context.DefaultTableName != column.Table {
+	if column.Table != "" && (context.InSubQuery || context.DefaultTableName != column.Table) {
 		sql += c.Dialect.Escape(column.Table) + "."
 	}
 	sql += c.Dialect.Escape(column.Name)
@@ -117,9 +212,208 @@ func (c SQLCompiler) VisitCombiner(context *CompilerContext, combiner CombinerCl
 	return fmt.Sprintf("(%s)", strings.Join(sqls, fmt.Sprintf(" %s ", combiner.operator)))
 }
 
+// VisitCompoundSelect compiles a compound select statement, each select
+// statement being enclosed in parenthesis
+func (c SQLCompiler) VisitCompoundSelect(context *CompilerContext, compound CompoundSelectStmt) string {
+	return CompileCompoundSelect(context, compound, true)
+}
+
+// CompileCompoundSelect is a default implementation for
+// Compiler.VisitCompoundSelect. If parenthesize is true, each select
+// statement is enclosed in parenthesis.
+func CompileCompoundSelect(context *CompilerContext, compound CompoundSelectStmt, parenthesize bool) string {
+	var selects []string
+	for _, sel := range compound.Selects {
+		sql := sel.Accept(context)
+		if parenthesize {
+			sql = "(" + sql + ")"
+		}
+		selects = append(selects, sql)
+	}
+	sql := strings.Join(selects, "\n"+compound.Operator+"\n")
+
+	if compound.OrderByClause != nil {
+		// The order by terms refer to the columns of the compound result,
+		// which cannot be qualified with a table name
+		orderBy := OrderByClause{}
+		for _, term := range compound.OrderByClause.Terms {
+			if col, ok := term.Clause.(ColumnElem); ok {
+				col.Table = ""
+				term.Clause = col
+			}
+			orderBy.Terms = append(orderBy.Terms, term)
+		}
+		defaultTableName, inSubQuery := context.DefaultTableName, context.InSubQuery
+		context.DefaultTableName, context.InSubQuery = "", false
+		sql += "\n" + orderBy.Accept(context)
+		context.DefaultTableName, context.InSubQuery = defaultTableName, inSubQuery
+	}
+
+	if limitOffset := compileLimitOffset(compound.LimitValue, compound.OffsetValue); limitOffset != "" {
+		sql += "\n" + limitOffset
+	}
+
+	return sql
+}
+
+// compileLimitOffset compiles the LIMIT and OFFSET tokens of a statement
+func compileLimitOffset(limit *int, offset *int) string {
+	var tokens []string
+	if limit != nil {
+		tokens = append(tokens, fmt.Sprintf("LIMIT %d", *limit))
+	}
+	if offset != nil {
+		tokens = append(tokens, fmt.Sprintf("OFFSET %d", *offset))
+	}
+	return strings.Join(tokens, " ")
+}
+
+// compileSubQuery compiles a statement nested in another one, enclosed in
+// parenthesis
+func compileSubQuery(context *CompilerContext, clause Clause) string {
+	inSubQuery := context.InSubQuery
+	context.InSubQuery = true
+	defer func() { context.InSubQuery = inSubQuery }()
+	return "(" + clause.Accept(context) + ")"
+}
+
+// isSubQuery returns true if the clause is a statement that must be
+// compiled as a sub query when nested in another clause
+func isSubQuery(clause Clause) bool {
+	switch clause.(type) {
+	case SelectStmt, CompoundSelectStmt:
+		return true
+	default:
+		return false
+	}
+}
+
+// CompileClause compiles a clause nested in another one, as a sub query if
+// it is a statement.
+// It is a helper for the dialects compiling the clauses having operands
+func CompileClause(context *CompilerContext, clause Clause) string {
+	if isSubQuery(clause) {
+		return compileSubQuery(context, clause)
+	}
+	return clause.Accept(context)
+}
+
+// compileSelectable compiles a selectable of a FROM clause or a join. The
+// derived tables having no alias are given one, as most databases require
+// it: anon_1, anon_2...
+func compileSelectable(context *CompilerContext, selectable Clause) string {
+	if isSubQuery(selectable) {
+		context.anonymousAliases++
+		name := fmt.Sprintf("anon_%d", context.anonymousAliases)
+		return context.Compiler.VisitAlias(context, Alias(name, selectable.(Selectable)))
+	}
+	return selectable.Accept(context)
+}
+
+// selectListPositions finds the position in a select list of the
+// expressions of GROUP BY or ORDER BY having bound values.
+// Such expressions are compiled as their position, because their bound
+// values would get other placeholders than in the select list, and the
+// databases would no longer see that they are the same expression.
+// The expressions are compared by their SQL and bound values, compiled once
+// on a scratch context so their errors are only reported by the compilation
+// of the statement.
+type selectListPositions struct {
+	scratch    *CompilerContext
+	selectList []Clause
+	compiled   []compiledClause
+}
+
+// compiledClause is the SQL and bound values of a clause
+type compiledClause struct {
+	sql   string
+	binds []interface{}
+}
+
+func newSelectListPositions(context *CompilerContext, selectList []Clause) *selectListPositions {
+	scratch := NewCompilerContext(context.Dialect)
+	scratch.Compiler = context.Compiler
+	scratch.DefaultTableName = context.DefaultTableName
+	scratch.InSubQuery = context.InSubQuery
+	return &selectListPositions{scratch: scratch, selectList: selectList}
+}
+
+// compile compiles a clause on the scratch context, as the first clause of
+// a statement
+func (p *selectListPositions) compile(clause Clause) compiledClause {
+	p.scratch.Binds = []interface{}{}
+	p.scratch.Params = make(map[string]int)
+	p.scratch.Errors = nil
+	sql := CompileClause(p.scratch, clause)
+	return compiledClause{sql: sql, binds: p.scratch.Binds}
+}
+
+// position returns the position of an expression in the select list, or 0
+// if it is not there or has no bound values
+func (p *selectListPositions) position(clause Clause) int {
+	if _, ok := clause.(ColumnElem); ok {
+		return 0
+	}
+	compiled := p.compile(clause)
+	if len(compiled.binds) == 0 {
+		return 0
+	}
+	if p.compiled == nil {
+		p.compiled = make([]compiledClause, len(p.selectList))
+		for i, c := range p.selectList {
+			if labeled, ok := c.(LabeledClause); ok {
+				c = labeled.Clause
+			}
+			p.compiled[i] = p.compile(c)
+		}
+	}
+	for i, c := range p.compiled {
+		if c.sql == compiled.sql && reflect.DeepEqual(c.binds, compiled.binds) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// VisitCTE compiles a common table expression definition
+func (c SQLCompiler) VisitCTE(context *CompilerContext, cte CTEClause) string {
+	sql := context.Compiler.VisitLabel(context, cte.Name)
+	if len(cte.ColumnNames) != 0 {
+		var names []string
+		for _, name := range cte.ColumnNames {
+			names = append(names, context.Compiler.VisitLabel(context, name))
+		}
+		sql += fmt.Sprintf("(%s)", strings.Join(names, ", "))
+	}
+	inSubQuery := context.InSubQuery
+	context.InSubQuery = true
+	defer func() { context.InSubQuery = inSubQuery }()
+	return fmt.Sprintf("%s AS (%s)", sql, cte.Select.Accept(context))
+}
+
+// compileWith compiles the WITH clause of a statement
+func compileWith(context *CompilerContext, ctes []CTEClause) string {
+	sql := "WITH "
+	for _, cte := range ctes {
+		if cte.Recursive {
+			sql += "RECURSIVE "
+			break
+		}
+	}
+	var definitions []string
+	for _, cte := range ctes {
+		definitions = append(definitions, context.Compiler.VisitCTE(context, cte))
+	}
+	return sql + strings.Join(definitions, ",\n")
+}
+
 // VisitDelete compiles a DELETE statement
 func (c SQLCompiler) VisitDelete(context *CompilerContext, delete DeleteStmt) string {
-	sql := "DELETE FROM " + delete.table.Accept(context)
+	var sql string
+	if len(delete.with) != 0 {
+		sql = compileWith(context, delete.with) + "\n"
+	}
+	sql += "DELETE FROM " + delete.table.Accept(context)
 
 	if delete.where != nil {
 		sql += "\n" + delete.where.Accept(context)
@@ -143,10 +437,7 @@ func (SQLCompiler) VisitExists(context *CompilerContext, exists ExistsClause) st
 	if exists.Not {
 		sql = "NOT "
 	}
-	sql += "EXISTS(%s)"
-	context.InSubQuery = true
-	defer func() { context.InSubQuery = false }()
-	return fmt.Sprintf(sql, exists.Select.Accept(context))
+	return sql + "EXISTS" + compileSubQuery(context, exists.Select)
 }
 
 // VisitForUpdate compiles a 'FOR UPDATE' clause
@@ -162,40 +453,103 @@ func (c SQLCompiler) VisitForUpdate(context *CompilerContext, forUpdate ForUpdat
 	return sql
 }
 
+// VisitFunc compiles a function call. The portable functions are compiled
+// to their standard spelling: CONCAT to the || operator, NOW to
+// CURRENT_TIMESTAMP and LENGTH to CHAR_LENGTH
+func (c SQLCompiler) VisitFunc(context *CompilerContext, fn FuncClause) string {
+	switch fn.Name {
+	case "CONCAT":
+		var args []string
+		for _, arg := range fn.Args {
+			args = append(args, CompileClause(context, arg))
+		}
+		return strings.Join(args, " || ")
+	case "NOW":
+		return "CURRENT_TIMESTAMP"
+	case "LENGTH":
+		return CompileFuncCall(context, "CHAR_LENGTH", fn.Args)
+	}
+	return CompileFuncCall(context, fn.Name, fn.Args)
+}
+
+// CompileFuncCall compiles a NAME(args...) function call.
+// It is a helper for the dialects compiling the portable functions.
+func CompileFuncCall(context *CompilerContext, name string, args []Clause) string {
+	var compiled []string
+	for _, arg := range args {
+		compiled = append(compiled, CompileClause(context, arg))
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(compiled, ", "))
+}
+
 // VisitHaving compiles a HAVING clause
 func (c SQLCompiler) VisitHaving(context *CompilerContext, having HavingClause) string {
 	aggSQL := having.aggregate.Accept(context)
 	return fmt.Sprintf("HAVING %s %s %s", aggSQL, having.op, Bind(having.value).Accept(context))
 }
 
+// VisitILike compiles a case-insensitive LIKE as
+// LOWER(left) LIKE LOWER(right), as there is no ILIKE operator in SQL
+func (c SQLCompiler) VisitILike(context *CompilerContext, ilike ILikeClause) string {
+	return Like(Lower(ilike.Left), Lower(ilike.Right)).Accept(context)
+}
+
 // VisitIn compiles a <left> (NOT) IN (<right>)
 func (c SQLCompiler) VisitIn(context *CompilerContext, in InClause) string {
+	right := CompileClause(context, in.Right)
+	if !isSubQuery(in.Right) {
+		right = "(" + right + ")"
+	}
 	return fmt.Sprintf(
-		"%s %s (%s)",
-		in.Left.Accept(context),
+		"%s %s %s",
+		CompileClause(context, in.Left),
 		in.Op,
-		in.Right.Accept(context),
+		right,
 	)
 }
 
+// DefaultValuesInserter is an optional Dialect capability of the dialects
+// that have their own syntax for the inserts of a row of default values,
+// rendered as INSERT INTO t DEFAULT VALUES by the others
+type DefaultValuesInserter interface {
+	// DefaultValuesInsert returns the insert of a row of default values in
+	// the table, whose name is already escaped
+	DefaultValuesInsert(table string) string
+}
+
 // VisitInsert compiles a INSERT statement
 func (c SQLCompiler) VisitInsert(context *CompilerContext, insert InsertStmt) string {
 	context.DefaultTableName = insert.table.Name
 	defer func() { context.DefaultTableName = "" }()
 
-	cols := List()
-	values := List()
-	for k, v := range insert.values {
-		cols.Clauses = append(cols.Clauses, insert.table.C(k))
-		values.Clauses = append(values.Clauses, Bind(v))
+	var with string
+	if len(insert.with) != 0 {
+		with = compileWith(context, insert.with) + "\n"
 	}
 
-	sql := fmt.Sprintf(
-		"INSERT INTO %s(%s)\nVALUES(%s)",
-		insert.table.Accept(context),
-		cols.Accept(context),
-		values.Accept(context),
-	)
+	table := insert.table.Accept(context)
+	var sql string
+	switch {
+	case insert.defaultValues:
+		if inserter, ok := context.Dialect.(DefaultValuesInserter); ok {
+			sql = with + inserter.DefaultValuesInsert(table)
+		} else {
+			sql = with + fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", table)
+		}
+	case insert.fromSelect != nil:
+		cols := List()
+		for _, col := range insert.columns {
+			cols.Clauses = append(cols.Clauses, ColumnElem{Name: col.Name, Table: insert.table.Name})
+		}
+		if len(cols.Clauses) != 0 {
+			table += "(" + cols.Accept(context) + ")"
+		}
+		context.DefaultTableName = ""
+		sql = with + fmt.Sprintf("INSERT INTO %s\n%s", table, insert.fromSelect.Accept(context))
+		context.DefaultTableName = insert.table.Name
+	default:
+		sql = with + compileInsertValues(context, insert, table)
+	}
 
 	returning := []string{}
 	for _, r := range insert.returning {
@@ -211,16 +565,55 @@ func (c SQLCompiler) VisitInsert(context *CompilerContext, insert InsertStmt) st
 	return sql
 }
 
+// compileInsertValues compiles the INSERT INTO ... VALUES part of an insert
+// statement
+func compileInsertValues(context *CompilerContext, insert InsertStmt, table string) string {
+	rows := insert.rowList()
+	names := insertColumns(rows)
+	cols := List()
+	for _, name := range names {
+		cols.Clauses = append(cols.Clauses, insert.table.C(name))
+	}
+	values := []string{}
+	for _, row := range rows {
+		rowValues := List()
+		for _, name := range names {
+			value, _ := row.Get(name)
+			rowValues.Clauses = append(rowValues.Clauses, GetClauseFrom(value))
+		}
+		values = append(values, "("+rowValues.Accept(context)+")")
+	}
+	if len(values) == 0 {
+		values = append(values, "()")
+	}
+
+	return fmt.Sprintf(
+		"INSERT INTO %s(%s)\nVALUES%s",
+		table,
+		cols.Accept(context),
+		strings.Join(values, ", "),
+	)
+}
+
 // VisitJoin compiles a JOIN (ON) clause
 func (c SQLCompiler) VisitJoin(context *CompilerContext, join JoinClause) string {
+	if join.Err() != nil {
+		context.AddError(join.Err())
+	}
 	sql := fmt.Sprintf(
 		"%s\n%s %s",
-		join.Left.Accept(context),
+		compileSelectable(context, join.Left),
 		join.JoinType,
-		join.Right.Accept(context),
+		compileSelectable(context, join.Right),
 	)
 	if join.OnClause != nil {
 		sql += " ON " + join.OnClause.Accept(context)
+	} else if len(join.Using) != 0 {
+		var columns []string
+		for _, col := range join.Using {
+			columns = append(columns, context.Compiler.VisitLabel(context, col))
+		}
+		sql += fmt.Sprintf(" USING (%s)", strings.Join(columns, ", "))
 	}
 
 	return sql
@@ -231,6 +624,15 @@ func (c SQLCompiler) VisitLabel(context *CompilerContext, label string) string {
 	return c.Dialect.Escape(label)
 }
 
+// VisitLabeled compiles a '<expression> AS <label>' clause of a select list
+func (c SQLCompiler) VisitLabeled(context *CompilerContext, labeled LabeledClause) string {
+	return fmt.Sprintf(
+		"%s AS %s",
+		CompileClause(context, labeled.Clause),
+		context.Compiler.VisitLabel(context, labeled.Name),
+	)
+}
+
 // VisitList compiles a list of values
 func (c SQLCompiler) VisitList(context *CompilerContext, list ListClause) string {
 	var clauses []string
@@ -240,14 +642,61 @@ func (c SQLCompiler) VisitList(context *CompilerContext, list ListClause) string
 	return strings.Join(clauses, ", ")
 }
 
+// VisitNot compiles NOT followed by the parenthesized clause
+func (c SQLCompiler) VisitNot(context *CompilerContext, not NotClause) string {
+	if _, ok := not.Clause.(CombinerClause); ok || isSubQuery(not.Clause) {
+		// AND and OR clauses and the subqueries are already parenthesized
+		return "NOT " + CompileClause(context, not.Clause)
+	}
+	return fmt.Sprintf("NOT (%s)", not.Clause.Accept(context))
+}
+
 // VisitOrderBy compiles a ORDER BY sql clause
 func (c SQLCompiler) VisitOrderBy(context *CompilerContext, OrderByClause OrderByClause) string {
-	cols := []string{}
-	for _, c := range OrderByClause.columns {
-		cols = append(cols, c.Accept(context))
+	return "ORDER BY " + compileOrderTerms(context, OrderByClause.Terms)
+}
+
+// compileOrderTerms compiles the terms of an ORDER BY
+func compileOrderTerms(context *CompilerContext, terms []OrderTermClause) string {
+	compiled := []string{}
+	for _, term := range terms {
+		compiled = append(compiled, term.Accept(context))
+	}
+	return strings.Join(compiled, ", ")
+}
+
+// VisitOrderTerm compiles a term of an ORDER BY, with its direction and
+// NULLS FIRST or NULLS LAST
+func (c SQLCompiler) VisitOrderTerm(context *CompilerContext, term OrderTermClause) string {
+	sql := CompileOrderTerm(context, term)
+	if term.Nulls != "" {
+		sql += " NULLS " + term.Nulls
 	}
+	return sql
+}
+
+// CompileOrderTerm compiles a term of an ORDER BY with its direction, but
+// without the position of the nulls.
+// It is a helper for the dialects emulating NULLS FIRST and NULLS LAST
+func CompileOrderTerm(context *CompilerContext, term OrderTermClause) string {
+	sql := CompileClause(context, term.Clause)
+	if term.Direction != "" {
+		sql += " " + term.Direction
+	}
+	return sql
+}
 
-	return fmt.Sprintf("ORDER BY %s %s", strings.Join(cols, ", "), OrderByClause.t)
+// CompileEmulatedNullsOrder compiles a term of an ORDER BY for the dialects
+// having no NULLS FIRST and NULLS LAST: the term is preceded by the ordering
+// of isNull, a clause that is true for the null values of the term
+func CompileEmulatedNullsOrder(context *CompilerContext, term OrderTermClause, isNull Clause) string {
+	switch term.Nulls {
+	case "FIRST":
+		return isNull.Accept(context) + " DESC, " + CompileOrderTerm(context, term)
+	case "LAST":
+		return isNull.Accept(context) + " ASC, " + CompileOrderTerm(context, term)
+	}
+	return CompileOrderTerm(context, term)
 }
 
 // VisitSelect compiles a SELECT statement
@@ -260,17 +709,22 @@ func (c SQLCompiler) VisitSelect(context *CompilerContext, selectStmt SelectStmt
 		context.DefaultTableName = selectStmt.FromClause.DefaultName()
 	}
 
+	// with
+	if len(selectStmt.WithClause) != 0 {
+		addLine(compileWith(context, selectStmt.WithClause))
+	}
+
 	// select
 	columns := []string{}
 	for _, c := range selectStmt.SelectList {
-		sql := c.Accept(context)
+		sql := CompileClause(context, c)
 		columns = append(columns, sql)
 	}
 	addLine(fmt.Sprintf("SELECT %s", strings.Join(columns, ", ")))
 
 	// from
 	if selectStmt.FromClause != nil {
-		addLine(fmt.Sprintf("FROM %s", selectStmt.FromClause.Accept(context)))
+		addLine(fmt.Sprintf("FROM %s", compileSelectable(context, selectStmt.FromClause)))
 	}
 
 	// where
@@ -279,9 +733,16 @@ func (c SQLCompiler) VisitSelect(context *CompilerContext, selectStmt SelectStmt
 	}
 
 	// group by
+	positions := newSelectListPositions(context, selectStmt.SelectList)
 	groupByCols := []string{}
 	for _, c := range selectStmt.GroupByClause {
-		groupByCols = append(groupByCols, context.Dialect.Escape(c.Name))
+		if col, ok := c.(ColumnElem); ok {
+			groupByCols = append(groupByCols, context.Dialect.Escape(col.Name))
+		} else if position := positions.position(c); position != 0 {
+			groupByCols = append(groupByCols, strconv.Itoa(position))
+		} else {
+			groupByCols = append(groupByCols, CompileClause(context, c))
+		}
 	}
 	if len(groupByCols) > 0 {
 		addLine(fmt.Sprintf("GROUP BY %s", strings.Join(groupByCols, ", ")))
@@ -293,21 +754,36 @@ func (c SQLCompiler) VisitSelect(context *CompilerContext, selectStmt SelectStmt
 		addLine(sql)
 	}
 
+	// window
+	windows := []string{}
+	for _, w := range selectStmt.NamedWindows {
+		spec := w.Window.Accept(context)
+		if !strings.HasPrefix(spec, "(") {
+			spec = "(" + spec + ")"
+		}
+		windows = append(windows, fmt.Sprintf("%s AS %s", context.Dialect.Escape(w.Name), spec))
+	}
+	if len(windows) > 0 {
+		addLine(fmt.Sprintf("WINDOW %s", strings.Join(windows, ", ")))
+	}
+
 	// order by
 	if selectStmt.OrderByClause != nil {
-		sql := selectStmt.OrderByClause.Accept(context)
-		addLine(sql)
+		orderBy := OrderByClause{}
+		for _, term := range selectStmt.OrderByClause.Terms {
+			// the emulations of NULLS FIRST and NULLS LAST need the expression
+			if term.Nulls == "" {
+				if position := positions.position(term.Clause); position != 0 {
+					term.Clause = SQLText(strconv.Itoa(position))
+				}
+			}
+			orderBy.Terms = append(orderBy.Terms, term)
+		}
+		addLine(orderBy.Accept(context))
 	}
 
-	if (selectStmt.OffsetValue != nil) || (selectStmt.LimitValue != nil) {
-		var tokens []string
-		if selectStmt.LimitValue != nil {
-			tokens = append(tokens, fmt.Sprintf("LIMIT %d", *selectStmt.LimitValue))
-		}
-		if selectStmt.OffsetValue != nil {
-			tokens = append(tokens, fmt.Sprintf("OFFSET %d", *selectStmt.OffsetValue))
-		}
-		addLine(strings.Join(tokens, " "))
+	if limitOffset := compileLimitOffset(selectStmt.LimitValue, selectStmt.OffsetValue); limitOffset != "" {
+		addLine(limitOffset)
 	}
 
 	if selectStmt.ForUpdateClause != nil {
@@ -319,6 +795,9 @@ func (c SQLCompiler) VisitSelect(context *CompilerContext, selectStmt SelectStmt
 
 // VisitTable returns a table name, optionally escaped
 func (SQLCompiler) VisitTable(context *CompilerContext, table TableElem) string {
+	if table.err != nil {
+		context.AddError(table.err)
+	}
 	return context.Compiler.VisitLabel(context, table.Name)
 }
 
@@ -332,13 +811,17 @@ func (c SQLCompiler) VisitUpdate(context *CompilerContext, update UpdateStmt) st
 	context.DefaultTableName = update.table.Name
 	defer func() { context.DefaultTableName = "" }()
 
-	sql := "UPDATE " + update.table.Accept(context)
+	var sql string
+	if len(update.with) != 0 {
+		sql = compileWith(context, update.with) + "\n"
+	}
+	sql += "UPDATE " + update.table.Accept(context)
 
 	sets := List()
 
-	for k, v := range update.values {
+	for _, cv := range update.values {
 		sets.Clauses = append(sets.Clauses,
-			Eq(update.table.C(k), Bind(v)))
+			Eq(update.table.C(cv.Column), GetClauseFrom(cv.Value)))
 	}
 
 	if len(sets.Clauses) > 0 {
@@ -361,13 +844,43 @@ func (c SQLCompiler) VisitUpdate(context *CompilerContext, update UpdateStmt) st
 	return sql
 }
 
-// VisitUpsert is not implemented and will panic.
+// VisitUpsert is not implemented and records an ErrNotSupported error.
 // It should be implemented in each dialect
 func (c SQLCompiler) VisitUpsert(context *CompilerContext, upsert UpsertStmt) string {
-	panic("Upsert is not Implemented in this compiler")
+	context.AddError(Error{
+		Code: ErrNotSupported,
+		Orig: fmt.Errorf("Upsert is not implemented by the %s dialect", context.Dialect.Driver()),
+	})
+	return ""
 }
 
 // VisitWhere compiles a WHERE clause
 func (c SQLCompiler) VisitWhere(context *CompilerContext, where WhereClause) string {
 	return fmt.Sprintf("WHERE %s", where.clause.Accept(context))
 }
+
+// VisitWindow compiles a window specification, or the name of a window
+func (c SQLCompiler) VisitWindow(context *CompilerContext, window WindowClause) string {
+	var parts []string
+	if window.Name != "" {
+		parts = append(parts, context.Dialect.Escape(window.Name))
+	}
+	if len(window.Partition) > 0 {
+		parts = append(parts, "PARTITION BY "+ListClause{window.Partition}.Accept(context))
+	}
+	if len(window.Order) > 0 {
+		parts = append(parts, "ORDER BY "+compileOrderTerms(context, window.Order))
+	}
+	if window.Frame != nil {
+		parts = append(parts, fmt.Sprintf(
+			"%s BETWEEN %s AND %s",
+			window.Frame.Mode,
+			window.Frame.Start,
+			window.Frame.End,
+		))
+	}
+	if window.Name != "" && len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
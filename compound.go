@@ -0,0 +1,142 @@
+package qb
+
+// Union generates a UNION compound select statement
+func Union(selects ...SelectStmt) CompoundSelectStmt {
+	return CompoundSelect("UNION", selects...)
+}
+
+// UnionAll generates a UNION ALL compound select statement
+func UnionAll(selects ...SelectStmt) CompoundSelectStmt {
+	return CompoundSelect("UNION ALL", selects...)
+}
+
+// Intersect generates an INTERSECT compound select statement
+func Intersect(selects ...SelectStmt) CompoundSelectStmt {
+	return CompoundSelect("INTERSECT", selects...)
+}
+
+// Except generates an EXCEPT compound select statement
+func Except(selects ...SelectStmt) CompoundSelectStmt {
+	return CompoundSelect("EXCEPT", selects...)
+}
+
+// CompoundSelect generates a compound select statement given the set
+// operator and the select statements to combine
+func CompoundSelect(operator string, selects ...SelectStmt) CompoundSelectStmt {
+	return CompoundSelectStmt{
+		Operator: operator,
+		Selects:  selects,
+	}
+}
+
+// CompoundSelectStmt is the base struct for building compound select
+// statements (UNION, INTERSECT...)
+// It satisfies the Selectable interface, its columns being the ones of the
+// first select statement
+type CompoundSelectStmt struct {
+	Operator      string
+	Selects       []SelectStmt
+	OrderByClause *OrderByClause
+	OffsetValue   *int
+	LimitValue    *int
+}
+
+// OrderBy generates an OrderByClause and sets the compound statement
+// orderbyclause.
+// The columns are rendered without their table name, so the columns
+// returned by C() can be used as well as the ones of the first select
+// statement.
+func (s CompoundSelectStmt) OrderBy(columns ...Clause) CompoundSelectStmt {
+	s.OrderByClause = orderBy(columns)
+	return s
+}
+
+// Asc sorts the terms of current order by clause in ascending order, except
+// the terms given a direction with the Asc and Desc functions
+// NOTE: Please use it after calling OrderBy()
+func (s CompoundSelectStmt) Asc() CompoundSelectStmt {
+	s.OrderByClause = s.OrderByClause.direction("ASC")
+	return s
+}
+
+// Desc sorts the terms of current order by clause in descending order, except
+// the terms given a direction with the Asc and Desc functions
+// NOTE: Please use it after calling OrderBy()
+func (s CompoundSelectStmt) Desc() CompoundSelectStmt {
+	s.OrderByClause = s.OrderByClause.direction("DESC")
+	return s
+}
+
+// Limit sets the limit number of rows
+func (s CompoundSelectStmt) Limit(limit int) CompoundSelectStmt {
+	s.LimitValue = &limit
+	return s
+}
+
+// Offset sets the offset
+func (s CompoundSelectStmt) Offset(value int) CompoundSelectStmt {
+	s.OffsetValue = &value
+	return s
+}
+
+// LimitOffset sets the limit & offset values of the compound statement
+func (s CompoundSelectStmt) LimitOffset(limit, offset int) CompoundSelectStmt {
+	s.LimitValue = &limit
+	s.OffsetValue = &offset
+	return s
+}
+
+// Accept calls the compiler VisitCompoundSelect method
+func (s CompoundSelectStmt) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitCompoundSelect(context, s)
+}
+
+// Build compiles the compound statement and returns the Stmt
+func (s CompoundSelectStmt) Build(dialect Dialect) *Stmt {
+	context := NewCompilerContext(dialect)
+	statement := Statement()
+	sql := s.Accept(context)
+	if err := context.Err(); err != nil {
+		statement.SetError(err)
+		return statement
+	}
+	statement.AddSQLClause(sql)
+	statement.AddBinding(context.Binds...)
+
+	return statement
+}
+
+// All returns the columns of the compound statement
+func (s CompoundSelectStmt) All() []Clause {
+	var clauses []Clause
+	for _, col := range s.ColumnList() {
+		clauses = append(clauses, col)
+	}
+	return clauses
+}
+
+// ColumnList returns the columns of the first select statement, without
+// their table name
+func (s CompoundSelectStmt) ColumnList() []ColumnElem {
+	if len(s.Selects) == 0 {
+		return nil
+	}
+	return s.Selects[0].ColumnList()
+}
+
+// C returns the column with the given name. An unknown column makes the
+// statements using it fail to build
+func (s CompoundSelectStmt) C(name string) ColumnElem {
+	for _, col := range s.ColumnList() {
+		if col.Name == name {
+			return col
+		}
+	}
+	return unknownColumn(name, "", "No such column '%s' in compound select", name)
+}
+
+// DefaultName returns an empty string because compound statements have no
+// name by default
+func (s CompoundSelectStmt) DefaultName() string {
+	return ""
+}
@@ -0,0 +1,125 @@
+package qb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompoundSelect(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	admins := Table(
+		"admins",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	dialect := NewDefaultDialect()
+
+	selUsers := Select(users.C("id"), users.C("email")).
+		From(users).
+		Where(users.C("id").Gt(10))
+	selAdmins := Select(admins.C("id"), admins.C("email")).From(admins)
+
+	for _, tt := range []struct {
+		compound CompoundSelectStmt
+		operator string
+	}{
+		{Union(selUsers, selAdmins), "UNION"},
+		{UnionAll(selUsers, selAdmins), "UNION ALL"},
+		{Intersect(selUsers, selAdmins), "INTERSECT"},
+		{Except(selUsers, selAdmins), "EXCEPT"},
+	} {
+		statement := tt.compound.Build(dialect)
+		assert.Equal(t, strings.Join([]string{
+			"(SELECT id, email",
+			"FROM users",
+			"WHERE id > ?)",
+			tt.operator,
+			"(SELECT id, email",
+			"FROM admins);",
+		}, "\n"), statement.SQL())
+		assert.Equal(t, []interface{}{10}, statement.Bindings())
+	}
+
+	union := Union(selUsers, selAdmins).
+		OrderBy(users.C("email")).Desc().
+		LimitOffset(10, 20)
+	ctx := NewCompilerContext(dialect)
+	assert.Equal(t, strings.Join([]string{
+		"(SELECT id, email",
+		"FROM users",
+		"WHERE id > ?)",
+		"UNION",
+		"(SELECT id, email",
+		"FROM admins)",
+		"ORDER BY email DESC",
+		"LIMIT 10 OFFSET 20",
+	}, "\n"), union.Accept(ctx))
+
+	union = Union(selUsers, selAdmins).OrderBy(union.C("id")).Asc().Limit(5).Offset(2)
+	ctx = NewCompilerContext(dialect)
+	assert.Contains(t, union.Accept(ctx), "\nORDER BY id ASC\nLIMIT 5 OFFSET 2")
+
+	assert.Equal(t, "", union.DefaultName())
+	assert.Equal(t, 2, len(union.All()))
+	assert.Equal(t, []ColumnElem{
+		{Name: "id", Type: Int()},
+		{Name: "email", Type: Varchar()},
+	}, union.ColumnList())
+	assert.NotNil(t, union.C("invalid").Err())
+	assert.Nil(t, union.C("id").Err())
+	assert.Nil(t, Union().ColumnList())
+}
+
+func TestCompoundSelectSubQuery(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	admins := Table(
+		"admins",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	dialect := NewDefaultDialect()
+
+	union := Union(
+		Select(users.C("email")).From(users),
+		Select(admins.C("email")).From(admins),
+	).OrderBy(users.C("email"))
+
+	sel := Select(union.C("email")).From(union)
+	ctx := NewCompilerContext(dialect)
+	assert.Equal(t, strings.Join([]string{
+		"SELECT email",
+		"FROM ((SELECT users.email",
+		"FROM users)",
+		"UNION",
+		"(SELECT admins.email",
+		"FROM admins)",
+		"ORDER BY email ASC) AS anon_1",
+	}, "\n"), sel.Accept(ctx))
+
+	everyone := Alias("everyone", union)
+	sel = Select(users.C("id")).
+		From(users).
+		InnerJoin(everyone, users.C("email"), everyone.C("email"))
+	ctx = NewCompilerContext(dialect)
+	assert.Equal(t, strings.Join([]string{
+		"SELECT users.id",
+		"FROM users",
+		"INNER JOIN ((SELECT users.email",
+		"FROM users)",
+		"UNION",
+		"(SELECT admins.email",
+		"FROM admins)",
+		"ORDER BY email ASC) AS everyone ON users.email = everyone.email",
+	}, "\n"), sel.Accept(ctx))
+	assert.False(t, ctx.InSubQuery)
+}
@@ -1,5 +1,7 @@
 package qb
 
+import "reflect"
+
 // conditional generators, comparator functions
 
 // Like generates a like conditional sql clause
@@ -7,6 +9,17 @@ func Like(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, "LIKE", GetClauseFrom(right))
 }
 
+// NotLike generates a not like conditional sql clause
+func NotLike(left Clause, right interface{}) BinaryExpressionClause {
+	return BinaryExpression(left, "NOT LIKE", GetClauseFrom(right))
+}
+
+// ILike generates a case-insensitive like conditional sql clause. The
+// dialects having no ILIKE operator compare the lower case strings
+func ILike(left Clause, right interface{}) ILikeClause {
+	return ILikeClause{BinaryExpression(left, "ILIKE", GetClauseFrom(right))}
+}
+
 // In generates an IN conditional sql clause
 func In(left Clause, values ...interface{}) InClause {
 	return InClause{BinaryExpressionClause{
@@ -25,16 +38,44 @@ func NotIn(left Clause, values ...interface{}) InClause {
 	}}
 }
 
-// NotEq generates a not equal conditional sql clause
+// NotEq generates a not equal conditional sql clause, or an IS NOT NULL
+// clause if right is nil or a nil pointer
 func NotEq(left Clause, right interface{}) BinaryExpressionClause {
+	if isNil(right) {
+		return IsNotNull(left)
+	}
 	return BinaryExpression(left, "!=", GetClauseFrom(right))
 }
 
-// Eq generates a equals conditional sql clause
+// Eq generates a equals conditional sql clause, or an IS NULL clause if
+// right is nil or a nil pointer
 func Eq(left Clause, right interface{}) BinaryExpressionClause {
+	if isNil(right) {
+		return IsNull(left)
+	}
 	return BinaryExpression(left, "=", GetClauseFrom(right))
 }
 
+// isNil returns true if a value is nil, or a typed nil pointer like the
+// fields of the nullable columns of a model
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// IsNull generates an IS NULL conditional sql clause
+func IsNull(left Clause) BinaryExpressionClause {
+	return BinaryExpression(left, "IS", SQLText("NULL"))
+}
+
+// IsNotNull generates an IS NOT NULL conditional sql clause
+func IsNotNull(left Clause) BinaryExpressionClause {
+	return BinaryExpression(left, "IS NOT", SQLText("NULL"))
+}
+
 // Gt generates a greater than conditional sql clause
 func Gt(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, ">", GetClauseFrom(right))
@@ -55,6 +96,21 @@ func Lte(left Clause, right interface{}) BinaryExpressionClause {
 	return BinaryExpression(left, "<=", GetClauseFrom(right))
 }
 
+// Between generates a BETWEEN low AND high conditional sql clause
+func Between(left Clause, low interface{}, high interface{}) BetweenClause {
+	return BetweenClause{left, GetClauseFrom(low), GetClauseFrom(high), false}
+}
+
+// NotBetween generates a NOT BETWEEN low AND high conditional sql clause
+func NotBetween(left Clause, low interface{}, high interface{}) BetweenClause {
+	return BetweenClause{left, GetClauseFrom(low), GetClauseFrom(high), true}
+}
+
+// Not negates a conditional sql clause
+func Not(clause Clause) NotClause {
+	return NotClause{clause}
+}
+
 // BinaryExpression generates a condition object to use in update, delete & select statements
 func BinaryExpression(left Clause, op string, right Clause) BinaryExpressionClause {
 	return BinaryExpressionClause{
@@ -85,3 +141,36 @@ type InClause struct {
 func (c InClause) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitIn(context, c)
 }
+
+// ILikeClause is a case-insensitive LIKE binary expression
+type ILikeClause struct {
+	BinaryExpressionClause
+}
+
+// Accept calls the compiler VisitILike method
+func (c ILikeClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitILike(context, c)
+}
+
+// BetweenClause is a [NOT] BETWEEN conditional clause
+type BetweenClause struct {
+	Left Clause
+	Low  Clause
+	High Clause
+	Not  bool
+}
+
+// Accept calls the compiler VisitBetween method
+func (c BetweenClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitBetween(context, c)
+}
+
+// NotClause is the negation of a conditional clause
+type NotClause struct {
+	Clause Clause
+}
+
+// Accept calls the compiler VisitNot method
+func (c NotClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitNot(context, c)
+}
@@ -109,6 +109,83 @@ func (suite *ConditionalTestSuite) TestConditionalLte() {
 	assert.Equal(suite.T(), []interface{}{1500}, bindings)
 }
 
+func (suite *ConditionalTestSuite) TestConditionalNotLike() {
+	notLike := NotLike(suite.country, "%land%")
+	sql := notLike.Accept(suite.ctx)
+
+	assert.Equal(suite.T(), "country NOT LIKE ?", sql)
+	assert.Equal(suite.T(), []interface{}{"%land%"}, suite.ctx.Binds)
+}
+
+func (suite *ConditionalTestSuite) TestConditionalILike() {
+	ilike := ILike(suite.country, "%LAND%")
+	sql := ilike.Accept(suite.ctx)
+
+	assert.Equal(suite.T(), "LOWER(country) LIKE LOWER(?)", sql)
+	assert.Equal(suite.T(), []interface{}{"%LAND%"}, suite.ctx.Binds)
+}
+
+func (suite *ConditionalTestSuite) TestConditionalIsNull() {
+	assert.Equal(suite.T(), "country IS NULL", IsNull(suite.country).Accept(suite.ctx))
+	assert.Equal(suite.T(), "country IS NOT NULL", IsNotNull(suite.country).Accept(suite.ctx))
+	assert.Equal(suite.T(), "country IS NULL", Eq(suite.country, nil).Accept(suite.ctx))
+	assert.Equal(suite.T(), "country IS NOT NULL", NotEq(suite.country, nil).Accept(suite.ctx))
+	assert.Equal(suite.T(), "country IS NULL", suite.country.Eq(nil).Accept(suite.ctx))
+	var country *string
+	assert.Equal(suite.T(), "country IS NULL", Eq(suite.country, country).Accept(suite.ctx))
+	assert.Equal(suite.T(), "country IS NOT NULL", NotEq(suite.country, country).Accept(suite.ctx))
+	assert.Empty(suite.T(), suite.ctx.Binds)
+
+	usa := "USA"
+	assert.Equal(suite.T(), "country = ?", Eq(suite.country, &usa).Accept(suite.ctx))
+	assert.Equal(suite.T(), []interface{}{&usa}, suite.ctx.Binds)
+}
+
+func (suite *ConditionalTestSuite) TestConditionalBetween() {
+	between := Between(suite.score, 1000, 2000)
+	sql := between.Accept(suite.ctx)
+
+	assert.Equal(suite.T(), "score BETWEEN ? AND ?", sql)
+	assert.Equal(suite.T(), []interface{}{1000, 2000}, suite.ctx.Binds)
+
+	sql = suite.score.NotBetween(0, 10).Accept(suite.ctx)
+	assert.Equal(suite.T(), "score NOT BETWEEN ? AND ?", sql)
+	assert.Equal(suite.T(), []interface{}{1000, 2000, 0, 10}, suite.ctx.Binds)
+}
+
+func (suite *ConditionalTestSuite) TestConditionalNot() {
+	not := Not(Or(suite.country.Eq("USA"), suite.score.Lt(10)))
+	sql := not.Accept(suite.ctx)
+
+	assert.Equal(suite.T(), "NOT (country = ? OR score < ?)", sql)
+	assert.Equal(suite.T(), []interface{}{"USA", 10}, suite.ctx.Binds)
+
+	sql = Not(suite.country.In("USA", "Sweden")).Accept(suite.ctx)
+	assert.Equal(suite.T(), "NOT (country IN (?, ?))", sql)
+
+	flags := Table("flags", Column("enabled", Boolean()))
+	sql = Not(Select(flags.C("enabled")).From(flags)).Accept(suite.ctx)
+	assert.Equal(suite.T(), "NOT (SELECT flags.enabled\nFROM flags)", sql)
+}
+
+type wrappingCompiler struct {
+	SQLCompiler
+}
+
+func (c wrappingCompiler) VisitBetween(context *CompilerContext, between BetweenClause) string {
+	return "BETWEEN(" + c.SQLCompiler.VisitBetween(context, between) + ")"
+}
+
+func (c wrappingCompiler) VisitNot(context *CompilerContext, not NotClause) string {
+	return "NOT(" + c.SQLCompiler.VisitNot(context, not) + ")"
+}
+
+func (suite *ConditionalTestSuite) TestConditionalCompiler() {
+	suite.ctx.Compiler = wrappingCompiler{NewSQLCompiler(suite.dialect)}
+	assert.Equal(suite.T(), "BETWEEN(score BETWEEN ? AND ?)", Between(suite.score, 1, 2).Accept(suite.ctx))
+	assert.Equal(suite.T(), "NOT(NOT (country = ?))", Not(suite.country.Eq("USA")).Accept(suite.ctx))
+}
+
 func TestConditionalTestSuite(t *testing.T) {
 	suite.Run(t, new(ConditionalTestSuite))
 }
@@ -82,6 +82,7 @@ func (c ForeignKeyConstraints) String(dialect Dialect) string {
 
 // ForeignKeyConstraint is the main struct for defining foreign key references
 type ForeignKeyConstraint struct {
+	Name           string // Optional, the database names the constraint if empty
 	Cols           []string
 	RefTable       string
 	RefCols        []string
@@ -90,8 +91,12 @@ type ForeignKeyConstraint struct {
 }
 
 func (fkey ForeignKeyConstraint) String(dialect Dialect) string {
-	ddl := fmt.Sprintf(
-		"\tFOREIGN KEY(%s) REFERENCES %s(%s)",
+	ddl := "\t"
+	if fkey.Name != "" {
+		ddl += fmt.Sprintf("CONSTRAINT %s ", dialect.Escape(fkey.Name))
+	}
+	ddl += fmt.Sprintf(
+		"FOREIGN KEY(%s) REFERENCES %s(%s)",
 		strings.Join(dialect.EscapeAll(fkey.Cols), ", "),
 		dialect.Escape(fkey.RefTable),
 		strings.Join(dialect.EscapeAll(fkey.RefCols), ", "),
@@ -105,16 +110,30 @@ func (fkey ForeignKeyConstraint) String(dialect Dialect) string {
 	return ddl
 }
 
-func checkFKeyCascadeAction(action string) string {
-	actionUp := strings.ToUpper(action)
-	if actionUp != "" &&
-		actionUp != "CASCADE" &&
-		actionUp != "NO ACTION" &&
-		actionUp != "RESTRICT" &&
-		actionUp != "SET NULL" {
-		panic("Invalid cascading action: " + actionUp)
+func checkFKeyCascadeAction(action string) error {
+	if action != "" &&
+		action != "CASCADE" &&
+		action != "NO ACTION" &&
+		action != "RESTRICT" &&
+		action != "SET NULL" {
+		return Error{Code: ErrInterface, Orig: fmt.Errorf("Invalid cascading action: %s", action)}
 	}
-	return actionUp
+	return nil
+}
+
+// Err returns the error of an invalid cascading action, if any
+func (fkey ForeignKeyConstraint) Err() error {
+	if err := checkFKeyCascadeAction(fkey.ActionOnUpdate); err != nil {
+		return err
+	}
+	return checkFKeyCascadeAction(fkey.ActionOnDelete)
+}
+
+// Named sets the name of the foreign key, which tells it from the other
+// foreign keys to the same table in JoinOnForeignKey
+func (fkey ForeignKeyConstraint) Named(name string) ForeignKeyConstraint {
+	fkey.Name = name
+	return fkey
 }
 
 // References set the reference part of the foreign key
@@ -126,13 +145,13 @@ func (fkey ForeignKeyConstraint) References(refTable string, refCols ...string)
 
 // OnUpdate set the ON UPDATE action
 func (fkey ForeignKeyConstraint) OnUpdate(action string) ForeignKeyConstraint {
-	fkey.ActionOnUpdate = checkFKeyCascadeAction(action)
+	fkey.ActionOnUpdate = strings.ToUpper(action)
 	return fkey
 }
 
 // OnDelete set the ON DELETE action
 func (fkey ForeignKeyConstraint) OnDelete(action string) ForeignKeyConstraint {
-	fkey.ActionOnDelete = checkFKeyCascadeAction(action)
+	fkey.ActionOnDelete = strings.ToUpper(action)
 	return fkey
 }
 
@@ -158,6 +177,9 @@ func (c UniqueKeyConstraint) String(dialect Dialect) string {
 // Table optionally set the constraint name based on the table name
 // if a name is already defined, it remains untouched
 func (c UniqueKeyConstraint) Table(name string) UniqueKeyConstraint {
+	if c.name != "" {
+		return c
+	}
 	return c.Name(
 		fmt.Sprintf("u_%s_%s", name, strings.Join(c.cols, "_")),
 	)
@@ -168,3 +190,8 @@ func (c UniqueKeyConstraint) Name(name string) UniqueKeyConstraint {
 	c.name = name
 	return c
 }
+
+// ConstraintName returns the constraint name
+func (c UniqueKeyConstraint) ConstraintName() string {
+	return c.name
+}
@@ -25,12 +25,9 @@ func TestConstraints(t *testing.T) {
 		ForeignKey("user_id", "user_email").References("users", "id", "email").String(dialect),
 		"FOREIGN KEY(user_id, user_email) REFERENCES users(id, email)")
 
-	assert.Panics(t, func() {
-		ForeignKey().OnUpdate("invalid")
-	})
-	assert.Panics(t, func() {
-		ForeignKey().OnDelete("invalid")
-	})
+	assert.Error(t, ForeignKey().OnUpdate("invalid").Err())
+	assert.Error(t, ForeignKey().OnDelete("invalid").Err())
+	assert.Nil(t, ForeignKey().OnUpdate("cascade").OnDelete("set null").Err())
 	assert.Equal(t,
 		"\tFOREIGN KEY(user_id) REFERENCES users(id) ON DELETE SET NULL",
 		ForeignKey("user_id").References("users", "id").OnDelete("SET NULL").String(dialect),
@@ -39,8 +36,17 @@ func TestConstraints(t *testing.T) {
 		"\tFOREIGN KEY(user_id) REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE",
 		ForeignKey("user_id").References("users", "id").OnUpdate("CASCADE").OnDelete("CASCADE").String(dialect),
 	)
+	fkey := ForeignKey("user_id").References("users", "id")
+	fkey.Name = "fk_user"
+	assert.Equal(t,
+		"\tCONSTRAINT fk_user FOREIGN KEY(user_id) REFERENCES users(id)",
+		fkey.String(dialect),
+	)
 
 	assert.Equal(t,
 		"CONSTRAINT u_users_id_email UNIQUE(id, email)",
 		UniqueKey("id", "email").Table("users").String(dialect))
+	assert.Equal(t,
+		"CONSTRAINT u_named UNIQUE(id, email)",
+		UniqueKey("id", "email").Name("u_named").Table("users").String(dialect))
 }
@@ -0,0 +1,91 @@
+package qb
+
+// With returns a new common table expression (CTE) given its name and
+// the select statement, or compound select statement, that defines it.
+// The CTE must be attached to a statement with the statement With() method,
+// and can then be used as a Selectable in From() and joins.
+func With(name string, sel Selectable) CTEClause {
+	return CTEClause{
+		Name:   name,
+		Select: sel,
+	}
+}
+
+// WithRecursive returns a new recursive common table expression.
+// It is rendered in a 'WITH RECURSIVE' clause, and is usually defined by
+// a UnionAll() of the initial select and the recursive one.
+func WithRecursive(name string, sel Selectable) CTEClause {
+	return CTEClause{
+		Name:      name,
+		Select:    sel,
+		Recursive: true,
+	}
+}
+
+// CTEClause is a common table expression. Its definition is compiled
+// by the Compiler VisitCTE method in the WITH clause of the statements it is
+// attached to, while the clause itself refers to the CTE by its name so it
+// can be used as a FROM element.
+type CTEClause struct {
+	Name        string
+	ColumnNames []string
+	Select      Selectable
+	Recursive   bool
+}
+
+// Columns sets explicit column names for the CTE
+func (c CTEClause) Columns(names ...string) CTEClause {
+	c.ColumnNames = names
+	return c
+}
+
+// Accept renders the CTE name
+func (c CTEClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitLabel(context, c.Name)
+}
+
+// All returns the CTE columns
+func (c CTEClause) All() []Clause {
+	var clauses []Clause
+	for _, col := range c.ColumnList() {
+		clauses = append(clauses, col)
+	}
+	return clauses
+}
+
+// ColumnList returns the CTE columns, with their "Table" field set to the
+// CTE name.
+// If no explicit column names were given, they are the columns of the
+// select list of the defining statement.
+func (c CTEClause) ColumnList() []ColumnElem {
+	var cols []ColumnElem
+	if len(c.ColumnNames) != 0 {
+		for _, name := range c.ColumnNames {
+			col := Column(name, TypeElem{})
+			col.Table = c.Name
+			cols = append(cols, col)
+		}
+		return cols
+	}
+	for _, col := range c.Select.ColumnList() {
+		col.Table = c.Name
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// C returns the CTE column with the given name. An unknown column makes the
+// statements using it fail to build
+func (c CTEClause) C(name string) ColumnElem {
+	for _, col := range c.ColumnList() {
+		if col.Name == name {
+			return col
+		}
+	}
+	return unknownColumn(name, c.Name, "No such column '%s' in CTE %s", name, c.Name)
+}
+
+// DefaultName returns the CTE name
+func (c CTEClause) DefaultName() string {
+	return c.Name
+}
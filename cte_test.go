@@ -0,0 +1,152 @@
+package qb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCTE(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("manager_id", Int()),
+	)
+	dialect := NewDefaultDialect()
+
+	managers := With(
+		"managers",
+		Select(users.C("id"), users.C("email")).
+			From(users).
+			Where(users.C("manager_id").Eq(nil)),
+	)
+
+	assert.Equal(t, "managers", managers.DefaultName())
+	assert.Equal(t, 2, len(managers.All()))
+	assert.Equal(t, "managers", managers.C("email").Table)
+	assert.NotNil(t, managers.C("manager_id").Err())
+
+	sel := Select(managers.C("email")).
+		From(managers).
+		With(managers)
+
+	ctx := NewCompilerContext(dialect)
+	assert.Equal(t, strings.Join([]string{
+		"WITH managers AS (SELECT users.id, users.email",
+		"FROM users",
+		"WHERE users.manager_id IS NULL)",
+		"SELECT email",
+		"FROM managers",
+	}, "\n"), sel.Accept(ctx))
+	assert.Equal(t, []interface{}{}, ctx.Binds)
+
+	sel = Select(users.C("email")).
+		From(users).
+		InnerJoin(managers, users.C("manager_id"), managers.C("id")).
+		Where(managers.C("email").Eq("boss@acme.com")).
+		With(managers)
+
+	ctx = NewCompilerContext(dialect)
+	assert.Equal(t, strings.Join([]string{
+		"WITH managers AS (SELECT users.id, users.email",
+		"FROM users",
+		"WHERE users.manager_id IS NULL)",
+		"SELECT users.email",
+		"FROM users",
+		"INNER JOIN managers ON users.manager_id = managers.id",
+		"WHERE managers.email = ?",
+	}, "\n"), sel.Accept(ctx))
+	assert.Equal(t, []interface{}{"boss@acme.com"}, ctx.Binds)
+}
+
+func TestCTERecursive(t *testing.T) {
+	counter := WithRecursive("counter", Select(SQLText("1"))).Columns("n")
+
+	assert.Equal(t, []ColumnElem{{Name: "n", Table: "counter"}}, counter.ColumnList())
+	assert.Equal(t, counter.C("n"), counter.All()[0])
+
+	sel := Select(counter.C("n")).From(counter).With(counter)
+	ctx := NewCompilerContext(NewDefaultDialect())
+	assert.Equal(t,
+		"WITH RECURSIVE counter(n) AS (SELECT 1)\nSELECT n\nFROM counter",
+		sel.Accept(ctx))
+
+	ref := Table("counter", Column("n", Int()))
+	counter = WithRecursive("counter", UnionAll(
+		Select(SQLText("1")),
+		Select(SQLText("n + 1")).From(ref).Where(ref.C("n").Lt(10)),
+	)).Columns("n")
+	sel = Select(counter.C("n")).From(counter).With(counter)
+	ctx = NewCompilerContext(NewDefaultDialect())
+	assert.Equal(t, strings.Join([]string{
+		"WITH RECURSIVE counter(n) AS ((SELECT 1)",
+		"UNION ALL",
+		"(SELECT n + 1",
+		"FROM counter",
+		"WHERE counter.n < ?))",
+		"SELECT n",
+		"FROM counter",
+	}, "\n"), sel.Accept(ctx))
+	assert.Equal(t, []interface{}{10}, ctx.Binds)
+}
+
+func TestCTEStatements(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	banned := Table(
+		"banned",
+		Column("email", Varchar()),
+	)
+	dialect := NewDefaultDialect()
+
+	bannedEmails := With("banned_emails", Select(banned.C("email")).From(banned))
+	inBanned := Exists(
+		Select(SQLText("1")).
+			From(bannedEmails).
+			Where(bannedEmails.C("email").Eq(users.C("email"))),
+	)
+
+	del := Delete(users).Where(inBanned).With(bannedEmails)
+	ctx := NewCompilerContext(dialect)
+	assert.Equal(t, strings.Join([]string{
+		"WITH banned_emails AS (SELECT banned.email",
+		"FROM banned)",
+		"DELETE FROM users",
+		"WHERE EXISTS(SELECT 1",
+		"FROM banned_emails",
+		"WHERE banned_emails.email = users.email)",
+	}, "\n"), del.Accept(ctx))
+
+	upd := Update(users).
+		Values(map[string]interface{}{"email": ""}).
+		Where(inBanned).
+		With(bannedEmails)
+	ctx = NewCompilerContext(dialect)
+	assert.Equal(t, strings.Join([]string{
+		"WITH banned_emails AS (SELECT banned.email",
+		"FROM banned)",
+		"UPDATE users",
+		"SET email = ?",
+		"WHERE EXISTS(SELECT 1",
+		"FROM banned_emails",
+		"WHERE banned_emails.email = users.email)",
+	}, "\n"), upd.Accept(ctx))
+	assert.Equal(t, []interface{}{""}, ctx.Binds)
+
+	ins := Insert(banned).
+		Values(map[string]interface{}{"email": "spam@acme.com"}).
+		With(bannedEmails)
+	statement := ins.Build(dialect)
+	assert.Equal(t, strings.Join([]string{
+		"WITH banned_emails AS (SELECT banned.email",
+		"FROM banned)",
+		"INSERT INTO banned(email)",
+		"VALUES(?);",
+	}, "\n"), statement.SQL())
+	assert.Equal(t, []interface{}{"spam@acme.com"}, statement.Bindings())
+}
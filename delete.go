@@ -11,6 +11,7 @@ func Delete(table TableElem) DeleteStmt {
 
 // DeleteStmt is the base struct for building delete queries
 type DeleteStmt struct {
+	with      []CTEClause
 	table     TableElem
 	where     *WhereClause
 	returning []ColumnElem
@@ -29,6 +30,13 @@ func (s DeleteStmt) Returning(cols ...ColumnElem) DeleteStmt {
 	return s
 }
 
+// With appends common table expressions to the WITH clause of the delete
+// statement
+func (s DeleteStmt) With(ctes ...CTEClause) DeleteStmt {
+	s.with = append(s.with[:len(s.with):len(s.with)], ctes...)
+	return s
+}
+
 // Accept implements Clause.Accept
 func (s DeleteStmt) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitDelete(context, s)
@@ -38,7 +46,12 @@ func (s DeleteStmt) Accept(context *CompilerContext) string {
 func (s DeleteStmt) Build(dialect Dialect) *Stmt {
 	context := NewCompilerContext(dialect)
 	statement := Statement()
-	statement.AddSQLClause(s.Accept(context))
+	sql := s.Accept(context)
+	if err := context.Err(); err != nil {
+		statement.SetError(err)
+		return statement
+	}
+	statement.AddSQLClause(sql)
 	statement.AddBinding(context.Binds...)
 
 	return statement
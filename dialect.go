@@ -1,12 +1,24 @@
 package qb
 
+import "fmt"
+
 // NewDialect returns a dialect pointer given driver
 func NewDialect(driver string) Dialect {
+	dialect, err := LookupDialect(driver)
+	if err != nil {
+		return &DefaultDialect{err: err}
+	}
+	return dialect
+}
+
+// LookupDialect returns the registered dialect of a driver, or an
+// ErrInterface error if there is no such dialect
+func LookupDialect(driver string) (Dialect, error) {
 	dialect, ok := DialectRegistry[driver]
-	if ok {
-		return dialect
+	if !ok {
+		return nil, Error{Code: ErrInterface, Orig: fmt.Errorf("No such dialect: %s", driver)}
 	}
-	panic("No such dialect: " + driver)
+	return dialect, nil
 }
 
 // DialectRegistry is a global registry of dialects
@@ -17,6 +29,15 @@ func RegisterDialect(name string, dialect Dialect) {
 	DialectRegistry[name] = dialect
 }
 
+// EngineDialect is an optional Dialect capability: the dialects having
+// settings that depend on the server, like its version, give each engine
+// its own dialect, set up for the server when the engine is created.
+// The registered dialects are shared by all the engines, so they are not
+// modified
+type EngineDialect interface {
+	ForEngine(engine *Engine) Dialect
+}
+
 // Dialect is the common interface for driver changes
 // It is for fixing compatibility issues of different drivers
 type Dialect interface {
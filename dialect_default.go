@@ -1,15 +1,21 @@
 package qb
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // DefaultDialect is a type of dialect that can be used with unsupported sql drivers
 type DefaultDialect struct {
 	escaping bool
+	// err is the error of NewDialect with an unknown driver, returned by the
+	// statements built with the dialect
+	err error
 }
 
 // NewDefaultDialect instanciate a DefaultDialect
 func NewDefaultDialect() Dialect {
-	return &DefaultDialect{false}
+	return &DefaultDialect{escaping: false}
 }
 
 // CompileType compiles a type into its DDL
@@ -65,7 +71,11 @@ func (d *DefaultDialect) GetCompiler() Compiler {
 
 // WrapError wraps a native error in a qb Error
 func (d *DefaultDialect) WrapError(err error) Error {
-	return Error{Orig: err}
+	qbErr := Error{Orig: err}
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		qbErr.Code = ErrCanceled
+	}
+	return qbErr
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package qb
 
 import (
+	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -26,10 +27,27 @@ func TestDefaultDialect(t *testing.T) {
 	err := errors.New("xxx")
 	qbErr := dialect.WrapError(err)
 	assert.Equal(t, err, qbErr.Orig)
+	assert.Equal(t, ErrAny, qbErr.Code)
+
+	assert.Equal(t, ErrCanceled, dialect.WrapError(context.Canceled).Code)
+	assert.Equal(t, ErrCanceled, dialect.WrapError(context.DeadlineExceeded).Code)
 }
 
 func TestGetDialect(t *testing.T) {
-	assert.Panics(t, func() {
-		NewDialect("unknown")
-	})
+	dialect := NewDialect("unknown")
+	users := Table("users", Column("id", Int()))
+	for _, stmt := range []*Stmt{
+		Select(users.C("id")).From(users).Build(dialect),
+		users.Build(dialect),
+	} {
+		assert.Equal(t, "", stmt.SQL())
+		qbErr, ok := stmt.Err().(Error)
+		assert.True(t, ok)
+		assert.Equal(t, ErrInterface, qbErr.Code)
+	}
+
+	_, err := LookupDialect("unknown")
+	assert.Error(t, err)
+	_, err = LookupDialect("default")
+	assert.Nil(t, err)
 }
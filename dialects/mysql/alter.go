@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slicebit/qb"
+)
+
+// CompileAlterTable renders an alter table statement with the MySQL syntax.
+// Changing the type or the nullability of a column needs its whole
+// definition, so the other attributes of the column are taken from the
+// altered table.
+func (d *Dialect) CompileAlterTable(stmt qb.AlterTableStmt) (string, error) {
+	if len(stmt.Actions) == 0 {
+		return qb.DefaultCompileAlterTable(d, stmt)
+	}
+	var actions []string
+	for _, action := range stmt.Actions {
+		sql, err := d.compileAlterTableAction(stmt, action)
+		if err != nil {
+			return "", err
+		}
+		actions = append(actions, sql)
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s", d.Escape(stmt.Table.Name), strings.Join(actions, ", ")), nil
+}
+
+func (d *Dialect) compileAlterTableAction(stmt qb.AlterTableStmt, action qb.AlterTableAction) (string, error) {
+	col := action.Column
+	switch action.Kind {
+	case qb.AlterColumnType:
+		col.Type = action.Type
+		return "MODIFY COLUMN " + col.String(d), nil
+	case qb.AlterSetNotNull:
+		if col.Type.Name == "" {
+			return "", qb.Error{
+				Code:   qb.ErrNotSupported,
+				Orig:   fmt.Errorf("Cannot set %s.%s not null without its type", stmt.Table.Name, col.Name),
+				Table:  stmt.Table.Name,
+				Column: col.Name,
+			}
+		}
+		if !qb.ColumnNotNull(col) {
+			col = col.NotNull()
+		}
+		return "MODIFY COLUMN " + col.String(d), nil
+	case qb.AlterRenameColumn:
+		if col.Type.Name == "" {
+			// MySQL 8.0 syntax
+			return qb.DefaultCompileAlterTableAction(d, stmt, action)
+		}
+		oldName := col.Name
+		col.Name = action.Name
+		return "CHANGE COLUMN " + d.Escape(oldName) + " " + col.String(d), nil
+	case qb.AlterDropConstraint:
+		for _, fkey := range stmt.Table.ForeignKeyConstraints.FKeys {
+			if fkey.Name == action.Name {
+				return "DROP FOREIGN KEY " + d.Escape(action.Name), nil
+			}
+		}
+		if stmt.Table.UniqueKeyConstraint.ConstraintName() == action.Name {
+			return "DROP INDEX " + d.Escape(action.Name), nil
+		}
+		// MySQL 8.0.19 syntax
+		return qb.DefaultCompileAlterTableAction(d, stmt, action)
+	default:
+		return qb.DefaultCompileAlterTableAction(d, stmt, action)
+	}
+}
@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/slicebit/qb"
+)
+
+// CompileSchemaChanges renders the schema changes, with the MySQL syntax for
+// altering columns and dropping indices and constraints
+func (d *Dialect) CompileSchemaChanges(changes []qb.SchemaChange) ([]string, error) {
+	var statements []string
+	for _, change := range changes {
+		alterTable := "ALTER TABLE " + d.Escape(change.Table.Name) + " "
+		switch change.Kind {
+		case qb.ChangeAlterColumn:
+			if err := qb.ColumnErr(d, change.Column); err != nil {
+				return nil, err
+			}
+			statements = append(statements, alterTable+"MODIFY COLUMN "+change.Column.String(d)+";")
+		case qb.ChangeAlterPrimaryKey:
+			var clauses []string
+			if len(change.OldTable.PrimaryKeyConstraint.Columns) != 0 {
+				clauses = append(clauses, "DROP PRIMARY KEY")
+			}
+			if len(change.Table.PrimaryKeyConstraint.Columns) != 0 {
+				clauses = append(clauses, "ADD "+change.Table.PrimaryKeyConstraint.String(d))
+			}
+			statements = append(statements, alterTable+strings.Join(clauses, ", ")+";")
+		case qb.ChangeDropIndex:
+			statements = append(statements, fmt.Sprintf(
+				"DROP INDEX %s ON %s;",
+				d.Escape(change.Index.Name),
+				d.Escape(change.Table.Name),
+			))
+		case qb.ChangeDropUniqueKey:
+			statements = append(statements, alterTable+"DROP INDEX "+d.Escape(change.UniqueKey.ConstraintName())+";")
+		case qb.ChangeDropForeignKey:
+			if change.ForeignKey.Name == "" {
+				return nil, qb.Error{
+					Code:  qb.ErrNotSupported,
+					Orig:  fmt.Errorf("Cannot drop a foreign key without name on %s", change.Table.Name),
+					Table: change.Table.Name,
+				}
+			}
+			statements = append(statements, alterTable+"DROP FOREIGN KEY "+d.Escape(change.ForeignKey.Name)+";")
+		default:
+			sqls, err := qb.DefaultCompileSchemaChange(d, change)
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, sqls...)
+		}
+	}
+	return statements, nil
+}
@@ -1,7 +1,10 @@
 package mysql
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/go-sql-driver/mysql"
@@ -11,16 +14,34 @@ import (
 //go:generate go run ./tools/generrors.go
 //go:generate gofmt -w errors.go
 
-const ()
+const (
+	// ER_CHECK_CONSTRAINT_VIOLATED is raised by MySQL 8.0.16+, which the
+	// generated errors do not cover
+	ER_CHECK_CONSTRAINT_VIOLATED = 3819
+)
+
+var (
+	// Duplicate entry 'al@pacino.com' for key 'users.email'
+	dupEntryRe = regexp.MustCompile(`(?s)^Duplicate entry '.*' for key '(.+)'$`)
+	// Cannot add or update a child row: a foreign key constraint fails
+	// (`db`.`sessions`, CONSTRAINT `fk` FOREIGN KEY (`user_id`) REFERENCES ...
+	foreignKeyRe = regexp.MustCompile("foreign key constraint fails \\((?:`[^`]*`\\.)?`([^`]*)`, CONSTRAINT `([^`]*)` FOREIGN KEY \\(([^)]*)\\)")
+	// Column 'email' cannot be null
+	badNullRe = regexp.MustCompile(`^Column '(.+)' cannot be null$`)
+	// Check constraint 'users_chk_1' is violated.
+	checkRe = regexp.MustCompile(`^Check constraint '(.+)' is violated`)
+)
 
 // Dialect is a type of dialect that can be used with mysql driver
 type Dialect struct {
 	escaping bool
+	version  string
+	major    int
 }
 
 // NewDialect returns a new MysqlDialect
 func NewDialect() qb.Dialect {
-	return &Dialect{false}
+	return &Dialect{escaping: false}
 }
 
 func init() {
@@ -68,9 +89,61 @@ func (d *Dialect) AutoIncrement(column *qb.ColumnElem) string {
 	return colSpec
 }
 
+// ForEngine implements qb.EngineDialect: each engine has its own dialect,
+// having the version of its server. If the server cannot be queried, the
+// version stays unknown, see SetServerVersion
+func (d *Dialect) ForEngine(engine *qb.Engine) qb.Dialect {
+	dialect := *d
+	dialect.DetectServerVersion(engine)
+	return &dialect
+}
+
+// SetServerVersion sets the version of the server, as returned by
+// SELECT VERSION(), so the compiler refuses the features the server does
+// not have. While the version is unknown, these features are refused.
+// It is not safe to call while the dialect compiles statements. The engines
+// detect the version of their server when they are created
+func (d *Dialect) SetServerVersion(version string) {
+	d.version = version
+	d.major = 0
+	fmt.Sscanf(version, "%d", &d.major)
+}
+
+// DetectServerVersion sets the version of the server of an engine, which
+// is queried with SELECT VERSION(). The engine dialects call it when they
+// are created, it can be called again if the server was not reachable:
+// engine.Dialect().(*mysql.Dialect).DetectServerVersion(engine)
+func (d *Dialect) DetectServerVersion(engine *qb.Engine) error {
+	var version string
+	if err := engine.DB().Get(&version, "SELECT VERSION()"); err != nil {
+		return err
+	}
+	d.SetServerVersion(version)
+	return nil
+}
+
+// SupportsWindowFunctions returns whether the server has window functions,
+// which are available since mysql 8. It is false if the version is unknown
+func (d *Dialect) SupportsWindowFunctions() bool {
+	return d.major >= 8
+}
+
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *Dialect) SupportsUnsigned() bool { return true }
 
+// SupportsTransactionalDDL returns whether the DDL statements can be rolled back or not
+func (d *Dialect) SupportsTransactionalDDL() bool { return false }
+
+// BatchLimits returns the maximum number of bind parameters of a statement,
+// and the max_allowed_packet of the server
+func (d *Dialect) BatchLimits(engine *qb.Engine) (int, int, error) {
+	var maxAllowedPacket int
+	if err := engine.DB().Get(&maxAllowedPacket, "SELECT @@max_allowed_packet"); err != nil {
+		return 0, 0, err
+	}
+	return 65535, maxAllowedPacket, nil
+}
+
 // Driver returns the current driver of dialect
 func (d *Dialect) Driver() string {
 	return "mysql"
@@ -84,12 +157,19 @@ func (d *Dialect) GetCompiler() qb.Compiler {
 // WrapError wraps a native error in a qb Error
 func (d *Dialect) WrapError(err error) qb.Error {
 	qbErr := qb.Error{Orig: err}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		qbErr.Code = qb.ErrCanceled
+		return qbErr
+	}
 	mErr, ok := err.(*mysql.MySQLError)
 	if !ok {
 		return qbErr
 	}
 	// Error mapping logic is copied from MySQL-python-1.2.5
 	switch mErr.Number {
+	case ER_QUERY_INTERRUPTED,
+		ER_QUERY_TIMEOUT:
+		qbErr.Code = qb.ErrCanceled
 	case CR_COMMANDS_OUT_OF_SYNC,
 		ER_DB_CREATE_EXISTS,
 		ER_SYNTAX_ERROR,
@@ -111,12 +191,39 @@ func (d *Dialect) WrapError(err error) qb.Error {
 		ER_DATETIME_FUNCTION_OVERFLOW:
 		qbErr.Code = qb.ErrData
 	case ER_DUP_ENTRY,
-		ER_DUP_UNIQUE,
-		ER_NO_REFERENCED_ROW,
+		ER_DUP_UNIQUE:
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrUniqueViolation
+		if m := dupEntryRe.FindStringSubmatch(mErr.Message); m != nil {
+			key := m[1]
+			// MySQL 8.0.19+ prefixes the key with the table name
+			if i := strings.LastIndex(key, "."); i != -1 {
+				qbErr.Table, key = key[:i], key[i+1:]
+			}
+			qbErr.Constraint = key
+		}
+	case ER_NO_REFERENCED_ROW,
 		ER_NO_REFERENCED_ROW_2,
 		ER_ROW_IS_REFERENCED,
-		ER_ROW_IS_REFERENCED_2,
-		ER_CANNOT_ADD_FOREIGN:
+		ER_ROW_IS_REFERENCED_2:
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrForeignKeyViolation
+		if m := foreignKeyRe.FindStringSubmatch(mErr.Message); m != nil {
+			qbErr.Table = m[1]
+			qbErr.Constraint = m[2]
+			if !strings.Contains(m[3], ",") {
+				qbErr.Column = strings.Trim(m[3], "`")
+			}
+		}
+	case ER_BAD_NULL_ERROR:
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrNotNullViolation
+		if m := badNullRe.FindStringSubmatch(mErr.Message); m != nil {
+			qbErr.Column = m[1]
+		}
+	case ER_CHECK_CONSTRAINT_VIOLATED:
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrCheckViolation
+		if m := checkRe.FindStringSubmatch(mErr.Message); m != nil {
+			qbErr.Constraint = m[1]
+		}
+	case ER_CANNOT_ADD_FOREIGN:
 		qbErr.Code = qb.ErrIntegrity
 	case ER_WARNING_NOT_COMPLETE_ROLLBACK,
 		ER_NOT_SUPPORTED_YET,
@@ -133,11 +240,104 @@ func (d *Dialect) WrapError(err error) qb.Error {
 	return qbErr
 }
 
+// DefaultValuesInsert returns INSERT INTO ... () VALUES (), as mysql has no
+// DEFAULT VALUES clause
+func (d *Dialect) DefaultValuesInsert(table string) string {
+	return fmt.Sprintf("INSERT INTO %s () VALUES ()", table)
+}
+
 // MysqlCompiler is a SQLCompiler specialised for Mysql
 type MysqlCompiler struct {
 	qb.SQLCompiler
 }
 
+// VisitFunc compiles a function call. mysql concatenates the strings with
+// the CONCAT function, || being the OR operator by default
+func (c MysqlCompiler) VisitFunc(context *qb.CompilerContext, fn qb.FuncClause) string {
+	if fn.Name == "CONCAT" {
+		return qb.CompileFuncCall(context, "CONCAT", fn.Args)
+	}
+	return c.SQLCompiler.VisitFunc(context, fn)
+}
+
+// VisitCast compiles a CAST(<clause> AS <type>) expression. mysql only
+// casts to a few types, so the type is converted to the closest one
+func (c MysqlCompiler) VisitCast(context *qb.CompilerContext, cast qb.CastClause) string {
+	return fmt.Sprintf(
+		"CAST(%s AS %s)",
+		qb.CompileClause(context, cast.Clause),
+		castType(context.Dialect.CompileType(cast.Type)),
+	)
+}
+
+// castType returns the type of a CAST given the DDL of the type
+func castType(ddl string) string {
+	name, size := ddl, ""
+	if i := strings.Index(ddl, "("); i != -1 {
+		name, size = ddl[:i], ddl[i:]
+		if j := strings.Index(size, ")"); j != -1 {
+			size = size[:j+1]
+		}
+	}
+	switch {
+	case strings.HasSuffix(ddl, " UNSIGNED"):
+		return "UNSIGNED"
+	case strings.HasSuffix(name, "INT"), name == "BOOLEAN":
+		return "SIGNED"
+	case name == "VARCHAR", name == "CHAR":
+		return "CHAR" + size
+	case name == "TEXT":
+		return "CHAR"
+	case name == "FLOAT", name == "DOUBLE", name == "REAL":
+		return "DOUBLE"
+	case name == "TIMESTAMP":
+		return "DATETIME"
+	case name == "BLOB":
+		return "BINARY"
+	case name == "NUMERIC":
+		return "DECIMAL" + size
+	}
+	return ddl
+}
+
+// VisitJoin compiles a JOIN clause. mysql has no FULL OUTER JOIN
+func (c MysqlCompiler) VisitJoin(context *qb.CompilerContext, join qb.JoinClause) string {
+	if join.JoinType == "FULL OUTER JOIN" {
+		context.AddError(qb.Error{
+			Code: qb.ErrNotSupported,
+			Orig: fmt.Errorf("FULL OUTER JOIN is not supported by mysql"),
+		})
+	}
+	return c.SQLCompiler.VisitJoin(context, join)
+}
+
+// VisitOrderTerm compiles a term of an ORDER BY. mysql has no NULLS FIRST
+// and NULLS LAST, so they are emulated by ordering ISNULL(term) first
+func (c MysqlCompiler) VisitOrderTerm(context *qb.CompilerContext, term qb.OrderTermClause) string {
+	return qb.CompileEmulatedNullsOrder(context, term, qb.Func("ISNULL", term.Clause))
+}
+
+// VisitWindow compiles a window specification. The windows are not
+// supported before mysql 8, and mysql has no GROUPS frames
+func (c MysqlCompiler) VisitWindow(context *qb.CompilerContext, window qb.WindowClause) string {
+	if d, ok := context.Dialect.(*Dialect); ok && !d.SupportsWindowFunctions() {
+		version := d.version
+		if version == "" {
+			version = "of unknown version"
+		}
+		context.AddError(qb.Error{
+			Code: qb.ErrNotSupported,
+			Orig: fmt.Errorf("Window functions are not supported by mysql %s", version),
+		})
+	} else if window.Frame != nil && window.Frame.Mode == "GROUPS" {
+		context.AddError(qb.Error{
+			Code: qb.ErrNotSupported,
+			Orig: fmt.Errorf("GROUPS window frames are not supported by mysql"),
+		})
+	}
+	return c.SQLCompiler.VisitWindow(context, window)
+}
+
 // VisitUpsert generates INSERT INTO ... VALUES ... ON DUPLICATE KEY UPDATE ...
 func (MysqlCompiler) VisitUpsert(context *qb.CompilerContext, upsert qb.UpsertStmt) string {
 	var (
@@ -145,20 +345,20 @@ func (MysqlCompiler) VisitUpsert(context *qb.CompilerContext, upsert qb.UpsertSt
 		values   []string
 	)
 
-	for k, v := range upsert.ValuesMap {
-		colNames = append(colNames, context.Compiler.VisitLabel(context, k))
-		context.Binds = append(context.Binds, v)
+	for _, cv := range upsert.ValueSet {
+		colNames = append(colNames, context.Compiler.VisitLabel(context, cv.Column))
+		context.Binds = append(context.Binds, cv.Value)
 		values = append(values, "?")
 	}
 
 	updates := []string{}
-	for k, v := range upsert.ValuesMap {
+	for _, cv := range upsert.ValueSet {
 		updates = append(updates, fmt.Sprintf(
 			"%s = %s",
-			context.Dialect.Escape(k),
+			context.Dialect.Escape(cv.Column),
 			"?",
 		))
-		context.Binds = append(context.Binds, v)
+		context.Binds = append(context.Binds, cv.Value)
 	}
 
 	sql := fmt.Sprintf(
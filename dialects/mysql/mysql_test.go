@@ -1,8 +1,10 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -45,6 +47,7 @@ func (suite *MysqlTestSuite) TestUUID() {
 func (suite *MysqlTestSuite) TestDialect() {
 	dialect := qb.NewDialect("mysql")
 	assert.Equal(suite.T(), true, dialect.SupportsUnsigned())
+	assert.Equal(suite.T(), false, dialect.(qb.TransactionalDDL).SupportsTransactionalDDL())
 	assert.Equal(suite.T(), "test", dialect.Escape("test"))
 	assert.Equal(suite.T(), false, dialect.Escaping())
 	dialect.SetEscaping(true)
@@ -69,12 +72,55 @@ func (suite *MysqlTestSuite) TestWrapError() {
 		{ER_CANNOT_ADD_FOREIGN, qb.ErrIntegrity},
 		{ER_FEATURE_DISABLED, qb.ErrNotSupported},
 		{ER_CHECKREAD, qb.ErrOperational},
+		{ER_QUERY_INTERRUPTED, qb.ErrCanceled},
 		{999, qb.ErrInternal},
 	} {
 		mErr := mysql.MySQLError{Number: tt.mErr}
 		qbErr := dialect.WrapError(&mErr)
 		assert.Equal(suite.T(), tt.qbCode, qbErr.Code)
 	}
+
+	for _, tt := range []struct {
+		mErr     mysql.MySQLError
+		expected qb.Error
+	}{
+		{
+			mysql.MySQLError{Number: ER_DUP_ENTRY, Message: "Duplicate entry 'al@pacino.com' for key 'email'"},
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrUniqueViolation, Constraint: "email"},
+		},
+		{
+			mysql.MySQLError{Number: ER_DUP_ENTRY, Message: "Duplicate entry '1' for key 'users.PRIMARY'"},
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrUniqueViolation, Table: "users", Constraint: "PRIMARY"},
+		},
+		{
+			mysql.MySQLError{Number: ER_NO_REFERENCED_ROW_2, Message: "Cannot add or update a child row: " +
+				"a foreign key constraint fails (`qb_test`.`sessions`, CONSTRAINT `sessions_ibfk_1` " +
+				"FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))"},
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrForeignKeyViolation, Table: "sessions", Column: "user_id", Constraint: "sessions_ibfk_1"},
+		},
+		{
+			mysql.MySQLError{Number: ER_ROW_IS_REFERENCED_2, Message: "Cannot delete or update a parent row: " +
+				"a foreign key constraint fails (`qb_test`.`sessions`, CONSTRAINT `fk_user` " +
+				"FOREIGN KEY (`user_id`, `user_email`) REFERENCES `users` (`id`, `email`))"},
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrForeignKeyViolation, Table: "sessions", Constraint: "fk_user"},
+		},
+		{
+			mysql.MySQLError{Number: ER_BAD_NULL_ERROR, Message: "Column 'email' cannot be null"},
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrNotNullViolation, Column: "email"},
+		},
+		{
+			mysql.MySQLError{Number: ER_CHECK_CONSTRAINT_VIOLATED, Message: "Check constraint 'users_chk_1' is violated."},
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrCheckViolation, Constraint: "users_chk_1"},
+		},
+	} {
+		mErr := tt.mErr
+		tt.expected.Orig = &mErr
+		assert.Equal(suite.T(), tt.expected, dialect.WrapError(&mErr))
+	}
+
+	assert.Equal(suite.T(), qb.ErrCanceled, dialect.WrapError(context.Canceled).Code)
+	assert.Equal(suite.T(), qb.ErrCanceled, dialect.WrapError(context.DeadlineExceeded).Code)
+	assert.Equal(suite.T(), qb.ErrCanceled, dialect.WrapError(fmt.Errorf("query: %w", context.Canceled)).Code)
 }
 
 func (suite *MysqlTestSuite) TestMysql() {
@@ -234,6 +280,275 @@ func (suite *MysqlTestSuite) TestUpsert() {
 	assert.Equal(suite.T(), 6, len(binds))
 }
 
+func (suite *MysqlTestSuite) TestReflectType() {
+	assert.Equal(suite.T(), qb.Int(), reflectType("int(11)"))
+	assert.Equal(suite.T(), qb.BigInt().Unsigned(), reflectType("bigint(20) unsigned"))
+	assert.Equal(suite.T(), qb.Boolean(), reflectType("tinyint(1)"))
+	assert.Equal(suite.T(), qb.Varchar().Size(40), reflectType("varchar(40)"))
+	assert.Equal(suite.T(), qb.Decimal().Precision(10, 2), reflectType("decimal(10,2)"))
+	assert.Equal(suite.T(), qb.Type("enum('a','b')"), reflectType("enum('a','b')"))
+}
+
+func (suite *MysqlTestSuite) TestReflect() {
+	fkey := qb.ForeignKey("user_id").References("reflect_users", "id").OnDelete("CASCADE")
+	fkey.Name = "fk_reflect_user"
+	users := qb.Table(
+		"reflect_users",
+		qb.Column("id", qb.BigInt()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()).NotNull().Unique(),
+		qb.Column("first_name", qb.Varchar().Size(40)),
+		qb.Column("last_name", qb.Varchar().Size(40)),
+		qb.UniqueKey("first_name", "last_name"),
+	).Index("last_name")
+	sessions := qb.Table(
+		"reflect_sessions",
+		qb.Column("user_id", qb.BigInt()),
+		qb.Column("token", qb.Varchar().Size(36)),
+		qb.PrimaryKey("user_id", "token"),
+		fkey,
+	)
+	suite.metadata.AddTable(users)
+	suite.metadata.AddTable(sessions)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+
+	metadata := qb.MetaData()
+	err := metadata.Reflect(suite.engine, "reflect_sessions", "reflect_users")
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "reflect_users", metadata.Tables()[0].Name)
+
+	reflected := metadata.Table("reflect_users")
+	assert.Equal(suite.T(), []string{"id"}, reflected.PrimaryKeyConstraint.Columns)
+	assert.True(suite.T(), reflected.C("id").Options.AutoIncrement)
+	assert.Equal(suite.T(), "email VARCHAR(255) NOT NULL UNIQUE", reflected.C("email").String(suite.engine.Dialect()))
+	assert.Equal(suite.T(), qb.UniqueKey("first_name", "last_name").Table("reflect_users"), reflected.UniqueKeyConstraint)
+	assert.Equal(suite.T(), []qb.IndexElem{
+		{Table: "reflect_users", Name: "i_last_name", Columns: []string{"last_name"}},
+	}, reflected.Indices)
+
+	reflected = metadata.Table("reflect_sessions")
+	assert.Equal(suite.T(), []string{"user_id", "token"}, reflected.PrimaryKeyConstraint.Columns)
+	assert.Equal(suite.T(), []qb.ForeignKeyConstraint{fkey}, reflected.ForeignKeyConstraints.FKeys)
+}
+
+func (suite *MysqlTestSuite) TestWindow() {
+	dialect := NewDialect()
+	sales := qb.Table(
+		"sales",
+		qb.Column("region", qb.Varchar()),
+		qb.Column("amount", qb.Int()),
+	)
+	sel := qb.Select(
+		sales.C("region"),
+		qb.Sum(sales.C("amount")).Over(qb.Window().PartitionBy(sales.C("region")).Rows(qb.UnboundedPreceding, qb.CurrentRow)),
+	).From(sales)
+
+	// the window functions are refused while the version is unknown
+	stmt := sel.Build(dialect)
+	assert.Equal(suite.T(), qb.ErrNotSupported, stmt.Err().(qb.Error).Code)
+	assert.Contains(suite.T(), stmt.Err().(qb.Error).Orig.Error(), "mysql of unknown version")
+
+	dialect.(*Dialect).SetServerVersion("8.0.21")
+	assert.True(suite.T(), dialect.(*Dialect).SupportsWindowFunctions())
+	stmt = sel.Build(dialect)
+	assert.Nil(suite.T(), stmt.Err())
+	assert.Equal(suite.T(), "SELECT region, SUM(amount) OVER (PARTITION BY region ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)\nFROM sales;", stmt.SQL())
+
+	groups := qb.Select(qb.Rank().Over(qb.Window().OrderBy(sales.C("amount")).Groups(qb.Preceding(1), qb.CurrentRow))).From(sales)
+	err := groups.Build(dialect).Err()
+	assert.Equal(suite.T(), qb.ErrNotSupported, err.(qb.Error).Code)
+
+	dialect.(*Dialect).SetServerVersion("5.7.31-log")
+	assert.False(suite.T(), dialect.(*Dialect).SupportsWindowFunctions())
+	stmt = sel.Window("w", qb.Window()).Build(dialect)
+	err = stmt.Err()
+	assert.Equal(suite.T(), qb.ErrNotSupported, err.(qb.Error).Code)
+	assert.Contains(suite.T(), err.(qb.Error).Orig.Error(), "mysql 5.7.31-log")
+	assert.Equal(suite.T(), "", stmt.SQL())
+}
+
+func (suite *MysqlTestSuite) TestServerVersion() {
+	other, err := qb.New("mysql", mysqlDsn)
+	assert.Nil(suite.T(), err)
+	defer other.Close()
+
+	// the engines have their own dialect, not the registered one
+	dialect := suite.engine.Dialect().(*Dialect)
+	assert.False(suite.T(), qb.NewDialect("mysql") == qb.Dialect(dialect))
+	assert.False(suite.T(), other.Dialect() == qb.Dialect(dialect))
+	dialect.SetServerVersion("5.7.31-log")
+	assert.False(suite.T(), dialect.SupportsWindowFunctions())
+	assert.Equal(suite.T(), "", qb.NewDialect("mysql").(*Dialect).version)
+
+	// the version of the server is detected when the engine is created
+	assert.NotEqual(suite.T(), "", other.Dialect().(*Dialect).version)
+	assert.Nil(suite.T(), dialect.DetectServerVersion(suite.engine))
+	assert.Equal(suite.T(), other.Dialect().(*Dialect).version, dialect.version)
+}
+
+func (suite *MysqlTestSuite) TestFunctions() {
+	dialect := NewDialect()
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("first_name", qb.Varchar()),
+		qb.Column("last_name", qb.Varchar()),
+		qb.Column("score", qb.Float()),
+	)
+	sel := qb.Select(
+		users.C("first_name").Concat(" ", users.C("last_name")),
+		qb.Length(users.C("last_name")),
+		qb.Now(),
+		qb.Cast(users.C("first_name"), qb.Int()),
+		qb.Cast(users.C("id"), qb.Int().Unsigned()),
+		qb.Cast(users.C("id"), qb.Varchar().Size(10)),
+		qb.Cast(users.C("score"), qb.Float()),
+		qb.Cast(users.C("first_name"), qb.Timestamp()),
+		qb.Cast(users.C("score"), qb.Decimal().Precision(10, 2)),
+	).From(users)
+	assert.Equal(suite.T(), "SELECT "+
+		"CONCAT(first_name, ?, last_name), "+
+		"CHAR_LENGTH(last_name), "+
+		"CURRENT_TIMESTAMP, "+
+		"CAST(first_name AS SIGNED), "+
+		"CAST(id AS UNSIGNED), "+
+		"CAST(id AS CHAR(10)), "+
+		"CAST(score AS DOUBLE), "+
+		"CAST(first_name AS DATETIME), "+
+		"CAST(score AS DECIMAL(10, 2))\n"+
+		"FROM users;", sel.Build(dialect).SQL())
+
+	sel = qb.Select(qb.Cast(qb.Select(qb.Max(users.C("score"))).From(users), qb.Int()))
+	assert.Equal(suite.T(), "SELECT CAST((SELECT MAX(users.score)\nFROM users) AS SIGNED);", sel.Build(dialect).SQL())
+}
+
+func (suite *MysqlTestSuite) TestOrderByNulls() {
+	dialect := NewDialect()
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("last_login", qb.Timestamp()).Null(),
+	)
+	sel := qb.Select(users.C("id")).From(users).OrderBy(
+		qb.Desc(users.C("last_login")).NullsFirst(),
+		qb.Asc(users.C("last_login")).NullsLast(),
+		users.C("id"),
+	)
+	assert.Equal(suite.T(), "SELECT id\nFROM users\n"+
+		"ORDER BY ISNULL(last_login) DESC, last_login DESC, ISNULL(last_login) ASC, last_login ASC, id ASC;",
+		sel.Build(dialect).SQL())
+}
+
+func (suite *MysqlTestSuite) TestFullOuterJoin() {
+	dialect := NewDialect()
+	users := qb.Table("users", qb.Column("id", qb.Int()).PrimaryKey())
+	sessions := qb.Table("sessions", qb.Column("user_id", qb.Int()))
+	err := qb.Select(users.C("id")).
+		From(users).
+		FullOuterJoin(sessions, users.C("id"), sessions.C("user_id")).
+		Build(dialect).Err()
+	assert.Equal(suite.T(), qb.ErrNotSupported, err.(qb.Error).Code)
+}
+
+func (suite *MysqlTestSuite) TestInsertDefaultValues() {
+	dialect := NewDialect()
+	users := qb.Table("users", qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement())
+
+	stmt := qb.Insert(users).DefaultValues().Build(dialect)
+	assert.Equal(suite.T(), "INSERT INTO users () VALUES ();", stmt.SQL())
+
+	sel := qb.Select(users.C("id")).From(users)
+	stmt = qb.Insert(users).Columns(users.C("id")).FromSelect(sel).Build(dialect)
+	assert.Equal(suite.T(), "INSERT INTO users(id)\nSELECT id\nFROM users;", stmt.SQL())
+}
+
+func (suite *MysqlTestSuite) TestAlterTable() {
+	dialect := NewDialect()
+	fkey := qb.ForeignKey("user_id").References("users", "id")
+	fkey.Name = "fk_user"
+	table := qb.Table(
+		"sessions",
+		qb.Column("user_id", qb.Int()),
+		qb.Column("token", qb.Varchar()).Default("none"),
+		qb.UniqueKey("user_id", "token").Name("u_token"),
+		fkey,
+	)
+
+	sql := qb.AlterTable(table).
+		AlterColumnType("token", qb.Varchar().Size(36)).
+		SetNotNull("user_id").
+		RenameColumn("token", "secret").
+		Build(dialect).SQL()
+	assert.Equal(suite.T(), "ALTER TABLE sessions "+
+		"MODIFY COLUMN token VARCHAR(36) DEFAULT 'none', "+
+		"MODIFY COLUMN user_id INT NOT NULL, "+
+		"CHANGE COLUMN token secret VARCHAR(255) DEFAULT 'none';", sql)
+
+	sql = qb.AlterTable(table).
+		DropConstraint("fk_user").
+		DropConstraint("u_token").
+		DropConstraint("c_other").
+		Build(dialect).SQL()
+	assert.Equal(suite.T(), "ALTER TABLE sessions "+
+		"DROP FOREIGN KEY fk_user, DROP INDEX u_token, DROP CONSTRAINT c_other;", sql)
+
+	sql = qb.AlterTable(table).RenameColumn("expires", "expires_at").RenameTo("tokens").Build(dialect).SQL()
+	assert.Equal(suite.T(), "ALTER TABLE sessions RENAME COLUMN expires TO expires_at, RENAME TO tokens;", sql)
+
+	stmt := qb.AlterTable(table).SetNotNull("expires").Build(dialect)
+	assert.Equal(suite.T(), qb.ErrNotSupported, stmt.Err().(qb.Error).Code)
+	assert.Equal(suite.T(), "expires", stmt.Err().(qb.Error).Column)
+}
+
+func (suite *MysqlTestSuite) TestSchemaChanges() {
+	dialect := NewDialect()
+	fkey := qb.ForeignKey("user_id").References("users", "id")
+	fkey.Name = "fk_user"
+	oldTable := qb.Table(
+		"sessions",
+		qb.Column("user_id", qb.Int()),
+		qb.Column("token", qb.Varchar()),
+		qb.UniqueKey("user_id", "token").Name("u_token"),
+		fkey,
+	).Index("token")
+	table := qb.Table(
+		"sessions",
+		qb.Column("user_id", qb.Int()),
+		qb.Column("token", qb.Varchar().Size(36)).NotNull(),
+	)
+	from := qb.MetaData()
+	from.AddTable(oldTable)
+	to := qb.MetaData()
+	to.AddTable(table)
+
+	statements, err := qb.CompileSchemaChanges(dialect, qb.Diff(dialect, from, to))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{
+		"ALTER TABLE sessions DROP FOREIGN KEY fk_user;",
+		"DROP INDEX i_token ON sessions;",
+		"ALTER TABLE sessions DROP INDEX u_token;",
+		"ALTER TABLE sessions MODIFY COLUMN token VARCHAR(36) NOT NULL;",
+	}, statements)
+
+	fkey.Name = ""
+	_, err = qb.CompileSchemaChanges(dialect, []qb.SchemaChange{{
+		Kind:       qb.ChangeDropForeignKey,
+		Table:      table,
+		ForeignKey: fkey,
+	}})
+	assert.Equal(suite.T(), qb.ErrNotSupported, err.(qb.Error).Code)
+
+	from = qb.MetaData()
+	from.AddTable(qb.Table("tags", qb.Column("name", qb.Varchar()).NotNull(), qb.Column("lang", qb.Varchar()).NotNull(), qb.PrimaryKey("name")))
+	to = qb.MetaData()
+	to.AddTable(qb.Table("tags", qb.Column("name", qb.Varchar()).NotNull(), qb.Column("lang", qb.Varchar()).NotNull(), qb.PrimaryKey("name", "lang")))
+	statements, err = qb.CompileSchemaChanges(dialect, qb.Diff(dialect, from, to))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{
+		"ALTER TABLE tags DROP PRIMARY KEY, ADD PRIMARY KEY(name, lang);",
+	}, statements)
+}
+
 func TestMysqlTestSuite(t *testing.T) {
 	suite.Run(t, new(MysqlTestSuite))
 }
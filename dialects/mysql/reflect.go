@@ -0,0 +1,195 @@
+package mysql
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/slicebit/qb"
+)
+
+// ReflectTableNames returns the names of the tables of the current database
+func (d *Dialect) ReflectTableNames(engine *qb.Engine) ([]string, error) {
+	var names []string
+	err := engine.DB().Select(&names, `SELECT TABLE_NAME
+FROM information_schema.TABLES
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'
+ORDER BY TABLE_NAME`)
+	return names, err
+}
+
+// reflectType converts an information_schema column type into a TypeElem
+func reflectType(columnType string) qb.TypeElem {
+	if strings.ToLower(columnType) == "tinyint(1)" {
+		return qb.Boolean()
+	}
+	t := qb.ParseType(columnType)
+	switch t.Name {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "BIGINT":
+		// the size is the display width, not a type constraint
+		reflected := qb.Type(t.Name)
+		if strings.HasSuffix(strings.ToLower(columnType), " unsigned") {
+			reflected = reflected.Unsigned()
+		}
+		return reflected
+	}
+	return t
+}
+
+// reflectAction converts an information_schema referential action
+func reflectAction(rule string) string {
+	if rule == "NO ACTION" || rule == "RESTRICT" {
+		// RESTRICT is the MySQL default, and is the same as NO ACTION
+		return ""
+	}
+	return rule
+}
+
+// ReflectTable builds a table definition from the information_schema
+// tables
+func (d *Dialect) ReflectTable(engine *qb.Engine, name string) (qb.TableElem, error) {
+	var columns []struct {
+		Name     string         `db:"COLUMN_NAME"`
+		Type     string         `db:"COLUMN_TYPE"`
+		Nullable string         `db:"IS_NULLABLE"`
+		Default  sql.NullString `db:"COLUMN_DEFAULT"`
+		Extra    string         `db:"EXTRA"`
+	}
+	err := engine.DB().Select(&columns, `SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, EXTRA
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?
+ORDER BY ORDINAL_POSITION`, name)
+	if err != nil || len(columns) == 0 {
+		return qb.TableElem{}, err
+	}
+
+	// the primary key, the unique constraints and the indices are all
+	// listed in the STATISTICS table
+	var indexRows []struct {
+		Name      string `db:"INDEX_NAME"`
+		Column    string `db:"COLUMN_NAME"`
+		NonUnique bool   `db:"NON_UNIQUE"`
+		IsFKey    bool   `db:"IS_FKEY"`
+	}
+	err = engine.DB().Select(&indexRows, `SELECT s.INDEX_NAME, s.COLUMN_NAME, s.NON_UNIQUE,
+	EXISTS(SELECT 1 FROM information_schema.TABLE_CONSTRAINTS tc
+		WHERE tc.TABLE_SCHEMA = s.TABLE_SCHEMA AND tc.TABLE_NAME = s.TABLE_NAME
+		AND tc.CONSTRAINT_NAME = s.INDEX_NAME AND tc.CONSTRAINT_TYPE = 'FOREIGN KEY') AS IS_FKEY
+FROM information_schema.STATISTICS s
+WHERE s.TABLE_SCHEMA = DATABASE() AND s.TABLE_NAME = ?
+ORDER BY s.INDEX_NAME, s.SEQ_IN_INDEX`, name)
+	if err != nil {
+		return qb.TableElem{}, err
+	}
+	var pkey []string
+	var indexNames []string
+	indexCols := map[string][]string{}
+	indexUnique := map[string]bool{}
+	for _, row := range indexRows {
+		if row.Name == "PRIMARY" {
+			pkey = append(pkey, row.Column)
+			continue
+		}
+		if row.IsFKey {
+			// implicitly created to back a foreign key
+			continue
+		}
+		if _, ok := indexCols[row.Name]; !ok {
+			indexNames = append(indexNames, row.Name)
+		}
+		indexCols[row.Name] = append(indexCols[row.Name], row.Column)
+		indexUnique[row.Name] = !row.NonUnique
+	}
+	isPkey := map[string]bool{}
+	for _, col := range pkey {
+		isPkey[col] = true
+	}
+	isUnique := map[string]bool{}
+	var uniqueKey *qb.UniqueKeyConstraint
+	var indices []qb.IndexElem
+	for _, indexName := range indexNames {
+		cols := indexCols[indexName]
+		switch {
+		case indexUnique[indexName] && len(cols) == 1:
+			isUnique[cols[0]] = true
+		case indexUnique[indexName] && uniqueKey == nil:
+			key := qb.UniqueKey(cols...).Name(indexName)
+			uniqueKey = &key
+		default:
+			indices = append(indices, qb.IndexElem{
+				Table:   name,
+				Name:    indexName,
+				Columns: cols,
+				Unique:  indexUnique[indexName],
+			})
+		}
+	}
+
+	var clauses []qb.TableSQLClause
+	for _, c := range columns {
+		col := qb.Column(c.Name, reflectType(c.Type))
+		if strings.Contains(c.Extra, "auto_increment") {
+			col = col.AutoIncrement()
+		}
+		if c.Nullable == "NO" && !isPkey[c.Name] {
+			col = col.NotNull()
+		}
+		if c.Default.Valid {
+			if strings.HasPrefix(strings.ToUpper(c.Default.String), "CURRENT_TIMESTAMP") {
+				col = col.Constraint("DEFAULT " + c.Default.String)
+			} else {
+				col = col.Default(c.Default.String)
+			}
+		}
+		if isUnique[c.Name] {
+			col = col.Unique()
+		}
+		clauses = append(clauses, col)
+	}
+	if len(pkey) != 0 {
+		clauses = append(clauses, qb.PrimaryKey(pkey...))
+	}
+	if uniqueKey != nil {
+		clauses = append(clauses, *uniqueKey)
+	}
+
+	var fkeyRows []struct {
+		Name      string `db:"CONSTRAINT_NAME"`
+		Column    string `db:"COLUMN_NAME"`
+		RefTable  string `db:"REFERENCED_TABLE_NAME"`
+		RefColumn string `db:"REFERENCED_COLUMN_NAME"`
+		OnUpdate  string `db:"UPDATE_RULE"`
+		OnDelete  string `db:"DELETE_RULE"`
+	}
+	err = engine.DB().Select(&fkeyRows, `SELECT kcu.CONSTRAINT_NAME, kcu.COLUMN_NAME,
+	kcu.REFERENCED_TABLE_NAME, kcu.REFERENCED_COLUMN_NAME, rc.UPDATE_RULE, rc.DELETE_RULE
+FROM information_schema.KEY_COLUMN_USAGE kcu
+JOIN information_schema.REFERENTIAL_CONSTRAINTS rc
+	ON rc.CONSTRAINT_SCHEMA = kcu.CONSTRAINT_SCHEMA AND rc.CONSTRAINT_NAME = kcu.CONSTRAINT_NAME
+WHERE kcu.TABLE_SCHEMA = DATABASE() AND kcu.TABLE_NAME = ?
+ORDER BY kcu.CONSTRAINT_NAME, kcu.ORDINAL_POSITION`, name)
+	if err != nil {
+		return qb.TableElem{}, err
+	}
+	var fkeys []qb.ForeignKeyConstraint
+	for i, row := range fkeyRows {
+		if i == 0 || row.Name != fkeyRows[i-1].Name {
+			fkeys = append(fkeys, qb.ForeignKeyConstraint{
+				Name:           row.Name,
+				RefTable:       row.RefTable,
+				ActionOnUpdate: reflectAction(row.OnUpdate),
+				ActionOnDelete: reflectAction(row.OnDelete),
+			})
+		}
+		fkey := &fkeys[len(fkeys)-1]
+		fkey.Cols = append(fkey.Cols, row.Column)
+		fkey.RefCols = append(fkey.RefCols, row.RefColumn)
+	}
+	for _, fkey := range fkeys {
+		clauses = append(clauses, fkey)
+	}
+	for _, index := range indices {
+		clauses = append(clauses, index)
+	}
+
+	return qb.Table(name, clauses...), nil
+}
@@ -0,0 +1,29 @@
+package postgres
+
+import (
+	"github.com/slicebit/qb"
+)
+
+// CompileSchemaChanges renders the schema changes. The primary keys are
+// dropped by the name PostgreSQL gives them, <table>_pkey
+func (d *Dialect) CompileSchemaChanges(changes []qb.SchemaChange) ([]string, error) {
+	var statements []string
+	for _, change := range changes {
+		if change.Kind != qb.ChangeAlterPrimaryKey {
+			sqls, err := qb.DefaultCompileSchemaChange(d, change)
+			if err != nil {
+				return nil, err
+			}
+			statements = append(statements, sqls...)
+			continue
+		}
+		alterTable := "ALTER TABLE " + d.Escape(change.Table.Name) + " "
+		if len(change.OldTable.PrimaryKeyConstraint.Columns) != 0 {
+			statements = append(statements, alterTable+"DROP CONSTRAINT "+d.Escape(change.Table.Name+"_pkey")+";")
+		}
+		if len(change.Table.PrimaryKeyConstraint.Columns) != 0 {
+			statements = append(statements, alterTable+"ADD "+change.Table.PrimaryKeyConstraint.String(d)+";")
+		}
+	}
+	return statements, nil
+}
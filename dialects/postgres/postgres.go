@@ -1,6 +1,8 @@
 package postgres
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -73,6 +75,17 @@ func (d *Dialect) AutoIncrement(column *qb.ColumnElem) string {
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *Dialect) SupportsUnsigned() bool { return false }
 
+// SupportsTransactionalDDL returns whether the DDL statements can be rolled back or not
+func (d *Dialect) SupportsTransactionalDDL() bool { return true }
+
+// SupportsReturning returns whether the insert statements can return the generated keys or not
+func (d *Dialect) SupportsReturning() bool { return true }
+
+// BatchLimits returns the maximum number of bind parameters of a statement
+func (d *Dialect) BatchLimits(engine *qb.Engine) (int, int, error) {
+	return 65535, 0, nil
+}
+
 // Driver returns the current driver of dialect
 func (d *Dialect) Driver() string {
 	return "postgres"
@@ -86,10 +99,34 @@ func (d *Dialect) GetCompiler() qb.Compiler {
 // WrapError wraps a native error in a qb Error
 func (d *Dialect) WrapError(err error) (qbErr qb.Error) {
 	qbErr.Orig = err
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		qbErr.Code = qb.ErrCanceled
+		return
+	}
 	pgErr, ok := err.(*pq.Error)
 	if !ok {
 		return
 	}
+	qbErr.Table = pgErr.Table
+	qbErr.Column = pgErr.Column
+	qbErr.Constraint = pgErr.Constraint
+	switch pgErr.Code {
+	case "57014": // query_canceled
+		qbErr.Code = qb.ErrCanceled
+		return
+	case "23505": // unique_violation
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrUniqueViolation
+		return
+	case "23503": // foreign_key_violation
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrForeignKeyViolation
+		return
+	case "23502": // not_null_violation
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrNotNullViolation
+		return
+	case "23514": // check_violation
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrCheckViolation
+		return
+	}
 	switch pgErr.Code.Class() {
 	case "0A": // Class 0A - Feature Not Supported
 		qbErr.Code = qb.ErrNotSupported
@@ -150,30 +187,46 @@ type PostgresCompiler struct {
 	qb.SQLCompiler
 }
 
-// VisitBind renders a bounded value
+// VisitBind renders a bounded value. All the occurrences of a named
+// parameter have the same placeholder
 func (PostgresCompiler) VisitBind(context *qb.CompilerContext, bind qb.BindClause) string {
+	param, isParam := bind.Value.(qb.ParamClause)
+	if n, ok := context.Params[param.Name]; isParam && ok {
+		return fmt.Sprintf("$%d", n)
+	}
 	context.Binds = append(context.Binds, bind.Value)
+	if isParam {
+		if context.Params == nil {
+			context.Params = map[string]int{}
+		}
+		context.Params[param.Name] = len(context.Binds)
+	}
 	return fmt.Sprintf("$%d", len(context.Binds))
 }
 
+// VisitILike compiles a case-insensitive LIKE with the ILIKE operator
+func (c PostgresCompiler) VisitILike(context *qb.CompilerContext, ilike qb.ILikeClause) string {
+	return c.VisitBinary(context, ilike.BinaryExpressionClause)
+}
+
 // VisitUpsert generates INSERT INTO ... VALUES ... ON CONFLICT(...) DO UPDATE SET ...
 func (PostgresCompiler) VisitUpsert(context *qb.CompilerContext, upsert qb.UpsertStmt) string {
 	var (
 		colNames []string
 		values   []string
 	)
-	for k, v := range upsert.ValuesMap {
-		colNames = append(colNames, context.Compiler.VisitLabel(context, k))
-		context.Binds = append(context.Binds, v)
+	for _, cv := range upsert.ValueSet {
+		colNames = append(colNames, context.Compiler.VisitLabel(context, cv.Column))
+		context.Binds = append(context.Binds, cv.Value)
 		values = append(values, fmt.Sprintf("$%d", len(context.Binds)))
 	}
 
 	var updates []string
-	for k, v := range upsert.ValuesMap {
-		context.Binds = append(context.Binds, v)
+	for _, cv := range upsert.ValueSet {
+		context.Binds = append(context.Binds, cv.Value)
 		updates = append(updates, fmt.Sprintf(
 			"%s = %s",
-			context.Dialect.Escape(k),
+			context.Dialect.Escape(cv.Column),
 			fmt.Sprintf("$%d", len(context.Binds)),
 		))
 	}
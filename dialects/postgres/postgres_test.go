@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -48,6 +49,12 @@ func (suite *PostgresTestSuite) TestUUID() {
 func (suite *PostgresTestSuite) TestDialectSimple() {
 	dialect := NewDialect()
 	assert.Equal(suite.T(), false, dialect.SupportsUnsigned())
+	assert.Equal(suite.T(), true, dialect.(qb.TransactionalDDL).SupportsTransactionalDDL())
+	assert.Equal(suite.T(), true, dialect.(qb.InsertReturning).SupportsReturning())
+	maxParams, maxBytes, err := dialect.(qb.BatchLimiter).BatchLimits(nil)
+	assert.Equal(suite.T(), 65535, maxParams)
+	assert.Equal(suite.T(), 0, maxBytes)
+	assert.Nil(suite.T(), err)
 	assert.Equal(suite.T(), "test", dialect.Escape("test"))
 	assert.Equal(suite.T(), false, dialect.Escaping())
 	assert.Equal(suite.T(), "postgres", dialect.Driver())
@@ -103,6 +110,7 @@ func (suite *PostgresTestSuite) TestWrapError() {
 		{"40000", qb.ErrOperational},
 		{"42000", qb.ErrProgramming},
 		{"54000", qb.ErrOperational},
+		{"57014", qb.ErrCanceled},
 		{"F0000", qb.ErrInternal},
 		{"HV000", qb.ErrOperational},
 		{"P0000", qb.ErrInternal},
@@ -112,6 +120,36 @@ func (suite *PostgresTestSuite) TestWrapError() {
 		qbErr := suite.engine.Dialect().WrapError(&pgErr)
 		assert.Equal(suite.T(), tt.qbCode, qbErr.Code)
 	}
+	for _, tt := range []struct {
+		pgCode string
+		detail qb.ErrorCode
+	}{
+		{"23505", qb.ErrUniqueViolation},
+		{"23503", qb.ErrForeignKeyViolation},
+		{"23502", qb.ErrNotNullViolation},
+		{"23514", qb.ErrCheckViolation},
+	} {
+		pgErr := pq.Error{Code: pq.ErrorCode(tt.pgCode)}
+		qbErr := suite.engine.Dialect().WrapError(&pgErr)
+		assert.Equal(suite.T(), qb.ErrIntegrity, qbErr.Code)
+		assert.Equal(suite.T(), tt.detail, qbErr.Detail)
+	}
+
+	qbErr = dialect.WrapError(&pq.Error{
+		Code:       "23505",
+		Table:      "users",
+		Column:     "email",
+		Constraint: "users_email_key",
+	})
+	assert.Equal(suite.T(), qb.ErrIntegrity, qbErr.Code)
+	assert.Equal(suite.T(), qb.ErrUniqueViolation, qbErr.Detail)
+	assert.Equal(suite.T(), "users", qbErr.Table)
+	assert.Equal(suite.T(), "email", qbErr.Column)
+	assert.Equal(suite.T(), "users_email_key", qbErr.Constraint)
+
+	assert.Equal(suite.T(), qb.ErrCanceled, dialect.WrapError(context.Canceled).Code)
+	assert.Equal(suite.T(), qb.ErrCanceled, dialect.WrapError(context.DeadlineExceeded).Code)
+	assert.Equal(suite.T(), qb.ErrCanceled, dialect.WrapError(fmt.Errorf("query: %w", context.Canceled)).Code)
 }
 
 func (suite *PostgresTestSuite) TestPostgres() {
@@ -370,6 +408,302 @@ func (suite *PostgresTestSuite) TestUpsert() {
 	assert.Equal(suite.T(), 4, len(binds))
 }
 
+func (suite *PostgresTestSuite) TestWith() {
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()),
+		qb.Column("email", qb.Varchar()),
+	)
+	active := qb.With(
+		"active_users",
+		qb.Select(users.C("id"), users.C("email")).
+			From(users).
+			Where(users.C("email").NotEq("")),
+	)
+	sel := qb.Select(active.C("email")).
+		From(active).
+		Where(active.C("id").Gt(10)).
+		With(active)
+
+	sql := sel.Accept(suite.ctx)
+	assert.Equal(suite.T(), "WITH active_users AS (SELECT users.id, users.email\nFROM users\nWHERE users.email != $1)\nSELECT email\nFROM active_users\nWHERE id > $2", sql)
+	assert.Equal(suite.T(), []interface{}{"", 10}, suite.ctx.Binds)
+}
+
+func (suite *PostgresTestSuite) TestUnion() {
+	users := qb.Table(
+		"users",
+		qb.Column("email", qb.Varchar()),
+	)
+	union := qb.Union(
+		qb.Select(users.C("email")).From(users).Where(users.C("email").Like("%@a.com")),
+		qb.Select(users.C("email")).From(users).Where(users.C("email").Like("%@b.com")),
+	).Limit(10)
+
+	sql := union.Accept(suite.ctx)
+	assert.Equal(suite.T(), "(SELECT email\nFROM users\nWHERE email LIKE $1)\nUNION\n(SELECT email\nFROM users\nWHERE email LIKE $2)\nLIMIT 10", sql)
+	assert.Equal(suite.T(), []interface{}{"%@a.com", "%@b.com"}, suite.ctx.Binds)
+}
+
+func (suite *PostgresTestSuite) TestReflectType() {
+	null := sql.NullInt64{}
+	assert.Equal(suite.T(), qb.Varchar(), reflectType("character varying", sql.NullInt64{Int64: 255, Valid: true}, null, null))
+	assert.Equal(suite.T(), qb.Int(), reflectType("integer", null, sql.NullInt64{Int64: 32, Valid: true}, sql.NullInt64{Valid: true}))
+	assert.Equal(suite.T(), qb.BigInt(), reflectType("bigint", null, null, null))
+	assert.Equal(suite.T(), qb.Numeric().Precision(10, 2), reflectType("numeric", null, sql.NullInt64{Int64: 10, Valid: true}, sql.NullInt64{Int64: 2, Valid: true}))
+	assert.Equal(suite.T(), qb.Timestamp(), reflectType("timestamp without time zone", null, null, null))
+	assert.Equal(suite.T(), qb.Blob(), reflectType("bytea", null, null, null))
+	assert.Equal(suite.T(), qb.UUID(), reflectType("uuid", null, null, null))
+}
+
+func (suite *PostgresTestSuite) TestReflect() {
+	fkey := qb.ForeignKey("user_id").References("reflect_users", "id").OnDelete("CASCADE")
+	fkey.Name = "fk_reflect_user"
+	users := qb.Table(
+		"reflect_users",
+		qb.Column("id", qb.BigInt()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()).NotNull().Unique(),
+		qb.Column("first_name", qb.Varchar().Size(40)),
+		qb.Column("last_name", qb.Varchar().Size(40)),
+		qb.UniqueKey("first_name", "last_name"),
+	).Index("last_name")
+	sessions := qb.Table(
+		"reflect_sessions",
+		qb.Column("user_id", qb.BigInt()),
+		qb.Column("token", qb.UUID()),
+		qb.PrimaryKey("user_id", "token"),
+		fkey,
+	)
+	suite.metadata.AddTable(users)
+	suite.metadata.AddTable(sessions)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+
+	metadata, err := qb.Reflect(suite.engine)
+	assert.Nil(suite.T(), err)
+
+	reflected := metadata.Table("reflect_users")
+	assert.Equal(suite.T(), []string{"id"}, reflected.PrimaryKeyConstraint.Columns)
+	assert.True(suite.T(), reflected.C("id").Options.AutoIncrement)
+	assert.Equal(suite.T(), qb.BigInt(), reflected.C("id").Type)
+	assert.Equal(suite.T(), "email VARCHAR(255) NOT NULL UNIQUE", reflected.C("email").String(suite.engine.Dialect()))
+	assert.Equal(suite.T(), "first_name VARCHAR(40)", reflected.C("first_name").String(suite.engine.Dialect()))
+	assert.Equal(suite.T(), qb.UniqueKey("first_name", "last_name").Table("reflect_users"), reflected.UniqueKeyConstraint)
+	assert.Equal(suite.T(), []qb.IndexElem{
+		{Table: "reflect_users", Name: "i_last_name", Columns: []string{"last_name"}},
+	}, reflected.Indices)
+
+	reflected = metadata.Table("reflect_sessions")
+	assert.Equal(suite.T(), []string{"user_id", "token"}, reflected.PrimaryKeyConstraint.Columns)
+	assert.Equal(suite.T(), qb.UUID(), reflected.C("token").Type)
+	assert.Equal(suite.T(), []qb.ForeignKeyConstraint{fkey}, reflected.ForeignKeyConstraints.FKeys)
+}
+
+func (suite *PostgresTestSuite) TestPrepare() {
+	dialect := NewDialect()
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+	)
+
+	stmt := qb.Prepare(
+		qb.Select(users.C("email")).
+			From(users).
+			Where(qb.And(qb.Like(users.C("email"), qb.Param("email")), users.C("id").Gt(10), users.C("id").Lt(qb.Param("max")))),
+		dialect,
+	)
+	bound := stmt.Bind(map[string]interface{}{"email": "%@pacino.com", "max": 20})
+	assert.Equal(suite.T(), "SELECT email\nFROM users\nWHERE (email LIKE $1 AND id > $2 AND id < $3);", bound.SQL())
+	assert.Equal(suite.T(), []interface{}{"%@pacino.com", 10, 20}, bound.Bindings())
+
+	// a named parameter used twice has a single placeholder
+	stmt = qb.Prepare(
+		qb.Select(users.C("email")).
+			From(users).
+			Where(qb.Or(users.C("id").Eq(qb.Param("id")), users.C("id").Gt(5), users.C("id").Lt(qb.Param("id")))),
+		dialect,
+	)
+	bound = stmt.Bind(map[string]interface{}{"id": 3})
+	assert.Equal(suite.T(), "SELECT email\nFROM users\nWHERE (id = $1 OR id > $2 OR id < $1);", bound.SQL())
+	assert.Equal(suite.T(), []interface{}{3, 5}, bound.Bindings())
+}
+
+func (suite *PostgresTestSuite) TestInsertFromSelect() {
+	dialect := NewDialect()
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()),
+	)
+
+	sel := qb.Select(users.C("email")).From(users).Where(users.C("id").Lt(10))
+	stmt := qb.Insert(users).
+		Columns(users.C("email")).
+		FromSelect(sel).
+		Returning(users.C("id")).
+		Build(dialect)
+	assert.Equal(suite.T(), "INSERT INTO users(email)\nSELECT email\nFROM users\nWHERE id < $1\nRETURNING id;", stmt.SQL())
+	assert.Equal(suite.T(), []interface{}{10}, stmt.Bindings())
+
+	stmt = qb.Insert(users).DefaultValues().Returning(users.C("id")).Build(dialect)
+	assert.Equal(suite.T(), "INSERT INTO users DEFAULT VALUES\nRETURNING id;", stmt.SQL())
+}
+
+func (suite *PostgresTestSuite) TestAlterTable() {
+	dialect := NewDialect()
+	table := qb.Table("users", qb.Column("id", qb.Int()))
+
+	sql := qb.AlterTable(table).
+		AddColumn(qb.Column("email", qb.Varchar()).NotNull()).
+		AlterColumnType("id", qb.BigInt()).
+		DropConstraint("u_email").
+		Build(dialect).SQL()
+	assert.Equal(suite.T(), "ALTER TABLE users ADD COLUMN email VARCHAR(255) NOT NULL, "+
+		"ALTER COLUMN id TYPE BIGINT, DROP CONSTRAINT u_email;", sql)
+
+	stmt := qb.AlterTable(table).RenameColumn("id", "user_id").RenameTo("members").Build(dialect)
+	assert.Equal(suite.T(), qb.ErrNotSupported, stmt.Err().(qb.Error).Code)
+}
+
+func (suite *PostgresTestSuite) TestSchemaChanges() {
+	dialect := NewDialect()
+	from := qb.MetaData()
+	from.AddTable(qb.Table(
+		"users",
+		qb.Column("id", qb.BigInt()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()).Constraint("DEFAULT ''::character varying"),
+	))
+	to := qb.MetaData()
+	to.AddTable(qb.Table(
+		"users",
+		qb.Column("id", qb.BigInt()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Text()).Default(""),
+		qb.Column("data", qb.Blob()),
+	))
+
+	statements, err := qb.CompileSchemaChanges(dialect, qb.Diff(dialect, from, to))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{
+		"ALTER TABLE users ADD COLUMN data bytea;",
+		"ALTER TABLE users ALTER COLUMN email TYPE TEXT;",
+	}, statements)
+
+	from = qb.MetaData()
+	from.AddTable(qb.Table("tags", qb.Column("name", qb.Varchar()).NotNull(), qb.Column("lang", qb.Varchar()).NotNull(), qb.PrimaryKey("name")))
+	to = qb.MetaData()
+	to.AddTable(qb.Table("tags", qb.Column("name", qb.Varchar()).NotNull(), qb.Column("lang", qb.Varchar()).NotNull(), qb.PrimaryKey("name", "lang")))
+	statements, err = qb.CompileSchemaChanges(dialect, qb.Diff(dialect, from, to))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{
+		"ALTER TABLE tags DROP CONSTRAINT tags_pkey;",
+		"ALTER TABLE tags ADD PRIMARY KEY(name, lang);",
+	}, statements)
+}
+
+func (suite *PostgresTestSuite) TestFunctions() {
+	dialect := NewDialect()
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("first_name", qb.Varchar()),
+		qb.Column("last_name", qb.Varchar()),
+		qb.Column("avatar", qb.Blob()),
+	)
+	sel := qb.Select(
+		users.C("first_name").Concat(" ", users.C("last_name")),
+		qb.Length(users.C("last_name")),
+		qb.Now(),
+		qb.Cast(users.C("avatar"), qb.Blob()),
+		users.C("id").Mul(2).Add(1),
+	).From(users)
+	statement := sel.Build(dialect)
+	assert.Equal(suite.T(), "SELECT "+
+		"first_name || $1 || last_name, "+
+		"CHAR_LENGTH(last_name), "+
+		"CURRENT_TIMESTAMP, "+
+		"CAST(avatar AS bytea), "+
+		"(id * $2) + $3\n"+
+		"FROM users;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{" ", 2, 1}, statement.Bindings())
+}
+
+func (suite *PostgresTestSuite) TestCase() {
+	dialect := NewDialect()
+	orders := qb.Table(
+		"orders",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("amount", qb.Int()),
+	)
+	sel := qb.Select(
+		qb.Case().When(orders.C("amount").Lt(100), "small").Else("large"),
+	).From(orders)
+	statement := sel.Build(dialect)
+	assert.Equal(suite.T(), "SELECT CASE WHEN amount < $1 THEN $2 ELSE $3 END\nFROM orders;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{100, "small", "large"}, statement.Bindings())
+}
+
+func (suite *PostgresTestSuite) TestCaseGroupBy() {
+	dialect := NewDialect()
+	orders := qb.Table(
+		"case_orders",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("amount", qb.Int()),
+	)
+	suite.metadata.AddTable(orders)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+	_, err := suite.engine.Exec(orders.Insert().ValuesList([]map[string]interface{}{
+		{"id": 1, "amount": 50},
+		{"id": 2, "amount": 500},
+		{"id": 3, "amount": 80},
+	}))
+	assert.Nil(suite.T(), err)
+
+	bucket := qb.Case().When(orders.C("amount").Lt(100), "small").Else("large")
+	sel := qb.Select(bucket, qb.Count(orders.C("id"))).From(orders).GroupBy(bucket).OrderBy(bucket)
+	statement := sel.Build(dialect)
+	assert.Equal(suite.T(), "SELECT CASE WHEN amount < $1 THEN $2 ELSE $3 END, COUNT(id)\n"+
+		"FROM case_orders\n"+
+		"GROUP BY 1\n"+
+		"ORDER BY 1 ASC;", statement.SQL())
+	rows, err := suite.engine.Query(sel)
+	assert.Nil(suite.T(), err)
+	defer rows.Close()
+	var results []string
+	for rows.Next() {
+		var name string
+		var count int64
+		assert.Nil(suite.T(), rows.Scan(&name, &count))
+		results = append(results, fmt.Sprintf("%s:%d", name, count))
+	}
+	assert.Equal(suite.T(), []string{"large:1", "small:2"}, results)
+}
+
+func (suite *PostgresTestSuite) TestILike() {
+	dialect := NewDialect()
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+	)
+	sel := qb.Select(users.C("id")).From(users).Where(users.C("email").ILike("%@EXAMPLE.COM"))
+	statement := sel.Build(dialect)
+	assert.Equal(suite.T(), "SELECT id\nFROM users\nWHERE email ILIKE $1;", statement.SQL())
+	assert.Equal(suite.T(), []interface{}{"%@EXAMPLE.COM"}, statement.Bindings())
+}
+
+func (suite *PostgresTestSuite) TestOrderByNulls() {
+	dialect := NewDialect()
+	users := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("last_login", qb.Timestamp()).Null(),
+	)
+	sel := qb.Select(users.C("id")).From(users).OrderBy(qb.Desc(users.C("last_login")).NullsLast(), users.C("id"))
+	assert.Equal(suite.T(), "SELECT id\nFROM users\nORDER BY last_login DESC NULLS LAST, id ASC;", sel.Build(dialect).SQL())
+}
+
 func TestPostgresTestSuite(t *testing.T) {
 	suite.Run(t, new(PostgresTestSuite))
 }
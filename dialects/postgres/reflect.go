@@ -0,0 +1,225 @@
+package postgres
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/slicebit/qb"
+)
+
+// ReflectTableNames returns the names of the tables of the current schema
+func (d *Dialect) ReflectTableNames(engine *qb.Engine) ([]string, error) {
+	var names []string
+	err := engine.DB().Select(&names, `SELECT table_name
+FROM information_schema.tables
+WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+ORDER BY table_name`)
+	return names, err
+}
+
+// reflectType converts an information_schema column type into a TypeElem
+func reflectType(dataType string, size, precision, scale sql.NullInt64) qb.TypeElem {
+	var t qb.TypeElem
+	switch dataType {
+	case "character varying":
+		t = qb.Type("VARCHAR")
+	case "character":
+		t = qb.Char()
+	case "integer":
+		t = qb.Int()
+	case "double precision":
+		t = qb.Float()
+	case "timestamp without time zone":
+		t = qb.Timestamp()
+	case "timestamp with time zone":
+		t = qb.Type("TIMESTAMPTZ")
+	case "bytea":
+		t = qb.Blob()
+	default:
+		t = qb.Type(strings.ToUpper(dataType))
+	}
+	if size.Valid {
+		t = t.Size(int(size.Int64))
+	} else if dataType == "numeric" && precision.Valid {
+		t = t.Precision(int(precision.Int64), int(scale.Int64))
+	}
+	return t
+}
+
+// reflectAction converts an information_schema referential action
+func reflectAction(rule string) string {
+	if rule == "NO ACTION" {
+		return ""
+	}
+	return rule
+}
+
+// ReflectTable builds a table definition from the information_schema and
+// pg_catalog tables
+func (d *Dialect) ReflectTable(engine *qb.Engine, name string) (qb.TableElem, error) {
+	var columns []struct {
+		Name      string         `db:"column_name"`
+		DataType  string         `db:"data_type"`
+		Size      sql.NullInt64  `db:"character_maximum_length"`
+		Precision sql.NullInt64  `db:"numeric_precision"`
+		Scale     sql.NullInt64  `db:"numeric_scale"`
+		Nullable  string         `db:"is_nullable"`
+		Default   sql.NullString `db:"column_default"`
+	}
+	err := engine.DB().Select(&columns, `SELECT column_name, data_type, character_maximum_length,
+	numeric_precision, numeric_scale, is_nullable, column_default
+FROM information_schema.columns
+WHERE table_schema = current_schema() AND table_name = $1
+ORDER BY ordinal_position`, name)
+	if err != nil || len(columns) == 0 {
+		return qb.TableElem{}, err
+	}
+
+	var constraints []struct {
+		Name   string `db:"constraint_name"`
+		Type   string `db:"constraint_type"`
+		Column string `db:"column_name"`
+	}
+	err = engine.DB().Select(&constraints, `SELECT tc.constraint_name, tc.constraint_type, kcu.column_name
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+	ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+WHERE tc.table_schema = current_schema() AND tc.table_name = $1
+	AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE')
+ORDER BY tc.constraint_name, kcu.ordinal_position`, name)
+	if err != nil {
+		return qb.TableElem{}, err
+	}
+	var pkey []string
+	var uniqueNames []string
+	uniqueCols := map[string][]string{}
+	for _, c := range constraints {
+		if c.Type == "PRIMARY KEY" {
+			pkey = append(pkey, c.Column)
+			continue
+		}
+		if _, ok := uniqueCols[c.Name]; !ok {
+			uniqueNames = append(uniqueNames, c.Name)
+		}
+		uniqueCols[c.Name] = append(uniqueCols[c.Name], c.Column)
+	}
+	isPkey := map[string]bool{}
+	for _, col := range pkey {
+		isPkey[col] = true
+	}
+	isUnique := map[string]bool{}
+	var uniqueKey *qb.UniqueKeyConstraint
+	var indices []qb.IndexElem
+	for _, constraintName := range uniqueNames {
+		cols := uniqueCols[constraintName]
+		if len(cols) == 1 {
+			isUnique[cols[0]] = true
+		} else if uniqueKey == nil {
+			key := qb.UniqueKey(cols...).Name(constraintName)
+			uniqueKey = &key
+		} else {
+			// a TableElem holds a single UniqueKeyConstraint
+			indices = append(indices, qb.IndexElem{
+				Table: name, Name: constraintName, Columns: cols, Unique: true,
+			})
+		}
+	}
+
+	var clauses []qb.TableSQLClause
+	for _, c := range columns {
+		col := qb.Column(c.Name, reflectType(c.DataType, c.Size, c.Precision, c.Scale))
+		if c.Default.Valid && strings.HasPrefix(c.Default.String, "nextval(") {
+			col = col.AutoIncrement()
+		} else if c.Default.Valid {
+			col = col.Constraint("DEFAULT " + c.Default.String)
+		}
+		if c.Nullable == "NO" && !isPkey[c.Name] {
+			col = col.NotNull()
+		}
+		if isUnique[c.Name] {
+			col = col.Unique()
+		}
+		clauses = append(clauses, col)
+	}
+	if len(pkey) != 0 {
+		clauses = append(clauses, qb.PrimaryKey(pkey...))
+	}
+	if uniqueKey != nil {
+		clauses = append(clauses, *uniqueKey)
+	}
+
+	var fkeyRows []struct {
+		Name      string `db:"constraint_name"`
+		Column    string `db:"column_name"`
+		RefTable  string `db:"ref_table_name"`
+		RefColumn string `db:"ref_column_name"`
+		OnUpdate  string `db:"update_rule"`
+		OnDelete  string `db:"delete_rule"`
+	}
+	err = engine.DB().Select(&fkeyRows, `SELECT kcu.constraint_name, kcu.column_name,
+	ref.table_name AS ref_table_name, ref.column_name AS ref_column_name,
+	rc.update_rule, rc.delete_rule
+FROM information_schema.referential_constraints rc
+JOIN information_schema.key_column_usage kcu
+	ON kcu.constraint_schema = rc.constraint_schema AND kcu.constraint_name = rc.constraint_name
+JOIN information_schema.key_column_usage ref
+	ON ref.constraint_schema = rc.unique_constraint_schema
+	AND ref.constraint_name = rc.unique_constraint_name
+	AND ref.ordinal_position = kcu.position_in_unique_constraint
+WHERE kcu.table_schema = current_schema() AND kcu.table_name = $1
+ORDER BY kcu.constraint_name, kcu.ordinal_position`, name)
+	if err != nil {
+		return qb.TableElem{}, err
+	}
+	var fkeys []qb.ForeignKeyConstraint
+	for i, row := range fkeyRows {
+		if i == 0 || row.Name != fkeyRows[i-1].Name {
+			fkeys = append(fkeys, qb.ForeignKeyConstraint{
+				Name:           row.Name,
+				RefTable:       row.RefTable,
+				ActionOnUpdate: reflectAction(row.OnUpdate),
+				ActionOnDelete: reflectAction(row.OnDelete),
+			})
+		}
+		fkey := &fkeys[len(fkeys)-1]
+		fkey.Cols = append(fkey.Cols, row.Column)
+		fkey.RefCols = append(fkey.RefCols, row.RefColumn)
+	}
+	for _, fkey := range fkeys {
+		clauses = append(clauses, fkey)
+	}
+
+	// the indices backing the constraints are skipped
+	var indexRows []struct {
+		Name   string `db:"index_name"`
+		Column string `db:"column_name"`
+		Unique bool   `db:"indisunique"`
+	}
+	err = engine.DB().Select(&indexRows, `SELECT i.relname AS index_name, a.attname AS column_name, ix.indisunique
+FROM pg_catalog.pg_index ix
+JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+JOIN LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord) ON true
+JOIN pg_catalog.pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+WHERE n.nspname = current_schema() AND t.relname = $1
+	AND NOT EXISTS (SELECT 1 FROM pg_catalog.pg_constraint c WHERE c.conindid = ix.indexrelid)
+ORDER BY i.relname, k.ord`, name)
+	if err != nil {
+		return qb.TableElem{}, err
+	}
+	for i, row := range indexRows {
+		if i == 0 || row.Name != indexRows[i-1].Name {
+			indices = append(indices, qb.IndexElem{
+				Table: name, Name: row.Name, Unique: row.Unique,
+			})
+		}
+		index := &indices[len(indices)-1]
+		index.Columns = append(index.Columns, row.Column)
+	}
+	for _, index := range indices {
+		clauses = append(clauses, index)
+	}
+
+	return qb.Table(name, clauses...), nil
+}
@@ -0,0 +1,39 @@
+package sqlite
+
+import (
+	"fmt"
+
+	"github.com/slicebit/qb"
+)
+
+// CompileAlterTable renders an alter table statement. The sqlite
+// ALTER TABLE has a single action, which can only add, drop or rename a
+// column, or rename the table. Dropping a column requires sqlite 3.35.
+func (d *Dialect) CompileAlterTable(stmt qb.AlterTableStmt) (string, error) {
+	notSupported := func(action qb.AlterTableAction, reason string) error {
+		return qb.Error{
+			Code:   qb.ErrNotSupported,
+			Orig:   fmt.Errorf("Cannot alter table %s with the sqlite3 dialect: %s", stmt.Table.Name, reason),
+			Table:  stmt.Table.Name,
+			Column: action.Column.Name,
+		}
+	}
+	if len(stmt.Actions) != 1 {
+		return "", notSupported(qb.AlterTableAction{}, "a single action is allowed")
+	}
+	action := stmt.Actions[0]
+	switch action.Kind {
+	case qb.AlterAddColumn:
+		if !nativeSchemaChange(qb.SchemaChange{Kind: qb.ChangeAddColumn, Column: action.Column}) {
+			return "", notSupported(action, "cannot add a primary key, unique, or not null column without default")
+		}
+	case qb.AlterDropColumn, qb.AlterRenameColumn, qb.AlterRenameTo:
+	case qb.AlterColumnType, qb.AlterSetDefault, qb.AlterDropDefault, qb.AlterSetNotNull:
+		return "", notSupported(action, "cannot alter a column")
+	case qb.AlterAddConstraint:
+		return "", notSupported(action, "cannot add a constraint")
+	case qb.AlterDropConstraint:
+		return "", notSupported(action, "cannot drop a constraint")
+	}
+	return qb.DefaultCompileAlterTable(d, stmt)
+}
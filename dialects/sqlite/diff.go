@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/slicebit/qb"
+)
+
+// nativeSchemaChange returns true if sqlite can apply the change without
+// rebuilding the table
+func nativeSchemaChange(change qb.SchemaChange) bool {
+	switch change.Kind {
+	case qb.ChangeCreateTable,
+		qb.ChangeDropTable,
+		qb.ChangeAddIndex,
+		qb.ChangeDropIndex:
+		return true
+	case qb.ChangeAddColumn:
+		col := change.Column
+		_, hasDefault := qb.ColumnDefault(col)
+		return !col.Options.PrimaryKey &&
+			!col.Options.AutoIncrement &&
+			!col.Options.Unique &&
+			(hasDefault || !qb.ColumnNotNull(col))
+	default:
+		return false
+	}
+}
+
+// CompileSchemaChanges renders the schema changes.
+// As the sqlite ALTER TABLE can only add columns, the tables having other
+// changes are rebuilt: a table is created with the new definition and
+// filled with the data of the old table, which is then dropped.
+// The rebuild of a referenced table breaks the foreign keys, whose
+// enforcement must be disabled while the changes are applied. The migrator
+// does it as the dialect is a qb.ForeignKeysSwitch. Otherwise, the
+// 'PRAGMA foreign_keys = OFF' must run outside the transaction of the
+// changes, as sqlite ignores it in a transaction.
+func (d *Dialect) CompileSchemaChanges(changes []qb.SchemaChange) ([]string, error) {
+	rebuilt := map[string]bool{}
+	for _, change := range changes {
+		if !nativeSchemaChange(change) {
+			rebuilt[change.Table.Name] = true
+		}
+	}
+
+	var statements []string
+	done := map[string]bool{}
+	for _, change := range changes {
+		name := change.Table.Name
+		if change.Kind != qb.ChangeCreateTable && change.Kind != qb.ChangeDropTable && rebuilt[name] {
+			if !done[name] {
+				sqls, err := d.rebuildTable(change.OldTable, change.Table)
+				if err != nil {
+					return nil, err
+				}
+				statements = append(statements, sqls...)
+				done[name] = true
+			}
+			continue
+		}
+		sqls, err := qb.DefaultCompileSchemaChange(d, change)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, sqls...)
+	}
+	return statements, nil
+}
+
+// rebuildTable returns the statements that rebuild a table with a new
+// definition, keeping the data of the columns that are in both definitions
+func (d *Dialect) rebuildTable(oldTable qb.TableElem, table qb.TableElem) ([]string, error) {
+	if err := table.Err(d); err != nil {
+		return nil, err
+	}
+	tmpTable := table
+	tmpTable.Name = "qb_tmp_" + table.Name
+	tmpTable.Indices = nil
+
+	var cols []string
+	for _, col := range table.Columns {
+		if _, ok := oldTable.Lookup(col.Name); ok {
+			cols = append(cols, d.Escape(col.Name))
+		}
+	}
+
+	statements := []string{
+		tmpTable.Create(d),
+		fmt.Sprintf(
+			"INSERT INTO %s(%s) SELECT %s FROM %s;",
+			d.Escape(tmpTable.Name),
+			strings.Join(cols, ", "),
+			strings.Join(cols, ", "),
+			d.Escape(oldTable.Name),
+		),
+		oldTable.Drop(d),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", d.Escape(tmpTable.Name), d.Escape(table.Name)),
+	}
+	for _, index := range table.Indices {
+		statements = append(statements, index.String(d))
+	}
+	return statements, nil
+}
+
+// SwitchForeignKeys enables or disables the foreign keys enforcement on a
+// connection, which must not be in a transaction
+func (d *Dialect) SwitchForeignKeys(conn *sql.Conn, on bool) (bool, error) {
+	ctx := context.Background()
+	var enabled bool
+	if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&enabled); err != nil {
+		return false, err
+	}
+	pragma := "PRAGMA foreign_keys = OFF"
+	if on {
+		pragma = "PRAGMA foreign_keys = ON"
+	}
+	if _, err := conn.ExecContext(ctx, pragma); err != nil {
+		return false, err
+	}
+	return enabled, nil
+}
+
+// CheckForeignKeys returns an ErrIntegrity Error detailed by
+// ErrForeignKeyViolation if a row violates a foreign key
+func (d *Dialect) CheckForeignKeys(tx *qb.Tx) error {
+	var violations []struct {
+		Table  string        `db:"table"`
+		RowID  sql.NullInt64 `db:"rowid"`
+		Parent string        `db:"parent"`
+		FKID   int           `db:"fkid"`
+	}
+	if err := tx.Tx().Select(&violations, "PRAGMA foreign_key_check"); err != nil {
+		return d.WrapError(err)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return qb.Error{
+		Code:   qb.ErrIntegrity,
+		Detail: qb.ErrForeignKeyViolation,
+		Orig:   fmt.Errorf("%d rows of %s violate their foreign key to %s", len(violations), violations[0].Table, violations[0].Parent),
+		Table:  violations[0].Table,
+	}
+}
@@ -0,0 +1,165 @@
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/slicebit/qb"
+)
+
+// quoteIdentifier quotes a table or index name for the PRAGMA statements,
+// which do not accept bound parameters
+func quoteIdentifier(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+// ReflectTableNames returns the names of the tables of the database
+func (d *Dialect) ReflectTableNames(engine *qb.Engine) ([]string, error) {
+	var names []string
+	err := engine.DB().Select(
+		&names,
+		"SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name",
+	)
+	return names, err
+}
+
+// ReflectTable builds a table definition from the sqlite_master and
+// PRAGMA informations
+func (d *Dialect) ReflectTable(engine *qb.Engine, name string) (qb.TableElem, error) {
+	var columns []struct {
+		CID          int     `db:"cid"`
+		Name         string  `db:"name"`
+		Type         string  `db:"type"`
+		NotNull      bool    `db:"notnull"`
+		DefaultValue *string `db:"dflt_value"`
+		PK           int     `db:"pk"`
+	}
+	if err := engine.DB().Select(&columns, "PRAGMA table_info("+quoteIdentifier(name)+")"); err != nil {
+		return qb.TableElem{}, err
+	}
+	if len(columns) == 0 {
+		return qb.TableElem{}, nil
+	}
+
+	pkeyCount := 0
+	for _, c := range columns {
+		if c.PK != 0 {
+			pkeyCount++
+		}
+	}
+	pkey := make([]string, pkeyCount)
+
+	var clauses []qb.TableSQLClause
+	colIndex := map[string]int{}
+	for _, c := range columns {
+		col := qb.Column(c.Name, qb.ParseType(c.Type))
+		if c.PK != 0 {
+			pkey[c.PK-1] = c.Name
+			if pkeyCount == 1 && strings.ToUpper(c.Type) == "INTEGER" {
+				// an INTEGER PRIMARY KEY is an alias for the rowid
+				col = qb.Column(c.Name, qb.Int()).AutoIncrement()
+			}
+		} else if c.NotNull {
+			col = col.NotNull()
+		}
+		if c.DefaultValue != nil {
+			col = col.Constraint("DEFAULT " + *c.DefaultValue)
+		}
+		colIndex[c.Name] = len(clauses)
+		clauses = append(clauses, col)
+	}
+	if pkeyCount != 0 {
+		clauses = append(clauses, qb.PrimaryKey(pkey...))
+	}
+
+	var fkeyRows []struct {
+		ID       int    `db:"id"`
+		Seq      int    `db:"seq"`
+		Table    string `db:"table"`
+		From     string `db:"from"`
+		To       string `db:"to"`
+		OnUpdate string `db:"on_update"`
+		OnDelete string `db:"on_delete"`
+		Match    string `db:"match"`
+	}
+	if err := engine.DB().Select(&fkeyRows, "PRAGMA foreign_key_list("+quoteIdentifier(name)+")"); err != nil {
+		return qb.TableElem{}, err
+	}
+	var fkeys []qb.ForeignKeyConstraint
+	for i, row := range fkeyRows {
+		if i == 0 || row.ID != fkeyRows[i-1].ID {
+			fkeys = append(fkeys, qb.ForeignKeyConstraint{RefTable: row.Table})
+			fkey := &fkeys[len(fkeys)-1]
+			if row.OnUpdate != "NO ACTION" {
+				fkey.ActionOnUpdate = row.OnUpdate
+			}
+			if row.OnDelete != "NO ACTION" {
+				fkey.ActionOnDelete = row.OnDelete
+			}
+		}
+		fkey := &fkeys[len(fkeys)-1]
+		fkey.Cols = append(fkey.Cols, row.From)
+		fkey.RefCols = append(fkey.RefCols, row.To)
+	}
+	for _, fkey := range fkeys {
+		clauses = append(clauses, fkey)
+	}
+
+	var indexRows []struct {
+		Seq     int    `db:"seq"`
+		Name    string `db:"name"`
+		Unique  bool   `db:"unique"`
+		Origin  string `db:"origin"`
+		Partial bool   `db:"partial"`
+	}
+	if err := engine.DB().Select(&indexRows, "PRAGMA index_list("+quoteIdentifier(name)+")"); err != nil {
+		return qb.TableElem{}, err
+	}
+	// PRAGMA index_list lists the most recent indices first
+	for i := len(indexRows) - 1; i >= 0; i-- {
+		index := indexRows[i]
+		if index.Origin == "pk" {
+			continue
+		}
+		var indexColumns []struct {
+			SeqNo int    `db:"seqno"`
+			CID   int    `db:"cid"`
+			Name  string `db:"name"`
+		}
+		err := engine.DB().Select(&indexColumns, "PRAGMA index_info("+quoteIdentifier(index.Name)+")")
+		if err != nil {
+			return qb.TableElem{}, err
+		}
+		var cols []string
+		for _, c := range indexColumns {
+			cols = append(cols, c.Name)
+		}
+		switch {
+		case index.Origin == "u" && len(cols) == 1:
+			col := clauses[colIndex[cols[0]]].(qb.ColumnElem)
+			clauses[colIndex[cols[0]]] = col.Unique()
+		case index.Origin == "u" && !hasUniqueKey(clauses):
+			// the constraint name is not kept by sqlite, use the default one
+			clauses = append(clauses, qb.UniqueKey(cols...))
+		default:
+			clauses = append(clauses, qb.IndexElem{
+				Table:   name,
+				Name:    index.Name,
+				Columns: cols,
+				Unique:  index.Unique,
+			})
+		}
+	}
+
+	return qb.Table(name, clauses...), nil
+}
+
+// hasUniqueKey returns true if a UniqueKeyConstraint is in the clauses. As
+// TableElem can hold only one, the others are reflected as unique indices.
+func hasUniqueKey(clauses []qb.TableSQLClause) bool {
+	for _, clause := range clauses {
+		if _, ok := clause.(qb.UniqueKeyConstraint); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,8 @@
 package sqlite
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -54,17 +56,46 @@ func (d *Dialect) Escaping() bool {
 	return d.escaping
 }
 
-// AutoIncrement generates auto increment sql of current dialect
+// AutoIncrement generates auto increment sql of current dialect. The tables
+// having an autoincrement column that is not their primary key fail to
+// build, see CheckColumn
 func (d *Dialect) AutoIncrement(column *qb.ColumnElem) string {
-	if !column.Options.InlinePrimaryKey {
-		panic("Sqlite does not support non-primarykey autoincrement columns")
-	}
 	return "INTEGER PRIMARY KEY"
 }
 
+// CheckColumn implements qb.ColumnChecker: sqlite only supports the
+// autoincrement columns that are the primary key of their table
+func (d *Dialect) CheckColumn(column qb.ColumnElem) error {
+	if column.Options.AutoIncrement && !column.Options.InlinePrimaryKey {
+		return qb.Error{
+			Code: qb.ErrNotSupported,
+			Orig: fmt.Errorf("Sqlite does not support non-primarykey autoincrement columns (%s)", column.Name),
+		}
+	}
+	return nil
+}
+
 // SupportsUnsigned returns whether driver supports unsigned type mappings or not
 func (d *Dialect) SupportsUnsigned() bool { return false }
 
+// SupportsTransactionalDDL returns whether the DDL statements can be rolled back or not
+func (d *Dialect) SupportsTransactionalDDL() bool { return true }
+
+// BatchLimits returns the maximum number of bind parameters of a statement,
+// which is 999 before sqlite 3.32.0 and 32766 after
+func (d *Dialect) BatchLimits(engine *qb.Engine) (int, int, error) {
+	var version string
+	if err := engine.DB().Get(&version, "SELECT sqlite_version()"); err != nil {
+		return 0, 0, err
+	}
+	var major, minor int
+	fmt.Sscanf(version, "%d.%d", &major, &minor)
+	if major > 3 || (major == 3 && minor >= 32) {
+		return 32766, 0, nil
+	}
+	return 999, 0, nil
+}
+
 // Driver returns the current driver of dialect
 func (d *Dialect) Driver() string {
 	return "sqlite3"
@@ -78,11 +109,18 @@ func (d *Dialect) GetCompiler() qb.Compiler {
 // WrapError wraps a native error in a qb Error
 func (d *Dialect) WrapError(err error) qb.Error {
 	qbErr := qb.Error{Orig: err}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		qbErr.Code = qb.ErrCanceled
+		return qbErr
+	}
 	sErr, ok := err.(sqlite3.Error)
 	if !ok {
 		return qbErr
 	}
 	switch sErr.Code {
+	case sqlite3.ErrInterrupt:
+		// the driver interrupts the queries when their context is done
+		qbErr.Code = qb.ErrCanceled
 	case sqlite3.ErrInternal,
 		sqlite3.ErrNotFound,
 		sqlite3.ErrNomem:
@@ -93,7 +131,6 @@ func (d *Dialect) WrapError(err error) qb.Error {
 		sqlite3.ErrBusy,
 		sqlite3.ErrLocked,
 		sqlite3.ErrReadonly,
-		sqlite3.ErrInterrupt,
 		sqlite3.ErrIoErr,
 		sqlite3.ErrFull,
 		sqlite3.ErrCantOpen,
@@ -105,8 +142,9 @@ func (d *Dialect) WrapError(err error) qb.Error {
 		qbErr.Code = qb.ErrDatabase
 	case sqlite3.ErrTooBig:
 		qbErr.Code = qb.ErrData
-	case sqlite3.ErrConstraint,
-		sqlite3.ErrMismatch:
+	case sqlite3.ErrConstraint:
+		wrapConstraintError(sErr, &qbErr)
+	case sqlite3.ErrMismatch:
 		qbErr.Code = qb.ErrIntegrity
 	case sqlite3.ErrMisuse:
 		qbErr.Code = qb.ErrProgramming
@@ -116,20 +154,100 @@ func (d *Dialect) WrapError(err error) qb.Error {
 	return qbErr
 }
 
+// wrapConstraintError sets the integrity code of a constraint error given
+// its extended code, and the table, column or constraint its message tells:
+//
+//	UNIQUE constraint failed: users.email
+//	NOT NULL constraint failed: users.email
+//	CHECK constraint failed: users_age
+//
+// The foreign key errors have no details.
+func wrapConstraintError(sErr sqlite3.Error, qbErr *qb.Error) {
+	var prefix string
+	switch sErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique,
+		sqlite3.ErrConstraintPrimaryKey,
+		sqlite3.ErrConstraintRowID:
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrUniqueViolation
+		prefix = "UNIQUE constraint failed: "
+	case sqlite3.ErrConstraintForeignKey:
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrForeignKeyViolation
+	case sqlite3.ErrConstraintNotNull:
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrNotNullViolation
+		prefix = "NOT NULL constraint failed: "
+	case sqlite3.ErrConstraintCheck:
+		qbErr.Code, qbErr.Detail = qb.ErrIntegrity, qb.ErrCheckViolation
+		prefix = "CHECK constraint failed: "
+	default:
+		qbErr.Code = qb.ErrIntegrity
+	}
+	msg := sErr.Error()
+	if prefix == "" || !strings.HasPrefix(msg, prefix) {
+		return
+	}
+	detail := strings.TrimPrefix(msg, prefix)
+	if sErr.ExtendedCode == sqlite3.ErrConstraintCheck {
+		qbErr.Constraint = detail
+		return
+	}
+	// table.column, or table.column1, table.column2 for the composite keys
+	cols := strings.Split(detail, ", ")
+	if i := strings.Index(cols[0], "."); i != -1 {
+		qbErr.Table = cols[0][:i]
+		if len(cols) == 1 {
+			qbErr.Column = cols[0][i+1:]
+		}
+	}
+}
+
 // SqliteCompiler is a SQLCompiler specialised for Sqlite
 type SqliteCompiler struct {
 	qb.SQLCompiler
 }
 
+// VisitCompoundSelect compiles a compound select statement without
+// parenthesis around the select statements, as sqlite does not accept them
+func (SqliteCompiler) VisitCompoundSelect(context *qb.CompilerContext, compound qb.CompoundSelectStmt) string {
+	return qb.CompileCompoundSelect(context, compound, false)
+}
+
+// VisitFunc compiles a function call. sqlite has no CHAR_LENGTH function,
+// its LENGTH function counting the characters of the strings
+func (c SqliteCompiler) VisitFunc(context *qb.CompilerContext, fn qb.FuncClause) string {
+	if fn.Name == "LENGTH" {
+		return qb.CompileFuncCall(context, "LENGTH", fn.Args)
+	}
+	return c.SQLCompiler.VisitFunc(context, fn)
+}
+
+// VisitJoin compiles a JOIN clause. sqlite only has FULL OUTER JOIN since
+// 3.39, which is more recent than the bundled one
+func (c SqliteCompiler) VisitJoin(context *qb.CompilerContext, join qb.JoinClause) string {
+	if join.JoinType == "FULL OUTER JOIN" {
+		context.AddError(qb.Error{
+			Code: qb.ErrNotSupported,
+			Orig: fmt.Errorf("FULL OUTER JOIN is not supported by sqlite"),
+		})
+	}
+	return c.SQLCompiler.VisitJoin(context, join)
+}
+
+// VisitOrderTerm compiles a term of an ORDER BY. NULLS FIRST and NULLS LAST
+// are emulated by ordering term IS NULL first, as sqlite only supports them
+// since 3.30
+func (c SqliteCompiler) VisitOrderTerm(context *qb.CompilerContext, term qb.OrderTermClause) string {
+	return qb.CompileEmulatedNullsOrder(context, term, qb.IsNull(term.Clause))
+}
+
 // VisitUpsert generates the following sql: REPLACE INTO ... VALUES ...
 func (SqliteCompiler) VisitUpsert(context *qb.CompilerContext, upsert qb.UpsertStmt) string {
 	var (
 		colNames []string
 		values   []string
 	)
-	for k, v := range upsert.ValuesMap {
-		colNames = append(colNames, context.Compiler.VisitLabel(context, k))
-		context.Binds = append(context.Binds, v)
+	for _, cv := range upsert.ValueSet {
+		colNames = append(colNames, context.Compiler.VisitLabel(context, cv.Column))
+		context.Binds = append(context.Binds, cv.Value)
 		values = append(values, "?")
 	}
 
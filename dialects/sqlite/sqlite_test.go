@@ -1,8 +1,10 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
@@ -36,6 +38,7 @@ func (suite *SqliteTestSuite) TestUUID() {
 func (suite *SqliteTestSuite) TestDialect() {
 	dialect := qb.NewDialect("sqlite")
 	assert.Equal(suite.T(), false, dialect.SupportsUnsigned())
+	assert.Equal(suite.T(), true, dialect.(qb.TransactionalDDL).SupportsTransactionalDDL())
 	assert.Equal(suite.T(), "test", dialect.Escape("test"))
 	assert.Equal(suite.T(), false, dialect.Escaping())
 	dialect.SetEscaping(true)
@@ -58,6 +61,7 @@ func (suite *SqliteTestSuite) TestWrapError() {
 		{sqlite3.ErrInternal, qb.ErrInternal},
 		{sqlite3.ErrNotFound, qb.ErrInternal},
 		{sqlite3.ErrNomem, qb.ErrInternal},
+		{sqlite3.ErrInterrupt, qb.ErrCanceled},
 		{sqlite3.ErrError, qb.ErrOperational},
 		{sqlite3.ErrIoErr, qb.ErrOperational},
 		{sqlite3.ErrCorrupt, qb.ErrDatabase},
@@ -71,6 +75,10 @@ func (suite *SqliteTestSuite) TestWrapError() {
 		qErr := dialect.WrapError(sErr)
 		assert.Equal(suite.T(), tt.expCode, qErr.Code)
 	}
+
+	assert.Equal(suite.T(), qb.ErrCanceled, dialect.WrapError(context.Canceled).Code)
+	assert.Equal(suite.T(), qb.ErrCanceled, dialect.WrapError(context.DeadlineExceeded).Code)
+	assert.Equal(suite.T(), qb.ErrCanceled, dialect.WrapError(fmt.Errorf("query: %w", context.Canceled)).Code)
 }
 
 func (suite *SqliteTestSuite) TestSqlite() {
@@ -222,11 +230,814 @@ func (suite *SqliteTestSuite) TestUpsert() {
 	assert.Equal(suite.T(), 3, len(binds))
 }
 
+func (suite *SqliteTestSuite) TestCompoundSelect() {
+	users := qb.Table(
+		"compound_users",
+		qb.Column("email", qb.Varchar()),
+	)
+	admins := qb.Table(
+		"compound_admins",
+		qb.Column("email", qb.Varchar()),
+	)
+	suite.metadata.AddTable(users)
+	suite.metadata.AddTable(admins)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+
+	for _, email := range []string{"al@pacino.com", "robert@deniro.com"} {
+		_, err := suite.engine.Exec(users.Insert().Values(map[string]interface{}{"email": email}))
+		assert.Nil(suite.T(), err)
+	}
+	_, err := suite.engine.Exec(admins.Insert().Values(map[string]interface{}{"email": "al@pacino.com"}))
+	assert.Nil(suite.T(), err)
+
+	union := qb.Union(
+		qb.Select(users.C("email")).From(users),
+		qb.Select(admins.C("email")).From(admins),
+	).OrderBy(users.C("email")).Desc()
+
+	ctx := qb.NewCompilerContext(suite.engine.Dialect())
+	assert.Equal(suite.T(), "SELECT email\nFROM compound_users\nUNION\nSELECT email\nFROM compound_admins\nORDER BY email DESC", union.Accept(ctx))
+
+	var emails []string
+	assert.Nil(suite.T(), suite.engine.Select(union, &emails))
+	assert.Equal(suite.T(), []string{"robert@deniro.com", "al@pacino.com"}, emails)
+
+	emails = nil
+	assert.Nil(suite.T(), suite.engine.Select(qb.UnionAll(
+		qb.Select(users.C("email")).From(users),
+		qb.Select(admins.C("email")).From(admins),
+	), &emails))
+	assert.Equal(suite.T(), 3, len(emails))
+
+	emails = nil
+	assert.Nil(suite.T(), suite.engine.Select(qb.Except(
+		qb.Select(users.C("email")).From(users),
+		qb.Select(admins.C("email")).From(admins),
+	), &emails))
+	assert.Equal(suite.T(), []string{"robert@deniro.com"}, emails)
+}
+
+func (suite *SqliteTestSuite) TestRecursiveCTE() {
+	ref := qb.Table("counter", qb.Column("n", qb.Int()))
+	counter := qb.WithRecursive("counter", qb.UnionAll(
+		qb.Select(qb.SQLText("1")),
+		qb.Select(qb.SQLText("n + 1")).From(ref).Where(ref.C("n").Lt(5)),
+	)).Columns("n")
+
+	var values []int
+	err := suite.engine.Select(qb.Select(counter.C("n")).From(counter).With(counter), &values)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []int{1, 2, 3, 4, 5}, values)
+}
+
+func (suite *SqliteTestSuite) TestReflect() {
+	users := qb.Table(
+		"reflect_users",
+		qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()).NotNull().Unique(),
+		qb.Column("first_name", qb.Varchar().Size(40)),
+		qb.Column("last_name", qb.Varchar().Size(40)),
+		qb.Column("balance", qb.Decimal().Precision(10, 2)).Constraint("DEFAULT 0"),
+		qb.UniqueKey("first_name", "last_name"),
+	).Index("last_name")
+	sessions := qb.Table(
+		"reflect_sessions",
+		qb.Column("user_id", qb.Int()),
+		qb.Column("token", qb.Varchar().Size(36)),
+		qb.PrimaryKey("user_id", "token"),
+		qb.ForeignKey("user_id").References("reflect_users", "id").OnDelete("CASCADE"),
+	)
+	suite.metadata.AddTable(users)
+	suite.metadata.AddTable(sessions)
+	assert.Nil(suite.T(), suite.metadata.CreateAll(suite.engine))
+	defer suite.metadata.DropAll(suite.engine)
+
+	names, err := suite.engine.Dialect().(qb.Reflector).ReflectTableNames(suite.engine)
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), names, "reflect_users")
+	assert.Contains(suite.T(), names, "reflect_sessions")
+
+	metadata := qb.MetaData()
+	err = metadata.Reflect(suite.engine, "reflect_sessions", "reflect_users")
+	assert.Nil(suite.T(), err)
+	tables := metadata.Tables()
+	assert.Equal(suite.T(), 2, len(tables))
+	assert.Equal(suite.T(), "reflect_users", tables[0].Name)
+	assert.Equal(suite.T(), "reflect_sessions", tables[1].Name)
+
+	reflected := metadata.Table("reflect_users")
+	assert.Equal(suite.T(), 5, len(reflected.Columns))
+	assert.Equal(suite.T(), []string{"id"}, reflected.PrimaryKeyConstraint.Columns)
+	assert.True(suite.T(), reflected.C("id").Options.AutoIncrement)
+	assert.Equal(suite.T(), "id INTEGER PRIMARY KEY", reflected.C("id").String(suite.engine.Dialect()))
+	assert.Equal(suite.T(), "email VARCHAR(255) NOT NULL UNIQUE", reflected.C("email").String(suite.engine.Dialect()))
+	assert.Equal(suite.T(), "first_name VARCHAR(40)", reflected.C("first_name").String(suite.engine.Dialect()))
+	assert.Equal(suite.T(), "balance DECIMAL(10, 2) DEFAULT 0", reflected.C("balance").String(suite.engine.Dialect()))
+	assert.Equal(suite.T(), qb.UniqueKey("first_name", "last_name").Table("reflect_users"), reflected.UniqueKeyConstraint)
+	assert.Equal(suite.T(), []qb.IndexElem{
+		{Table: "reflect_users", Name: "i_last_name", Columns: []string{"last_name"}},
+	}, reflected.Indices)
+
+	reflected = metadata.Table("reflect_sessions")
+	assert.Equal(suite.T(), []string{"user_id", "token"}, reflected.PrimaryKeyConstraint.Columns)
+	assert.Equal(suite.T(), "token VARCHAR(36)", reflected.C("token").String(suite.engine.Dialect()))
+	assert.Equal(suite.T(), []qb.ForeignKeyConstraint{{
+		Cols:           []string{"user_id"},
+		RefTable:       "reflect_users",
+		RefCols:        []string{"id"},
+		ActionOnDelete: "CASCADE",
+	}}, reflected.ForeignKeyConstraints.FKeys)
+
+	err = metadata.Reflect(suite.engine, "reflect_nothing")
+	assert.Equal(suite.T(), qb.ErrProgramming, err.(qb.Error).Code)
+	assert.Equal(suite.T(), "reflect_nothing", err.(qb.Error).Table)
+}
+
+func (suite *SqliteTestSuite) TestSchemaChanges() {
+	v1 := qb.MetaData()
+	v1.AddTable(qb.Table(
+		"diff_users",
+		qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()).NotNull(),
+		qb.Column("age", qb.Int()),
+	).Index("email"))
+	v1.AddTable(qb.Table(
+		"diff_posts",
+		qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement(),
+		qb.Column("user_id", qb.Int()),
+		qb.Column("title", qb.Varchar()),
+	))
+	assert.Nil(suite.T(), v1.CreateAll(suite.engine))
+
+	_, err := suite.engine.Exec(qb.Insert(v1.Table("diff_users")).Values(map[string]interface{}{
+		"email": "al@pacino.com",
+		"age":   79,
+	}))
+	assert.Nil(suite.T(), err)
+	_, err = suite.engine.Exec(qb.Insert(v1.Table("diff_posts")).Values(map[string]interface{}{
+		"user_id": 1,
+		"title":   "The Godfather",
+	}))
+	assert.Nil(suite.T(), err)
+
+	v2 := qb.MetaData()
+	v2.AddTable(qb.Table(
+		"diff_users",
+		qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()).NotNull().Unique(),
+		qb.Column("name", qb.Varchar()).Default("anonymous"),
+	).Index("email"))
+	v2.AddTable(qb.Table(
+		"diff_posts",
+		qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement(),
+		qb.Column("user_id", qb.Int()),
+		qb.Column("title", qb.Varchar().Size(100)).NotNull().Default("untitled"),
+		qb.ForeignKey("user_id").References("diff_users", "id"),
+	))
+	v2.AddTable(qb.Table(
+		"diff_tags",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("label", qb.Varchar()),
+	))
+	defer v2.DropAll(suite.engine)
+
+	changes, err := v2.DiffDatabase(suite.engine)
+	assert.Nil(suite.T(), err)
+	statements, err := qb.CompileSchemaChanges(suite.engine.Dialect(), changes)
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), statements, "CREATE TABLE diff_tags (\n\tid INT PRIMARY KEY,\n\tlabel VARCHAR(255)\n);")
+	assert.Contains(suite.T(), statements, "INSERT INTO qb_tmp_diff_users(id, email) SELECT id, email FROM diff_users;")
+	assert.Contains(suite.T(), statements, "ALTER TABLE qb_tmp_diff_posts RENAME TO diff_posts;")
+	for _, statement := range statements {
+		_, err = suite.engine.DB().Exec(statement)
+		assert.Nil(suite.T(), err, statement)
+	}
+
+	changes, err = v2.DiffDatabase(suite.engine)
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), changes)
+
+	var user struct {
+		ID    int
+		Email string
+		Name  string
+	}
+	users := v2.Table("diff_users")
+	err = suite.engine.Get(qb.Select(users.C("id"), users.C("email"), users.C("name")).From(users), &user)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, user.ID)
+	assert.Equal(suite.T(), "al@pacino.com", user.Email)
+	assert.Equal(suite.T(), "anonymous", user.Name)
+
+	var title string
+	posts := v2.Table("diff_posts")
+	err = suite.engine.Get(qb.Select(posts.C("title")).From(posts), &title)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "The Godfather", title)
+}
+
+func (suite *SqliteTestSuite) TestSchemaChangesReferencedTable() {
+	engine, err := qb.New("sqlite3", "./qb_test.db?_foreign_keys=1")
+	assert.Nil(suite.T(), err)
+	defer engine.Close()
+
+	v1 := qb.MetaData()
+	v1.AddTable(qb.Table(
+		"fk_groups",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("name", qb.Varchar()),
+	))
+	v1.AddTable(qb.Table(
+		"fk_members",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("group_id", qb.Int()),
+		qb.ForeignKey("group_id").References("fk_groups", "id").OnDelete("CASCADE"),
+	))
+	assert.Nil(suite.T(), v1.CreateAll(engine))
+	defer v1.DropAll(engine)
+	defer engine.DB().Exec("DROP TABLE qb_migrations")
+
+	members := v1.Table("fk_members")
+	_, err = engine.Exec(qb.Insert(v1.Table("fk_groups")).Values(map[string]interface{}{"id": 1, "name": "actors"}))
+	assert.Nil(suite.T(), err)
+	_, err = engine.Exec(qb.Insert(members).Values(map[string]interface{}{"id": 1, "group_id": 1}))
+	assert.Nil(suite.T(), err)
+
+	v2 := qb.MetaData()
+	v2.AddTable(qb.Table(
+		"fk_groups",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("name", qb.Varchar()).Unique(),
+	))
+	v2.AddTable(members)
+	statements, err := qb.CompileSchemaChanges(engine.Dialect(), qb.Diff(engine.Dialect(), v1, v2))
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), statements, "DROP TABLE fk_groups;")
+
+	rebuild := qb.NewMigration(1, "rebuild_groups", func(tx *qb.Tx) error {
+		for _, statement := range statements {
+			if _, err := tx.Tx().Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil)
+	rebuild.Checksum = "rebuild_groups"
+	assert.Nil(suite.T(), qb.NewMigrator(engine, rebuild).Up())
+
+	// the rebuild did not cascade to the members, and the foreign keys are
+	// enforced again
+	var count int
+	assert.Nil(suite.T(), engine.Get(qb.Select(qb.Count(members.C("id"))).From(members), &count))
+	assert.Equal(suite.T(), 1, count)
+	var enabled bool
+	assert.Nil(suite.T(), engine.DB().Get(&enabled, "PRAGMA foreign_keys"))
+	assert.True(suite.T(), enabled)
+
+	// the foreign keys broken by a migration are detected before it is
+	// committed
+	broken := qb.NewMigration(2, "broken", func(tx *qb.Tx) error {
+		_, err := tx.Exec(qb.Insert(members).Values(map[string]interface{}{"id": 2, "group_id": 42}))
+		return err
+	}, nil)
+	broken.Checksum = "broken"
+	err = qb.NewMigrator(engine, rebuild, broken).Up()
+	assert.Equal(suite.T(), qb.ErrIntegrity, err.(qb.Error).Code)
+	assert.Equal(suite.T(), qb.ErrForeignKeyViolation, err.(qb.Error).Detail)
+	assert.Equal(suite.T(), "fk_members", err.(qb.Error).Table)
+	assert.Nil(suite.T(), engine.Get(qb.Select(qb.Count(members.C("id"))).From(members), &count))
+	assert.Equal(suite.T(), 1, count)
+}
+
+func (suite *SqliteTestSuite) TestSchemaChangesInvalidTable() {
+	dialect := suite.engine.Dialect()
+	table := qb.Table(
+		"diff_events",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("seq", qb.Int()).AutoIncrement(),
+	)
+	for _, change := range []qb.SchemaChange{
+		{Kind: qb.ChangeCreateTable, Table: table},
+		{Kind: qb.ChangeAddColumn, Table: table, Column: table.C("seq")},
+		{Kind: qb.ChangeAlterColumn, Table: table, OldTable: table, Column: table.C("seq")},
+	} {
+		statements, err := qb.CompileSchemaChanges(dialect, []qb.SchemaChange{change})
+		assert.Nil(suite.T(), statements)
+		assert.Equal(suite.T(), qb.ErrNotSupported, err.(qb.Error).Code)
+	}
+}
+
+func (suite *SqliteTestSuite) TestAlterTable() {
+	table := qb.Table(
+		"alter_users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()).NotNull(),
+	)
+	_, err := suite.engine.DB().Exec(table.Create(suite.engine.Dialect()))
+	assert.Nil(suite.T(), err)
+
+	_, err = suite.engine.Exec(qb.AlterTable(table).AddColumn(qb.Column("age", qb.Int()).Default(18)))
+	assert.Nil(suite.T(), err)
+	_, err = suite.engine.Exec(qb.AlterTable(table).RenameColumn("email", "mail"))
+	assert.Nil(suite.T(), err)
+	_, err = suite.engine.Exec(qb.AlterTable(table).RenameTo("alter_members"))
+	assert.Nil(suite.T(), err)
+	defer suite.engine.DB().Exec("DROP TABLE alter_members")
+
+	members, err := qb.NewDialect("sqlite3").(qb.Reflector).ReflectTable(suite.engine, "alter_members")
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), members.ColumnNames(), "mail")
+	assert.Contains(suite.T(), members.ColumnNames(), "age")
+
+	for _, stmt := range []qb.AlterTableStmt{
+		qb.AlterTable(members).DropConstraint("u_mail"),
+		qb.AlterTable(members).AddConstraint(qb.UniqueKey("mail")),
+		qb.AlterTable(members).AlterColumnType("age", qb.BigInt()),
+		qb.AlterTable(members).SetNotNull("age"),
+		qb.AlterTable(members).AddColumn(qb.Column("name", qb.Varchar()).NotNull()),
+		qb.AlterTable(members).RenameTo("users").DropColumn("age"),
+	} {
+		_, err = suite.engine.Exec(stmt)
+		assert.Equal(suite.T(), qb.ErrNotSupported, err.(qb.Error).Code)
+	}
+}
+
+func (suite *SqliteTestSuite) TestTableFromStruct() {
+	type Timestamps struct {
+		CreatedAt time.Time `qb:"notnull"`
+	}
+	type Account struct {
+		ID    int64  `qb:"pk;autoincrement"`
+		Email string `qb:"type:varchar(64);notnull;unique"`
+		Timestamps
+	}
+
+	metadata := qb.MetaData()
+	accounts, err := metadata.AddStruct("struct_accounts", Account{})
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), metadata.CreateAll(suite.engine))
+	defer metadata.DropAll(suite.engine)
+
+	al := Account{Email: "al@pacino.com", Timestamps: Timestamps{time.Now()}}
+	_, err = suite.engine.Exec(accounts.Insert().ValuesFrom(&al))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), al.ID)
+
+	tx, err := suite.engine.Begin()
+	assert.Nil(suite.T(), err)
+	robert := Account{Email: "robert@deniro.com", Timestamps: Timestamps{time.Now()}}
+	_, err = tx.Exec(accounts.Insert().ValuesFrom(&robert))
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), tx.Commit())
+	assert.Equal(suite.T(), int64(2), robert.ID)
+
+	robert.Email = "bob@deniro.com"
+	_, err = suite.engine.Exec(accounts.Update().ValuesFrom(robert).Where(accounts.C("id").Eq(robert.ID)))
+	assert.Nil(suite.T(), err)
+
+	var account Account
+	err = suite.engine.Get(qb.Select(accounts.All()...).From(accounts).Where(accounts.C("id").Eq(2)), &account)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), account.ID)
+	assert.Equal(suite.T(), "bob@deniro.com", account.Email)
+	assert.False(suite.T(), account.CreatedAt.IsZero())
+}
+
+func (suite *SqliteTestSuite) TestInsertBatch() {
+	maxParams, maxBytes, err := suite.engine.Dialect().(qb.BatchLimiter).BatchLimits(suite.engine)
+	assert.Nil(suite.T(), err)
+	assert.Contains(suite.T(), []int{999, 32766}, maxParams)
+	assert.Equal(suite.T(), 0, maxBytes)
+
+	table := qb.Table(
+		"batch_users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(table)
+	assert.Nil(suite.T(), metadata.CreateAll(suite.engine))
+	defer metadata.DropAll(suite.engine)
+
+	var rows []map[string]interface{}
+	for i := 1; i <= 20000; i++ {
+		rows = append(rows, map[string]interface{}{"id": i, "email": "al@pacino.com"})
+	}
+	count, err := suite.engine.InsertBatch(qb.Insert(table).ValuesList(rows))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(20000), count)
+
+	var total int
+	err = suite.engine.Get(qb.Select(qb.Count(table.C("id"))).From(table), &total)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 20000, total)
+
+	// a failing batch rolls back the whole insert
+	_, err = suite.engine.InsertBatch(qb.Insert(table).ValuesList([]map[string]interface{}{
+		{"id": 20001, "email": "robert@deniro.com"},
+		{"id": 1, "email": "robert@deniro.com"},
+	}))
+	assert.Equal(suite.T(), qb.ErrIntegrity, err.(qb.Error).Code)
+	assert.Equal(suite.T(), qb.ErrUniqueViolation, err.(qb.Error).Detail)
+	err = suite.engine.Get(qb.Select(qb.Count(table.C("id"))).From(table), &total)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 20000, total)
+}
+
+func (suite *SqliteTestSuite) TestIntegrityErrors() {
+	engine, err := qb.New("sqlite3", "./qb_test.db?_foreign_keys=1")
+	assert.Nil(suite.T(), err)
+	defer engine.Close()
+
+	users := qb.Table(
+		"integrity_users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()).NotNull().Unique(),
+		qb.Column("age", qb.Int()).Constraint("CONSTRAINT age_positive CHECK (age >= 0)"),
+	)
+	sessions := qb.Table(
+		"integrity_sessions",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("user_id", qb.Int()),
+		qb.ForeignKey("user_id").References("integrity_users", "id"),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(users)
+	metadata.AddTable(sessions)
+	assert.Nil(suite.T(), metadata.CreateAll(engine))
+	defer metadata.DropAll(engine)
+
+	_, err = engine.Exec(qb.Insert(users).Values(map[string]interface{}{
+		"id": 1, "email": "al@pacino.com", "age": 79,
+	}))
+	assert.Nil(suite.T(), err)
+
+	for _, tt := range []struct {
+		stmt     qb.Builder
+		expected qb.Error
+	}{
+		{
+			qb.Insert(users).Values(map[string]interface{}{"id": 2, "email": "al@pacino.com"}),
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrUniqueViolation, Table: "integrity_users", Column: "email"},
+		},
+		{
+			qb.Insert(users).Values(map[string]interface{}{"id": 1, "email": "robert@deniro.com"}),
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrUniqueViolation, Table: "integrity_users", Column: "id"},
+		},
+		{
+			qb.Insert(users).Values(map[string]interface{}{"id": 2, "email": nil}),
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrNotNullViolation, Table: "integrity_users", Column: "email"},
+		},
+		{
+			qb.Insert(users).Values(map[string]interface{}{"id": 2, "email": "robert@deniro.com", "age": -1}),
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrCheckViolation, Constraint: "age_positive"},
+		},
+		{
+			qb.Insert(sessions).Values(map[string]interface{}{"id": 1, "user_id": 2}),
+			qb.Error{Code: qb.ErrIntegrity, Detail: qb.ErrForeignKeyViolation},
+		},
+	} {
+		_, err = engine.Exec(tt.stmt)
+		qbErr, ok := err.(qb.Error)
+		if assert.True(suite.T(), ok, "%v", err) {
+			tt.expected.Orig = qbErr.Orig
+			assert.Equal(suite.T(), tt.expected, qbErr)
+			assert.True(suite.T(), errors.Is(err, qb.ErrIntegrityViolation))
+		}
+	}
+}
+
+func (suite *SqliteTestSuite) TestInsertFromSelect() {
+	users := qb.Table(
+		"select_users",
+		qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()).Default("nobody"),
+	)
+	archive := qb.Table(
+		"select_archive",
+		qb.Column("id", qb.Int()).PrimaryKey().AutoIncrement(),
+		qb.Column("email", qb.Varchar()),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(users)
+	metadata.AddTable(archive)
+	assert.Nil(suite.T(), metadata.CreateAll(suite.engine))
+	defer metadata.DropAll(suite.engine)
+
+	res, err := suite.engine.Exec(qb.Insert(users).DefaultValues())
+	assert.Nil(suite.T(), err)
+	id, err := res.LastInsertId()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), id)
+	_, err = suite.engine.Exec(qb.Insert(users).DefaultValues())
+	assert.Nil(suite.T(), err)
+
+	sel := qb.Select(users.C("email")).From(users)
+	res, err = suite.engine.Exec(qb.Insert(archive).Columns(archive.C("email")).FromSelect(sel))
+	assert.Nil(suite.T(), err)
+	count, err := res.RowsAffected()
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), count)
+
+	var emails []string
+	err = suite.engine.Select(qb.Select(archive.C("email")).From(archive), &emails)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"nobody", "nobody"}, emails)
+}
+
+func (suite *SqliteTestSuite) TestPrepare() {
+	users := qb.Table(
+		"prepare_users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(users)
+	assert.Nil(suite.T(), metadata.CreateAll(suite.engine))
+	defer metadata.DropAll(suite.engine)
+
+	dialect := suite.engine.Dialect()
+	ins := qb.Prepare(users.Insert().Values(map[string]interface{}{"id": qb.Param("id"), "email": qb.Param("email")}), dialect)
+	for i, email := range []string{"al@pacino.com", "robert@deniro.com"} {
+		_, err := suite.engine.Exec(ins.Bind(map[string]interface{}{"id": i + 1, "email": email}))
+		assert.Nil(suite.T(), err)
+	}
+
+	sel := qb.Prepare(users.Select(users.C("email")).Where(users.C("id").Eq(qb.Param("id"))), dialect)
+	var email string
+	assert.Nil(suite.T(), suite.engine.Get(sel.Bind(map[string]interface{}{"id": 2}), &email))
+	assert.Equal(suite.T(), "robert@deniro.com", email)
+
+	_, err := suite.engine.Exec(ins.Bind(map[string]interface{}{"id": 3}))
+	assert.EqualError(suite.T(), err, "Interface error: Missing value of the parameter email")
+}
+
+func (suite *SqliteTestSuite) TestWindow() {
+	sales := qb.Table(
+		"window_sales",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("region", qb.Varchar()),
+		qb.Column("amount", qb.Int()),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(sales)
+	assert.Nil(suite.T(), metadata.CreateAll(suite.engine))
+	defer metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(sales.Insert().ValuesList([]map[string]interface{}{
+		{"id": 1, "region": "north", "amount": 10},
+		{"id": 2, "region": "north", "amount": 30},
+		{"id": 3, "region": "south", "amount": 20},
+		{"id": 4, "region": "north", "amount": 20},
+	}))
+	assert.Nil(suite.T(), err)
+
+	rows, err := suite.engine.Query(qb.Select(
+		sales.C("id"),
+		qb.RowNumber().Over(qb.WindowName("w")),
+		qb.Sum(sales.C("amount")).Over(qb.Window().PartitionBy(sales.C("region")).OrderBy(sales.C("amount")).Rows(qb.UnboundedPreceding, qb.CurrentRow)),
+		qb.Lag(sales.C("amount"), 1).Over(qb.WindowName("w")),
+	).
+		From(sales).
+		Window("w", qb.Window().PartitionBy(sales.C("region")).OrderBy(sales.C("amount"))).
+		OrderBy(sales.C("id")))
+	assert.Nil(suite.T(), err)
+	defer rows.Close()
+	var results [][]int64
+	for rows.Next() {
+		var id, rank, running int64
+		var prev sql.NullInt64
+		assert.Nil(suite.T(), rows.Scan(&id, &rank, &running, &prev))
+		results = append(results, []int64{id, rank, running, prev.Int64})
+	}
+	assert.Equal(suite.T(), [][]int64{
+		{1, 1, 10, 0},
+		{2, 3, 60, 20},
+		{3, 1, 20, 0},
+		{4, 2, 30, 10},
+	}, results)
+}
+
+func (suite *SqliteTestSuite) TestFunctions() {
+	items := qb.Table(
+		"function_items",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("label", qb.Varchar()),
+		qb.Column("code", qb.Varchar()).Null(),
+		qb.Column("price", qb.Int()),
+		qb.Column("quantity", qb.Int()),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(items)
+	assert.Nil(suite.T(), metadata.CreateAll(suite.engine))
+	defer metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(items.Insert().ValuesList([]map[string]interface{}{
+		{"id": 1, "label": "Apple", "code": "ap", "price": 3, "quantity": 4},
+		{"id": 2, "label": "Pear", "code": nil, "price": 5, "quantity": 2},
+	}))
+	assert.Nil(suite.T(), err)
+
+	_, err = suite.engine.Exec(items.Update().
+		Values(map[string]interface{}{"quantity": items.C("quantity").Add(1)}).
+		Where(items.C("id").Eq(2)))
+	assert.Nil(suite.T(), err)
+
+	rows, err := suite.engine.Query(qb.Select(
+		items.C("label").Concat("-", qb.Coalesce(items.C("code"), "none")),
+		qb.Upper(items.C("label")),
+		qb.Lower(items.C("label")),
+		qb.Length(items.C("label")),
+		items.C("price").Mul(items.C("quantity")).Sub(1),
+		items.C("price").Mod(2),
+		qb.Cast(items.C("price"), qb.Varchar()),
+		qb.Now(),
+	).From(items).OrderBy(items.C("id")))
+	assert.Nil(suite.T(), err)
+	defer rows.Close()
+	var results [][]interface{}
+	for rows.Next() {
+		var label, upper, lower, price, now string
+		var length, total, mod int64
+		assert.Nil(suite.T(), rows.Scan(&label, &upper, &lower, &length, &total, &mod, &price, &now))
+		assert.NotEmpty(suite.T(), now)
+		results = append(results, []interface{}{label, upper, lower, length, total, mod, price})
+	}
+	assert.Equal(suite.T(), [][]interface{}{
+		{"Apple-ap", "APPLE", "apple", int64(5), int64(11), int64(1), "3"},
+		{"Pear-none", "PEAR", "pear", int64(4), int64(14), int64(1), "5"},
+	}, results)
+}
+
+func (suite *SqliteTestSuite) TestCase() {
+	orders := qb.Table(
+		"case_orders",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("status", qb.Int()),
+		qb.Column("amount", qb.Int()),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(orders)
+	assert.Nil(suite.T(), metadata.CreateAll(suite.engine))
+	defer metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(orders.Insert().ValuesList([]map[string]interface{}{
+		{"id": 1, "status": 1, "amount": 50},
+		{"id": 2, "status": 2, "amount": 500},
+		{"id": 3, "status": 1, "amount": 5000},
+		{"id": 4, "status": 1, "amount": 80},
+	}))
+	assert.Nil(suite.T(), err)
+
+	bucket := qb.Case().
+		When(orders.C("amount").Lt(100), "small").
+		When(orders.C("amount").Lt(1000), "medium").
+		Else("large")
+	rows, err := suite.engine.Query(qb.Select(
+		bucket,
+		qb.Count(orders.C("id")),
+		qb.Sum(qb.Case(orders.C("status")).When(1, orders.C("amount")).Else(0)),
+	).From(orders).GroupBy(bucket).OrderBy(bucket))
+	assert.Nil(suite.T(), err)
+	defer rows.Close()
+	var results [][]interface{}
+	for rows.Next() {
+		var name string
+		var count, active int64
+		assert.Nil(suite.T(), rows.Scan(&name, &count, &active))
+		results = append(results, []interface{}{name, count, active})
+	}
+	assert.Equal(suite.T(), [][]interface{}{
+		{"large", int64(1), int64(5000)},
+		{"medium", int64(1), int64(0)},
+		{"small", int64(2), int64(130)},
+	}, results)
+}
+
+func (suite *SqliteTestSuite) TestConditionals() {
+	users := qb.Table(
+		"conditional_users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("email", qb.Varchar()),
+		qb.Column("nickname", qb.Varchar()).Null(),
+		qb.Column("age", qb.Int()),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(users)
+	assert.Nil(suite.T(), metadata.CreateAll(suite.engine))
+	defer metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(users.Insert().ValuesList([]map[string]interface{}{
+		{"id": 1, "email": "Joe@Example.com", "nickname": "joe", "age": 17},
+		{"id": 2, "email": "jane@example.org", "nickname": nil, "age": 30},
+		{"id": 3, "email": "JACK@EXAMPLE.COM", "nickname": nil, "age": 45},
+	}))
+	assert.Nil(suite.T(), err)
+
+	ids := func(where qb.Clause) []int {
+		var ids []int
+		err := suite.engine.Select(qb.Select(users.C("id")).From(users).Where(where).OrderBy(users.C("id")), &ids)
+		assert.Nil(suite.T(), err)
+		return ids
+	}
+	assert.Equal(suite.T(), []int{1, 3}, ids(users.C("email").ILike("%@example.com")))
+	assert.Equal(suite.T(), []int{2, 3}, ids(users.C("email").NotLike("Joe%")))
+	assert.Equal(suite.T(), []int{2, 3}, ids(users.C("nickname").Eq(nil)))
+	assert.Equal(suite.T(), []int{1}, ids(users.C("nickname").IsNotNull()))
+	assert.Equal(suite.T(), []int{2}, ids(users.C("age").Between(18, 40)))
+	assert.Equal(suite.T(), []int{1, 3}, ids(users.C("age").NotBetween(18, 40)))
+	assert.Equal(suite.T(), []int{1}, ids(qb.Not(users.C("nickname").IsNull())))
+}
+
+func (suite *SqliteTestSuite) TestOrderBy() {
+	users := qb.Table(
+		"order_users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("name", qb.Varchar()),
+		qb.Column("score", qb.Int()).Null(),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(users)
+	assert.Nil(suite.T(), metadata.CreateAll(suite.engine))
+	defer metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(users.Insert().ValuesList([]map[string]interface{}{
+		{"id": 1, "name": "b", "score": 10},
+		{"id": 2, "name": "a", "score": nil},
+		{"id": 3, "name": "a", "score": 30},
+		{"id": 4, "name": "b", "score": nil},
+	}))
+	assert.Nil(suite.T(), err)
+
+	ids := func(sel qb.SelectStmt) []int {
+		var ids []int
+		assert.Nil(suite.T(), suite.engine.Select(sel, &ids))
+		return ids
+	}
+	sel := qb.Select(users.C("id")).From(users)
+	assert.Equal(suite.T(), []int{2, 4, 3, 1}, ids(sel.OrderBy(qb.Desc(users.C("score")).NullsFirst(), users.C("id"))))
+	assert.Equal(suite.T(), []int{1, 3, 2, 4}, ids(sel.OrderBy(qb.Asc(users.C("score")).NullsLast(), users.C("id"))))
+	assert.Equal(suite.T(), []int{3, 2, 4, 1}, ids(sel.OrderBy(users.C("name"), qb.Desc(users.C("id")))))
+	assert.Equal(suite.T(), []int{4, 3, 2, 1}, ids(sel.OrderBy(users.C("id")).Desc()))
+}
+
+func (suite *SqliteTestSuite) TestJoins() {
+	categories := qb.Table(
+		"join_categories",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("name", qb.Varchar()),
+		qb.Column("parent_id", qb.Int()).Null(),
+		qb.ForeignKey("parent_id").References("join_categories", "id"),
+	)
+	metadata := qb.MetaData()
+	metadata.AddTable(categories)
+	assert.Nil(suite.T(), metadata.CreateAll(suite.engine))
+	defer metadata.DropAll(suite.engine)
+
+	_, err := suite.engine.Exec(categories.Insert().ValuesList([]map[string]interface{}{
+		{"id": 1, "name": "books", "parent_id": nil},
+		{"id": 2, "name": "novels", "parent_id": 1},
+		{"id": 3, "name": "poetry", "parent_id": 1},
+	}))
+	assert.Nil(suite.T(), err)
+
+	parent := qb.Alias("parent", categories)
+	rows, err := suite.engine.Query(qb.Select(categories.C("name"), parent.C("name")).
+		From(categories).
+		InnerJoin(parent).
+		OrderBy(categories.C("id")))
+	assert.Nil(suite.T(), err)
+	defer rows.Close()
+	var pairs [][]string
+	for rows.Next() {
+		var name, parentName string
+		assert.Nil(suite.T(), rows.Scan(&name, &parentName))
+		pairs = append(pairs, []string{name, parentName})
+	}
+	assert.Equal(suite.T(), [][]string{{"novels", "books"}, {"poetry", "books"}}, pairs)
+
+	var names []string
+	err = suite.engine.Select(qb.Select(parent.C("name")).
+		From(categories).
+		JoinUsing(parent, "id").
+		Where(categories.C("parent_id").IsNull()), &names)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"books"}, names)
+
+	_, err = suite.engine.Query(qb.Select(categories.C("name")).
+		From(categories).
+		FullOuterJoin(parent, categories.C("parent_id"), parent.C("id")))
+	assert.Equal(suite.T(), qb.ErrNotSupported, err.(qb.Error).Code)
+}
+
 func (suite *SqliteTestSuite) TestSqliteAutoIncrement() {
 	col := qb.Column("test", qb.Int()).AutoIncrement()
-	assert.Panics(suite.T(), func() {
-		col.String(suite.engine.Dialect())
-	})
+	assert.Error(suite.T(), suite.engine.Dialect().(*Dialect).CheckColumn(col))
+	stmt := qb.Table("test", col, qb.Column("name", qb.Varchar())).Build(suite.engine.Dialect())
+	qbErr, ok := stmt.Err().(qb.Error)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), qb.ErrNotSupported, qbErr.Code)
 
 	col.Options.InlinePrimaryKey = true
 	assert.Equal(suite.T(), "INTEGER PRIMARY KEY", suite.engine.Dialect().AutoIncrement(&col))
@@ -0,0 +1,412 @@
+package qb
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaChangeKind is the kind of a SchemaChange
+type SchemaChangeKind int
+
+// The kinds of schema changes, in the order they are produced by Diff
+const (
+	ChangeDropForeignKey SchemaChangeKind = iota
+	ChangeDropIndex
+	ChangeDropUniqueKey
+	ChangeDropTable
+	ChangeCreateTable
+	ChangeAddColumn
+	ChangeAlterColumn
+	ChangeDropColumn
+	ChangeAlterPrimaryKey
+	ChangeAddUniqueKey
+	ChangeAddIndex
+	ChangeAddForeignKey
+)
+
+// SchemaChange is a single change of a database schema, as found by Diff
+type SchemaChange struct {
+	Kind SchemaChangeKind
+	// Table is the definition of the table after the change, or before it
+	// for ChangeDropTable
+	Table TableElem
+	// OldTable is the definition of the table before the change. It is
+	// empty for ChangeCreateTable and ChangeDropTable
+	OldTable TableElem
+	// Column is the added, altered or dropped column
+	Column ColumnElem
+	// OldColumn is the definition of the column before a ChangeAlterColumn
+	OldColumn  ColumnElem
+	Index      IndexElem
+	ForeignKey ForeignKeyConstraint
+	UniqueKey  UniqueKeyConstraint
+}
+
+// SchemaChangeCompiler is an optional Dialect capability: the dialects
+// implementing it have their own rendering of the schema changes.
+// The others are rendered by DefaultCompileSchemaChange
+type SchemaChangeCompiler interface {
+	CompileSchemaChanges(changes []SchemaChange) ([]string, error)
+}
+
+// CompileSchemaChanges returns the DDL statements that apply the changes
+func CompileSchemaChanges(dialect Dialect, changes []SchemaChange) ([]string, error) {
+	if compiler, ok := dialect.(SchemaChangeCompiler); ok {
+		return compiler.CompileSchemaChanges(changes)
+	}
+	var statements []string
+	for _, change := range changes {
+		sqls, err := DefaultCompileSchemaChange(dialect, change)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, sqls...)
+	}
+	return statements, nil
+}
+
+// DiffDatabase returns the changes to apply to the engine database so its
+// schema matches the metadata. The tables of the database that are not in
+// the metadata are dropped, except the migrations table.
+func (m *MetaDataElem) DiffDatabase(engine *Engine) ([]SchemaChange, error) {
+	reflected, err := Reflect(engine)
+	if err != nil {
+		return nil, err
+	}
+	from := MetaData()
+	for _, table := range reflected.Tables() {
+		if table.Name != MigrationsTableName {
+			from.AddTable(table)
+		}
+	}
+	return Diff(engine.Dialect(), from, m), nil
+}
+
+// Diff returns the changes that turn the 'from' schema into the 'to' schema.
+// The columns are compared by their DDL in the given dialect.
+// The changes are ordered so they can be applied one after the other:
+// the foreign keys, indices and tables are dropped first, then the tables are
+// created and the columns added, altered or dropped, and the new unique keys,
+// indices and foreign keys are added at last.
+func Diff(dialect Dialect, from *MetaDataElem, to *MetaDataElem) []SchemaChange {
+	fromTables := map[string]TableElem{}
+	for _, table := range from.Tables() {
+		fromTables[table.Name] = table
+	}
+	toTables := map[string]TableElem{}
+	for _, table := range to.Tables() {
+		toTables[table.Name] = table
+	}
+
+	var changes []SchemaChange
+	var created, dropped []TableElem
+	for _, table := range to.Tables() {
+		if _, ok := fromTables[table.Name]; !ok {
+			created = append(created, table)
+		}
+	}
+	for _, table := range from.Tables() {
+		oldTable := table
+		table, ok := toTables[oldTable.Name]
+		if !ok {
+			dropped = append(dropped, oldTable)
+			continue
+		}
+		changes = append(changes, diffTable(dialect, oldTable, table)...)
+	}
+
+	dropped = sortTablesByDependency(dropped)
+	for i := len(dropped) - 1; i >= 0; i-- {
+		changes = append(changes, SchemaChange{Kind: ChangeDropTable, Table: dropped[i]})
+	}
+	for _, table := range sortTablesByDependency(created) {
+		changes = append(changes, SchemaChange{Kind: ChangeCreateTable, Table: table})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Kind < changes[j].Kind
+	})
+	return changes
+}
+
+// diffTable returns the changes of an existing table
+func diffTable(dialect Dialect, oldTable TableElem, table TableElem) []SchemaChange {
+	var changes []SchemaChange
+	change := func(kind SchemaChangeKind) SchemaChange {
+		return SchemaChange{Kind: kind, Table: table, OldTable: oldTable}
+	}
+
+	for _, col := range table.Columns {
+		oldCol, ok := oldTable.Lookup(col.Name)
+		if !ok {
+			c := change(ChangeAddColumn)
+			c.Column = col
+			changes = append(changes, c)
+		} else if !sameColumn(dialect, oldCol, col) {
+			c := change(ChangeAlterColumn)
+			c.Column, c.OldColumn = col, oldCol
+			changes = append(changes, c)
+		}
+	}
+	for _, oldCol := range oldTable.Columns {
+		if _, ok := table.Lookup(oldCol.Name); !ok {
+			c := change(ChangeDropColumn)
+			c.Column = oldCol
+			changes = append(changes, c)
+		}
+	}
+
+	oldPkey, pkey := oldTable.PrimaryKeyConstraint.Columns, table.PrimaryKeyConstraint.Columns
+	if len(oldPkey)+len(pkey) != 0 && !reflect.DeepEqual(oldPkey, pkey) {
+		changes = append(changes, change(ChangeAlterPrimaryKey))
+	}
+
+	if !reflect.DeepEqual(oldTable.UniqueKeyConstraint.cols, table.UniqueKeyConstraint.cols) {
+		if len(oldTable.UniqueKeyConstraint.cols) != 0 {
+			c := change(ChangeDropUniqueKey)
+			c.UniqueKey = oldTable.UniqueKeyConstraint
+			changes = append(changes, c)
+		}
+		if len(table.UniqueKeyConstraint.cols) != 0 {
+			c := change(ChangeAddUniqueKey)
+			c.UniqueKey = table.UniqueKeyConstraint
+			changes = append(changes, c)
+		}
+	}
+
+	for _, index := range oldTable.Indices {
+		if !containsIndex(table.Indices, index) {
+			c := change(ChangeDropIndex)
+			c.Index = index
+			changes = append(changes, c)
+		}
+	}
+	for _, index := range table.Indices {
+		if !containsIndex(oldTable.Indices, index) {
+			c := change(ChangeAddIndex)
+			c.Index = index
+			changes = append(changes, c)
+		}
+	}
+
+	for _, fkey := range oldTable.ForeignKeyConstraints.FKeys {
+		if !containsForeignKey(table.ForeignKeyConstraints.FKeys, fkey) {
+			c := change(ChangeDropForeignKey)
+			c.ForeignKey = fkey
+			changes = append(changes, c)
+		}
+	}
+	for _, fkey := range table.ForeignKeyConstraints.FKeys {
+		if !containsForeignKey(oldTable.ForeignKeyConstraints.FKeys, fkey) {
+			c := change(ChangeAddForeignKey)
+			c.ForeignKey = fkey
+			changes = append(changes, c)
+		}
+	}
+
+	return changes
+}
+
+func containsIndex(indices []IndexElem, index IndexElem) bool {
+	for _, i := range indices {
+		if i.Name == index.Name && i.Unique == index.Unique && reflect.DeepEqual(i.Columns, index.Columns) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsForeignKey looks for a foreign key with the same definition. The
+// names are compared only if both are set.
+func containsForeignKey(fkeys []ForeignKeyConstraint, fkey ForeignKeyConstraint) bool {
+	for _, f := range fkeys {
+		if f.Name != "" && fkey.Name != "" && f.Name != fkey.Name {
+			continue
+		}
+		if f.RefTable == fkey.RefTable &&
+			reflect.DeepEqual(f.Cols, fkey.Cols) &&
+			reflect.DeepEqual(f.RefCols, fkey.RefCols) &&
+			f.ActionOnUpdate == fkey.ActionOnUpdate &&
+			f.ActionOnDelete == fkey.ActionOnDelete {
+			return true
+		}
+	}
+	return false
+}
+
+// columnType returns the type of a column as rendered in its DDL
+func columnType(dialect Dialect, col ColumnElem) string {
+	if col.Options.AutoIncrement {
+		if ddl := dialect.AutoIncrement(&col); ddl != "" {
+			return ddl
+		}
+	}
+	return dialect.CompileType(col.Type)
+}
+
+// ColumnNotNull returns true if the column cannot be null
+func ColumnNotNull(col ColumnElem) bool {
+	if col.Options.PrimaryKey {
+		return true
+	}
+	for _, constraint := range col.Constraints {
+		if strings.ToUpper(constraint.Name) == "NOT NULL" {
+			return true
+		}
+	}
+	return false
+}
+
+// ColumnDefault returns the default value expression of a column, and
+// false if it has no default value
+func ColumnDefault(col ColumnElem) (string, bool) {
+	for _, constraint := range col.Constraints {
+		if strings.HasPrefix(strings.ToUpper(constraint.Name), "DEFAULT ") {
+			return strings.TrimSpace(constraint.Name[len("DEFAULT "):]), true
+		}
+	}
+	return "", false
+}
+
+// normalizeDefault removes the quotes and the postgres casts of a default
+// value expression, so the expressions written by hand and the reflected
+// ones can be compared
+func normalizeDefault(value string) string {
+	if i := strings.Index(value, "::"); i != -1 {
+		value = value[:i]
+	}
+	return strings.Trim(value, "'")
+}
+
+func sameColumn(dialect Dialect, a ColumnElem, b ColumnElem) bool {
+	if columnType(dialect, a) != columnType(dialect, b) ||
+		ColumnNotNull(a) != ColumnNotNull(b) ||
+		a.Options.Unique != b.Options.Unique {
+		return false
+	}
+	aDefault, aHasDefault := ColumnDefault(a)
+	bDefault, bHasDefault := ColumnDefault(b)
+	return aHasDefault == bHasDefault && normalizeDefault(aDefault) == normalizeDefault(bDefault)
+}
+
+// errSchemaChangeNotSupported returns a ErrNotSupported Error for a change
+func errSchemaChangeNotSupported(dialect Dialect, change SchemaChange, reason string) Error {
+	return Error{
+		Code:   ErrNotSupported,
+		Orig:   fmt.Errorf("Cannot change the schema of %s with the %s dialect: %s", change.Table.Name, dialect.Driver(), reason),
+		Table:  change.Table.Name,
+		Column: change.Column.Name,
+	}
+}
+
+// CreateTableStatements returns the CREATE TABLE statement of a table
+// followed by the CREATE INDEX statements of its indices, or the error
+// that makes the table definition invalid for the dialect
+func CreateTableStatements(dialect Dialect, table TableElem) ([]string, error) {
+	if err := table.Err(dialect); err != nil {
+		return nil, err
+	}
+	indices := table.Indices
+	table.Indices = nil
+	statements := []string{table.Create(dialect)}
+	for _, index := range indices {
+		statements = append(statements, index.String(dialect))
+	}
+	return statements, nil
+}
+
+// DefaultCompileSchemaChange is a default implementation of the schema
+// changes rendering, with the ALTER TABLE syntax of the SQL standard
+func DefaultCompileSchemaChange(dialect Dialect, change SchemaChange) ([]string, error) {
+	table := dialect.Escape(change.Table.Name)
+	alterTable := "ALTER TABLE " + table + " "
+	switch change.Kind {
+	case ChangeCreateTable:
+		return CreateTableStatements(dialect, change.Table)
+	case ChangeDropTable:
+		return []string{change.Table.Drop(dialect)}, nil
+	case ChangeAddColumn:
+		if err := ColumnErr(dialect, change.Column); err != nil {
+			return nil, err
+		}
+		return []string{alterTable + "ADD COLUMN " + change.Column.String(dialect) + ";"}, nil
+	case ChangeDropColumn:
+		return []string{alterTable + "DROP COLUMN " + dialect.Escape(change.Column.Name) + ";"}, nil
+	case ChangeAlterPrimaryKey:
+		if len(change.OldTable.PrimaryKeyConstraint.Columns) != 0 {
+			return nil, errSchemaChangeNotSupported(dialect, change, "cannot drop a primary key without name")
+		}
+		return []string{alterTable + "ADD " + change.Table.PrimaryKeyConstraint.String(dialect) + ";"}, nil
+	case ChangeAlterColumn:
+		return defaultCompileAlterColumn(dialect, change)
+	case ChangeAddIndex:
+		return []string{change.Index.String(dialect)}, nil
+	case ChangeDropIndex:
+		return []string{"DROP INDEX " + dialect.Escape(change.Index.Name) + ";"}, nil
+	case ChangeAddUniqueKey:
+		return []string{alterTable + "ADD " + change.UniqueKey.String(dialect) + ";"}, nil
+	case ChangeDropUniqueKey:
+		return []string{alterTable + "DROP CONSTRAINT " + dialect.Escape(change.UniqueKey.name) + ";"}, nil
+	case ChangeAddForeignKey:
+		if err := change.ForeignKey.Err(); err != nil {
+			return nil, err
+		}
+		return []string{alterTable + "ADD " + strings.TrimSpace(change.ForeignKey.String(dialect)) + ";"}, nil
+	case ChangeDropForeignKey:
+		if change.ForeignKey.Name == "" {
+			return nil, errSchemaChangeNotSupported(dialect, change, "cannot drop a foreign key without name")
+		}
+		return []string{alterTable + "DROP CONSTRAINT " + dialect.Escape(change.ForeignKey.Name) + ";"}, nil
+	default:
+		return nil, errSchemaChangeNotSupported(dialect, change, fmt.Sprintf("unknown change %d", change.Kind))
+	}
+}
+
+func defaultCompileAlterColumn(dialect Dialect, change SchemaChange) ([]string, error) {
+	col, oldCol := change.Column, change.OldColumn
+	alterColumn := fmt.Sprintf(
+		"ALTER TABLE %s ALTER COLUMN %s ",
+		dialect.Escape(change.Table.Name),
+		dialect.Escape(col.Name),
+	)
+	var statements []string
+
+	if col.Options.AutoIncrement != oldCol.Options.AutoIncrement {
+		return nil, errSchemaChangeNotSupported(dialect, change, "cannot alter the auto increment of a column")
+	}
+	if columnType(dialect, col) != columnType(dialect, oldCol) {
+		statements = append(statements, alterColumn+"TYPE "+dialect.CompileType(col.Type)+";")
+	}
+
+	if notNull := ColumnNotNull(col); notNull != ColumnNotNull(oldCol) {
+		if notNull {
+			statements = append(statements, alterColumn+"SET NOT NULL;")
+		} else {
+			statements = append(statements, alterColumn+"DROP NOT NULL;")
+		}
+	}
+
+	value, hasDefault := ColumnDefault(col)
+	oldValue, oldHasDefault := ColumnDefault(oldCol)
+	if hasDefault != oldHasDefault || normalizeDefault(value) != normalizeDefault(oldValue) {
+		if hasDefault {
+			statements = append(statements, alterColumn+"SET DEFAULT "+value+";")
+		} else {
+			statements = append(statements, alterColumn+"DROP DEFAULT;")
+		}
+	}
+
+	if col.Options.Unique != oldCol.Options.Unique {
+		if !col.Options.Unique {
+			return nil, errSchemaChangeNotSupported(dialect, change, "cannot drop an unnamed unique constraint")
+		}
+		statements = append(statements, fmt.Sprintf(
+			"ALTER TABLE %s ADD UNIQUE(%s);",
+			dialect.Escape(change.Table.Name),
+			dialect.Escape(col.Name),
+		))
+	}
+	return statements, nil
+}
@@ -0,0 +1,251 @@
+package qb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func diffTestSchemas() (*MetaDataElem, *MetaDataElem) {
+	postsUser := ForeignKey("user_id").References("users", "id")
+	postsUser.Name = "fk_posts_user"
+
+	from := MetaData()
+	from.AddTable(Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("email", Varchar()),
+		Column("name", Varchar()).NotNull(),
+		Column("age", Int()),
+	).Index("name"))
+	from.AddTable(Table(
+		"posts",
+		Column("id", Int()).PrimaryKey(),
+		Column("user_id", Int()),
+		postsUser,
+	))
+	from.AddTable(Table("logs", Column("id", Int())))
+
+	to := MetaData()
+	to.AddTable(Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("email", Varchar()).NotNull().Unique(),
+		Column("name", Varchar()).Default("anonymous"),
+		Column("bio", Text()),
+	).Index("email"))
+	to.AddTable(Table(
+		"memberships",
+		Column("group_id", Int()),
+		Column("user_id", Int()),
+		ForeignKey("group_id").References("groups", "id"),
+		UniqueKey("group_id", "user_id"),
+	))
+	to.AddTable(Table(
+		"groups",
+		Column("id", Int()).PrimaryKey(),
+	))
+	to.AddTable(Table(
+		"posts",
+		Column("id", Int()).PrimaryKey(),
+		Column("user_id", Int()),
+		Column("author_id", Int()),
+		ForeignKey("author_id").References("users", "id"),
+	))
+	return from, to
+}
+
+func TestDiff(t *testing.T) {
+	dialect := NewDefaultDialect()
+	from, to := diffTestSchemas()
+
+	changes := Diff(dialect, from, to)
+	var kinds []SchemaChangeKind
+	for _, change := range changes {
+		kinds = append(kinds, change.Kind)
+	}
+	assert.Equal(t, []SchemaChangeKind{
+		ChangeDropForeignKey,
+		ChangeDropIndex,
+		ChangeDropTable,
+		ChangeCreateTable,
+		ChangeCreateTable,
+		ChangeAddColumn,
+		ChangeAddColumn,
+		ChangeAlterColumn,
+		ChangeAlterColumn,
+		ChangeDropColumn,
+		ChangeAddIndex,
+		ChangeAddForeignKey,
+	}, kinds)
+	assert.Equal(t, "groups", changes[3].Table.Name)
+	assert.Equal(t, "memberships", changes[4].Table.Name)
+	assert.Equal(t, "users", changes[7].OldTable.Name)
+	assert.Equal(t, "email", changes[7].OldColumn.Name)
+
+	statements, err := CompileSchemaChanges(dialect, changes)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE posts DROP CONSTRAINT fk_posts_user;",
+		"DROP INDEX i_name;",
+		"DROP TABLE logs;",
+		"CREATE TABLE groups (\n\tid INT PRIMARY KEY\n);",
+		"CREATE TABLE memberships (\n\tgroup_id INT,\n\tuser_id INT,\n\tFOREIGN KEY(group_id) REFERENCES groups(id),\n\tCONSTRAINT u_memberships_group_id_user_id UNIQUE(group_id, user_id)\n);",
+		"ALTER TABLE users ADD COLUMN bio TEXT;",
+		"ALTER TABLE posts ADD COLUMN author_id INT;",
+		"ALTER TABLE users ALTER COLUMN email SET NOT NULL;",
+		"ALTER TABLE users ADD UNIQUE(email);",
+		"ALTER TABLE users ALTER COLUMN name DROP NOT NULL;",
+		"ALTER TABLE users ALTER COLUMN name SET DEFAULT 'anonymous';",
+		"ALTER TABLE users DROP COLUMN age;",
+		"CREATE INDEX i_email ON users(email);",
+		"ALTER TABLE posts ADD FOREIGN KEY(author_id) REFERENCES users(id);",
+	}, statements)
+
+	assert.Empty(t, Diff(dialect, to, to))
+	assert.Empty(t, Diff(dialect, MetaData(), MetaData()))
+}
+
+func TestDiffColumns(t *testing.T) {
+	dialect := NewDefaultDialect()
+	from := MetaData()
+	from.AddTable(Table(
+		"users",
+		Column("id", Int()).PrimaryKey().AutoIncrement(),
+		Column("email", Varchar()).Constraint("DEFAULT 'x'::character varying"),
+		Column("age", Int()).Unique(),
+		UniqueKey("id", "email"),
+	))
+	to := MetaData()
+	to.AddTable(Table(
+		"users",
+		Column("id", Int()).PrimaryKey().AutoIncrement().NotNull(),
+		Column("email", Varchar()).Default("x"),
+		Column("age", BigInt()).Unique(),
+	))
+
+	changes := Diff(dialect, from, to)
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, ChangeDropUniqueKey, changes[0].Kind)
+	assert.Equal(t, ChangeAlterColumn, changes[1].Kind)
+
+	statements, err := CompileSchemaChanges(dialect, changes)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"ALTER TABLE users DROP CONSTRAINT u_users_id_email;",
+		"ALTER TABLE users ALTER COLUMN age TYPE BIGINT;",
+	}, statements)
+
+	noUnique := MetaData()
+	noUnique.AddTable(Table(
+		"users",
+		Column("id", Int()).PrimaryKey().AutoIncrement(),
+		Column("email", Varchar()).Default("x"),
+		Column("age", BigInt()),
+	))
+	_, err = CompileSchemaChanges(dialect, Diff(dialect, to, noUnique))
+	assert.Equal(t, ErrNotSupported, err.(Error).Code)
+	assert.Equal(t, "users", err.(Error).Table)
+	assert.Equal(t, "age", err.(Error).Column)
+
+	_, err = CompileSchemaChanges(dialect, []SchemaChange{{
+		Kind:       ChangeDropForeignKey,
+		Table:      Table("users"),
+		ForeignKey: ForeignKey("group_id").References("groups", "id"),
+	}})
+	assert.Equal(t, ErrNotSupported, err.(Error).Code)
+}
+
+func TestDiffPrimaryKey(t *testing.T) {
+	dialect := NewDefaultDialect()
+	noPkey := MetaData()
+	noPkey.AddTable(Table(
+		"memberships",
+		Column("user_id", Int()).NotNull(),
+		Column("group_id", Int()).NotNull(),
+	))
+	pkey := MetaData()
+	pkey.AddTable(Table(
+		"memberships",
+		Column("user_id", Int()).NotNull(),
+		Column("group_id", Int()).NotNull(),
+		PrimaryKey("user_id", "group_id"),
+	))
+	userPkey := MetaData()
+	userPkey.AddTable(Table(
+		"memberships",
+		Column("user_id", Int()).NotNull(),
+		Column("group_id", Int()).NotNull(),
+		PrimaryKey("user_id"),
+	))
+
+	changes := Diff(dialect, noPkey, pkey)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, ChangeAlterPrimaryKey, changes[0].Kind)
+	statements, err := CompileSchemaChanges(dialect, changes)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"ALTER TABLE memberships ADD PRIMARY KEY(user_id, group_id);"}, statements)
+
+	changes = Diff(dialect, userPkey, pkey)
+	assert.Equal(t, ChangeAlterPrimaryKey, changes[len(changes)-1].Kind)
+	_, err = CompileSchemaChanges(dialect, changes)
+	assert.Equal(t, ErrNotSupported, err.(Error).Code)
+
+	assert.Empty(t, Diff(dialect, pkey, pkey))
+}
+
+func TestDiffAutoIncrement(t *testing.T) {
+	dialect := NewDefaultDialect()
+	col := Column("seq", Int()).AutoIncrement()
+	assert.Equal(t, dialect.AutoIncrement(&col), columnType(dialect, col))
+
+	from := MetaData()
+	from.AddTable(Table("events", Column("id", Int()).PrimaryKey(), Column("seq", Int())))
+	to := MetaData()
+	to.AddTable(Table("events", Column("id", Int()).PrimaryKey(), col))
+	changes := Diff(dialect, from, to)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, ChangeAlterColumn, changes[0].Kind)
+	assert.Empty(t, Diff(dialect, to, to))
+}
+
+func TestCompileSchemaChangesInvalidTable(t *testing.T) {
+	dialect := NewDefaultDialect()
+	fkey := ForeignKey("user_id").References("users", "id").OnUpdate("bogus")
+	posts := Table(
+		"posts",
+		Column("id", Int()).PrimaryKey(),
+		Column("user_id", Int()),
+		fkey,
+	)
+	for _, change := range []SchemaChange{
+		{Kind: ChangeCreateTable, Table: posts},
+		{Kind: ChangeAddForeignKey, Table: posts, ForeignKey: fkey},
+		{Kind: ChangeAddColumn, Table: posts, Column: posts.C("missing")},
+		{Kind: ChangeCreateTable, Table: MetaData().Table("missing")},
+	} {
+		statements, err := CompileSchemaChanges(dialect, []SchemaChange{change})
+		assert.Nil(t, statements)
+		assert.Equal(t, ErrInterface, err.(Error).Code)
+	}
+}
+
+func TestDiffDatabase(t *testing.T) {
+	from, to := diffTestSchemas()
+	dialect := &reflectorDialect{tables: map[string]TableElem{}}
+	for _, table := range from.Tables() {
+		dialect.tables[table.Name] = table
+	}
+	dialect.tables[MigrationsTableName] = migrationsTable
+	engine := &Engine{dialect: dialect}
+
+	changes, err := to.DiffDatabase(engine)
+	assert.Nil(t, err)
+	assert.Equal(t, Diff(dialect, from, to), changes)
+
+	dialect.err = errors.New("connection lost")
+	changes, err = to.DiffDatabase(engine)
+	assert.Nil(t, changes)
+	assert.NotNil(t, err)
+}
@@ -1,9 +1,11 @@
 package qb
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"os"
+	"sync/atomic"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/serenize/snaker"
@@ -21,20 +23,26 @@ func New(driver string, dsn string) (*Engine, error) {
 		return snaker.CamelToSnake(name)
 	})
 
-	return &Engine{
+	engine := &Engine{
 		dialect: NewDialect(driver),
 		dsn:     dsn,
 		db:      conn,
 		logger:  &DefaultLogger{LDefault, log.New(os.Stdout, "", -1)},
-	}, err
+	}
+	if d, ok := engine.dialect.(EngineDialect); ok {
+		engine.dialect = d.ForEngine(engine)
+	}
+	return engine, err
 }
 
 // Engine is the generic struct for handling db connections
 type Engine struct {
-	dsn     string
-	db      *sqlx.DB
-	dialect Dialect
-	logger  Logger
+	dsn        string
+	db         *sqlx.DB
+	dialect    Dialect
+	logger     Logger
+	stmts      *stmtCache
+	stmtsStats StmtCacheStats
 }
 
 // Dialect returns the engine dialect
@@ -70,6 +78,37 @@ func (e *Engine) SetLogFlags(flags LogFlags) {
 	e.logger.SetLogFlags(flags)
 }
 
+// SetStmtCacheSize enables a cache of the prepared statements of the
+// engine, keeping at most size statements. The statements are keyed by
+// their SQL, and each transaction has its own cache of the same size.
+// A size of 0 disables the cache, which is the default.
+func (e *Engine) SetStmtCacheSize(size int) {
+	if e.stmts != nil {
+		e.stmts.Close()
+		e.stmts = nil
+	}
+	if size > 0 {
+		e.stmts = newStmtCache(e.db, size, &e.stmtsStats)
+	}
+}
+
+// StmtCacheStats returns the hit and miss counters of the prepared
+// statement cache
+func (e *Engine) StmtCacheStats() StmtCacheStats {
+	return StmtCacheStats{
+		Hits:   atomic.LoadInt64(&e.stmtsStats.Hits),
+		Misses: atomic.LoadInt64(&e.stmtsStats.Misses),
+	}
+}
+
+// runner returns the prepared statement cache if enabled, or the db
+func (e *Engine) runner() runner {
+	if e.stmts != nil {
+		return e.stmts
+	}
+	return e.db
+}
+
 func (e *Engine) log(statement *Stmt) {
 	logFlags := e.logger.LogFlags()
 	if logFlags&LQuery != 0 {
@@ -80,57 +119,183 @@ func (e *Engine) log(statement *Stmt) {
 	}
 }
 
-// Exec executes insert & update type queries and returns sql.Result and error
-func (e *Engine) Exec(builder Builder) (sql.Result, error) {
-	statement := builder.Build(e.dialect)
+// build builds a statement, binds the values of its named parameters and
+// logs it. A statement that cannot be built returns an ErrInterface Error
+func (e *Engine) build(builder Builder, args []Args) (*Stmt, error) {
+	statement := bindArgs(builder.Build(e.dialect), args)
+	if err := statement.Err(); err != nil {
+		if _, ok := err.(Error); !ok {
+			err = Error{Code: ErrInterface, Orig: err}
+		}
+		return nil, err
+	}
 	e.log(statement)
-	res, err := e.db.Exec(statement.SQL(), statement.Bindings()...)
-	return res, e.TranslateError(err)
+	return statement, nil
+}
+
+// Exec executes insert & update type queries and returns sql.Result and error
+func (e *Engine) Exec(builder Builder, args ...Args) (sql.Result, error) {
+	return e.ExecContext(context.Background(), builder, args...)
+}
+
+// ExecContext executes insert & update type queries using the given context
+// and returns sql.Result and error
+func (e *Engine) ExecContext(ctx context.Context, builder Builder, args ...Args) (sql.Result, error) {
+	return e.exec(ctx, e.runner(), builder, args)
+}
+
+// InsertBatch executes an insert statement of several rows in a
+// transaction. The rows are split in batches respecting the limits of the
+// dialect. It returns the number of inserted rows.
+func (e *Engine) InsertBatch(insert InsertStmt) (int64, error) {
+	return e.InsertBatchContext(context.Background(), insert)
+}
+
+// InsertBatchContext executes an insert statement of several rows in a
+// transaction using the given context, as InsertBatch
+func (e *Engine) InsertBatchContext(ctx context.Context, insert InsertStmt) (int64, error) {
+	var maxParams, maxBytes int
+	if limiter, ok := e.dialect.(BatchLimiter); ok {
+		var err error
+		maxParams, maxBytes, err = limiter.BatchLimits(e)
+		if err != nil {
+			return 0, e.TranslateError(err)
+		}
+	}
+
+	tx, err := e.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, batch := range insert.Batches(maxParams, maxBytes) {
+		res, err := tx.ExecContext(ctx, batch)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			tx.Rollback()
+			return 0, e.TranslateError(err)
+		}
+		count += n
+	}
+	return count, tx.Commit()
+}
+
+// exec executes a statement. If it is an insert built with ValuesFrom, the
+// generated key is written back into the struct, using a RETURNING clause if
+// the dialect supports it, or LastInsertId
+func (e *Engine) exec(ctx context.Context, db runner, builder Builder, args []Args) (sql.Result, error) {
+	var key *generatedKey
+	returning := false
+	if insert, ok := builder.(InsertStmt); ok && insert.generatedKey != nil && len(insert.rows) == 0 && len(insert.returning) == 0 {
+		key = insert.generatedKey
+		if r, ok := e.dialect.(InsertReturning); ok && r.SupportsReturning() {
+			returning = true
+			builder = insert.Returning(insert.table.C(key.column))
+		}
+	}
+
+	statement, err := e.build(builder, args)
+	if err != nil {
+		return nil, err
+	}
+	if returning {
+		err := db.QueryRowContext(ctx, statement.SQL(), statement.Bindings()...).Scan(key.value.Addr().Interface())
+		if err != nil {
+			return nil, e.TranslateError(err)
+		}
+		return returningResult{key}, nil
+	}
+	res, err := db.ExecContext(ctx, statement.SQL(), statement.Bindings()...)
+	if err != nil || key == nil {
+		return res, e.TranslateError(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return res, e.TranslateError(err)
+	}
+	return res, key.set(id)
 }
 
 // Row wraps a *sql.Row in order to translate errors
 type Row struct {
 	*sql.Row
 	TranslateError func(error) error
+	err            error
 }
 
-// Scan wraps sql.Row.Scan()
+// Scan wraps sql.Row.Scan(). It returns the error of the statement if it
+// could not be built
 func (r Row) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
 	return r.TranslateError(r.Row.Scan(dest...))
 }
 
 // QueryRow wraps *sql.DB.QueryRow()
-func (e *Engine) QueryRow(builder Builder) Row {
-	statement := builder.Build(e.dialect)
-	e.log(statement)
+func (e *Engine) QueryRow(builder Builder, args ...Args) Row {
+	return e.QueryRowContext(context.Background(), builder, args...)
+}
+
+// QueryRowContext wraps *sql.DB.QueryRowContext()
+func (e *Engine) QueryRowContext(ctx context.Context, builder Builder, args ...Args) Row {
+	statement, err := e.build(builder, args)
+	if err != nil {
+		return Row{TranslateError: e.TranslateError, err: err}
+	}
 	return Row{
-		e.db.QueryRow(statement.SQL(), statement.Bindings()...),
-		e.TranslateError,
+		Row:            e.runner().QueryRowContext(ctx, statement.SQL(), statement.Bindings()...),
+		TranslateError: e.TranslateError,
 	}
 }
 
 // Query wraps *sql.DB.Query()
-func (e *Engine) Query(builder Builder) (*sql.Rows, error) {
-	statement := builder.Build(e.dialect)
-	e.log(statement)
-	rows, err := e.db.Query(statement.SQL(), statement.Bindings()...)
+func (e *Engine) Query(builder Builder, args ...Args) (*sql.Rows, error) {
+	return e.QueryContext(context.Background(), builder, args...)
+}
+
+// QueryContext wraps *sql.DB.QueryContext()
+func (e *Engine) QueryContext(ctx context.Context, builder Builder, args ...Args) (*sql.Rows, error) {
+	statement, err := e.build(builder, args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := e.runner().QueryContext(ctx, statement.SQL(), statement.Bindings()...)
 	return rows, e.TranslateError(err)
 }
 
 // Get maps the single row to a model
-func (e *Engine) Get(builder Builder, model interface{}) error {
-	statement := builder.Build(e.dialect)
-	e.log(statement)
+func (e *Engine) Get(builder Builder, model interface{}, args ...Args) error {
+	return e.GetContext(context.Background(), builder, model, args...)
+}
+
+// GetContext maps the single row to a model using the given context
+func (e *Engine) GetContext(ctx context.Context, builder Builder, model interface{}, args ...Args) error {
+	statement, err := e.build(builder, args)
+	if err != nil {
+		return err
+	}
 	return e.TranslateError(
-		e.db.Get(model, statement.SQL(), statement.Bindings()...))
+		e.runner().GetContext(ctx, model, statement.SQL(), statement.Bindings()...))
 }
 
 // Select maps multiple rows to a model array
-func (e *Engine) Select(builder Builder, model interface{}) error {
-	statement := builder.Build(e.dialect)
-	e.log(statement)
+func (e *Engine) Select(builder Builder, model interface{}, args ...Args) error {
+	return e.SelectContext(context.Background(), builder, model, args...)
+}
+
+// SelectContext maps multiple rows to a model array using the given context
+func (e *Engine) SelectContext(ctx context.Context, builder Builder, model interface{}, args ...Args) error {
+	statement, err := e.build(builder, args)
+	if err != nil {
+		return err
+	}
 	return e.TranslateError(
-		e.db.Select(model, statement.SQL(), statement.Bindings()...))
+		e.runner().SelectContext(ctx, model, statement.SQL(), statement.Bindings()...))
 }
 
 // DB returns sql.DB of wrapped engine connection
@@ -143,8 +308,11 @@ func (e *Engine) Ping() error {
 	return e.db.Ping()
 }
 
-// Close closes the sqlx db connection
+// Close closes the cached prepared statements and the sqlx db connection
 func (e *Engine) Close() error {
+	if e.stmts != nil {
+		e.stmts.Close()
+	}
 	return e.db.Close()
 }
 
@@ -160,17 +328,59 @@ func (e *Engine) Dsn() string {
 
 // Begin begins a transaction and return a *qb.Tx
 func (e *Engine) Begin() (*Tx, error) {
-	tx, err := e.db.Beginx()
+	return e.BeginTx(context.Background(), nil)
+}
+
+// BeginTx begins a transaction with the given context and options, and
+// return a *qb.Tx.
+// The context is used until the transaction is committed or rolled back.
+func (e *Engine) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := e.db.BeginTxx(ctx, opts)
+	if err != nil {
+		return nil, e.TranslateError(err)
+	}
+	t := &Tx{engine: e, tx: tx}
+	if e.stmts != nil {
+		t.stmts = newStmtCache(tx, e.stmts.size, &e.stmtsStats)
+	}
+	return t, nil
+}
+
+// beginConnTx begins a transaction on a connection of the engine pool, so
+// the connection settings changed outside the transaction apply to it
+func (e *Engine) beginConnTx(ctx context.Context, conn *sql.Conn) (*Tx, error) {
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
-		return nil, e.dialect.WrapError(err)
+		return nil, e.TranslateError(err)
+	}
+	t := &Tx{engine: e, tx: &sqlx.Tx{Tx: tx, Mapper: e.db.Mapper}}
+	if e.stmts != nil {
+		t.stmts = newStmtCache(t.tx, e.stmts.size, &e.stmtsStats)
 	}
-	return &Tx{e, tx}, nil
+	return t, nil
 }
 
 // Tx is an in-progress database transaction
 type Tx struct {
 	engine *Engine
 	tx     *sqlx.Tx
+	stmts  *stmtCache
+}
+
+// runner returns the prepared statement cache of the transaction if
+// enabled, or the transaction
+func (tx *Tx) runner() runner {
+	if tx.stmts != nil {
+		return tx.stmts
+	}
+	return tx.tx
+}
+
+// closeStmts closes the cached prepared statements of the transaction
+func (tx *Tx) closeStmts() {
+	if tx.stmts != nil {
+		tx.stmts.Close()
+	}
 }
 
 // Tx returns the underlying *sqlx.Tx
@@ -178,54 +388,87 @@ func (tx *Tx) Tx() *sqlx.Tx {
 	return tx.tx
 }
 
-// Commit commits the transaction
+// Commit commits the transaction. Its error is translated into a qb.Error
 func (tx *Tx) Commit() error {
-	return tx.tx.Commit()
+	tx.closeStmts()
+	return tx.engine.TranslateError(tx.tx.Commit())
 }
 
-// Rollback aborts the transaction
+// Rollback aborts the transaction. Its error is translated into a qb.Error
 func (tx *Tx) Rollback() error {
-	return tx.tx.Rollback()
+	tx.closeStmts()
+	return tx.engine.TranslateError(tx.tx.Rollback())
 }
 
 // Exec executes insert & update type queries and returns sql.Result and error
-func (tx *Tx) Exec(builder Builder) (sql.Result, error) {
-	statement := builder.Build(tx.engine.dialect)
-	tx.engine.log(statement)
-	res, err := tx.tx.Exec(statement.SQL(), statement.Bindings()...)
-	return res, tx.engine.TranslateError(err)
+func (tx *Tx) Exec(builder Builder, args ...Args) (sql.Result, error) {
+	return tx.ExecContext(context.Background(), builder, args...)
 }
 
-// QueryRow wraps *sql.DB.QueryRow()
-func (tx *Tx) QueryRow(builder Builder) Row {
-	statement := builder.Build(tx.engine.dialect)
-	tx.engine.log(statement)
+// ExecContext executes insert & update type queries using the given context
+// and returns sql.Result and error
+func (tx *Tx) ExecContext(ctx context.Context, builder Builder, args ...Args) (sql.Result, error) {
+	return tx.engine.exec(ctx, tx.runner(), builder, args)
+}
+
+// QueryRow wraps *sql.Tx.QueryRow()
+func (tx *Tx) QueryRow(builder Builder, args ...Args) Row {
+	return tx.QueryRowContext(context.Background(), builder, args...)
+}
+
+// QueryRowContext wraps *sql.Tx.QueryRowContext()
+func (tx *Tx) QueryRowContext(ctx context.Context, builder Builder, args ...Args) Row {
+	statement, err := tx.engine.build(builder, args)
+	if err != nil {
+		return Row{TranslateError: tx.engine.TranslateError, err: err}
+	}
 	return Row{
-		tx.tx.QueryRow(statement.SQL(), statement.Bindings()...),
-		tx.engine.TranslateError,
+		Row:            tx.runner().QueryRowContext(ctx, statement.SQL(), statement.Bindings()...),
+		TranslateError: tx.engine.TranslateError,
 	}
 }
 
-// Query wraps *sql.DB.Query()
-func (tx *Tx) Query(builder Builder) (*sql.Rows, error) {
-	statement := builder.Build(tx.engine.dialect)
-	tx.engine.log(statement)
-	rows, err := tx.tx.Query(statement.SQL(), statement.Bindings()...)
+// Query wraps *sql.Tx.Query()
+func (tx *Tx) Query(builder Builder, args ...Args) (*sql.Rows, error) {
+	return tx.QueryContext(context.Background(), builder, args...)
+}
+
+// QueryContext wraps *sql.Tx.QueryContext()
+func (tx *Tx) QueryContext(ctx context.Context, builder Builder, args ...Args) (*sql.Rows, error) {
+	statement, err := tx.engine.build(builder, args)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.runner().QueryContext(ctx, statement.SQL(), statement.Bindings()...)
 	return rows, tx.engine.TranslateError(err)
 }
 
 // Get maps the single row to a model
-func (tx *Tx) Get(builder Builder, model interface{}) error {
-	statement := builder.Build(tx.engine.dialect)
-	tx.engine.log(statement)
+func (tx *Tx) Get(builder Builder, model interface{}, args ...Args) error {
+	return tx.GetContext(context.Background(), builder, model, args...)
+}
+
+// GetContext maps the single row to a model using the given context
+func (tx *Tx) GetContext(ctx context.Context, builder Builder, model interface{}, args ...Args) error {
+	statement, err := tx.engine.build(builder, args)
+	if err != nil {
+		return err
+	}
 	return tx.engine.TranslateError(
-		tx.tx.Get(model, statement.SQL(), statement.Bindings()...))
+		tx.runner().GetContext(ctx, model, statement.SQL(), statement.Bindings()...))
 }
 
 // Select maps multiple rows to a model array
-func (tx *Tx) Select(builder Builder, model interface{}) error {
-	statement := builder.Build(tx.engine.dialect)
-	tx.engine.log(statement)
+func (tx *Tx) Select(builder Builder, model interface{}, args ...Args) error {
+	return tx.SelectContext(context.Background(), builder, model, args...)
+}
+
+// SelectContext maps multiple rows to a model array using the given context
+func (tx *Tx) SelectContext(ctx context.Context, builder Builder, model interface{}, args ...Args) error {
+	statement, err := tx.engine.build(builder, args)
+	if err != nil {
+		return err
+	}
 	return tx.engine.TranslateError(
-		tx.tx.Select(model, statement.SQL(), statement.Bindings()...))
+		tx.runner().SelectContext(ctx, model, statement.SQL(), statement.Bindings()...))
 }
@@ -1,6 +1,9 @@
 package qb_test
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"testing"
 
 	_ "github.com/mattn/go-sqlite3"
@@ -67,6 +70,27 @@ func TestEngineFail(t *testing.T) {
 	assert.NotNil(t, err)
 }
 
+func TestEngineBuildError(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+
+	usersTable := qb.Table(
+		"users",
+		qb.Column("full_name", qb.Varchar()).NotNull(),
+	)
+
+	_, err = engine.Exec(qb.Update(usersTable).Values(map[string]interface{}{
+		"full_name": "Al Pacino",
+	}).Where(usersTable.C("nickname").Eq("Al")))
+	qbErr, ok := err.(qb.Error)
+	assert.True(t, ok)
+	assert.Equal(t, qb.ErrInterface, qbErr.Code)
+
+	_, err = engine.Exec(qb.MetaData().Table("unknown"))
+	assert.NotNil(t, err)
+}
+
 func TestTx(t *testing.T) {
 	engine, err := qb.New("sqlite3", ":memory:")
 	assert.Nil(t, err)
@@ -148,6 +172,46 @@ func TestTx(t *testing.T) {
 
 }
 
+func TestTxErrors(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+
+	tx, err := engine.Begin()
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit())
+
+	err = tx.Rollback()
+	_, ok := err.(qb.Error)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, sql.ErrTxDone))
+
+	err = tx.Commit()
+	_, ok = err.(qb.Error)
+	assert.True(t, ok)
+	assert.True(t, errors.Is(err, sql.ErrTxDone))
+}
+
+func TestEngineNotFound(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+
+	sel := qb.Select(qb.SQLText("1")).Where(qb.SQLText("1 = 0"))
+
+	var one int
+	err = engine.Get(sel, &one)
+	assert.True(t, errors.Is(err, qb.ErrNotFound))
+
+	err = engine.QueryRow(sel).Scan(&one)
+	assert.True(t, errors.Is(err, qb.ErrNotFound))
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+
+	err = engine.Get(qb.Select(qb.SQLText("1")), &one)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, one)
+}
+
 func TestTxBeginError(t *testing.T) {
 	engine, err := qb.New("sqlite3", "file:///dev/null?_txlock=exclusive")
 	assert.Nil(t, err)
@@ -187,3 +251,176 @@ func TestEngineSelect(t *testing.T) {
 	assert.Equal(t, 1, len(s))
 	assert.Equal(t, 1, s[0].Value)
 }
+
+func TestEngineContext(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+
+	sel := qb.Select(qb.SQLText("1 AS value"))
+	var s struct {
+		Value int `db:"value"`
+	}
+	var sl []struct {
+		Value int `db:"value"`
+	}
+
+	ctx := context.Background()
+
+	_, err = engine.ExecContext(ctx, sel)
+	assert.Nil(t, err)
+	rows, err := engine.QueryContext(ctx, sel)
+	assert.Nil(t, err)
+	rows.Close()
+	var value int
+	assert.Nil(t, engine.QueryRowContext(ctx, sel).Scan(&value))
+	assert.Equal(t, 1, value)
+	assert.Nil(t, engine.GetContext(ctx, sel, &s))
+	assert.Equal(t, 1, s.Value)
+	assert.Nil(t, engine.SelectContext(ctx, sel, &sl))
+	assert.Equal(t, 1, len(sl))
+
+	tx, err := engine.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	assert.Nil(t, err)
+	_, err = tx.ExecContext(ctx, sel)
+	assert.Nil(t, err)
+	rows, err = tx.QueryContext(ctx, sel)
+	assert.Nil(t, err)
+	rows.Close()
+	assert.Nil(t, tx.QueryRowContext(ctx, sel).Scan(&value))
+	assert.Nil(t, tx.GetContext(ctx, sel, &s))
+	assert.Nil(t, tx.SelectContext(ctx, sel, &sl))
+	assert.Nil(t, tx.Rollback())
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+
+	assertCanceled := func(err error) {
+		if assert.IsType(t, qb.Error{}, err) {
+			assert.Equal(t, qb.ErrCanceled, err.(qb.Error).Code)
+			assert.Equal(t, context.Canceled, err.(qb.Error).Orig)
+		}
+	}
+
+	_, err = engine.ExecContext(canceledCtx, sel)
+	assertCanceled(err)
+	_, err = engine.QueryContext(canceledCtx, sel)
+	assertCanceled(err)
+	assertCanceled(engine.QueryRowContext(canceledCtx, sel).Scan(&value))
+	assertCanceled(engine.GetContext(canceledCtx, sel, &s))
+	assertCanceled(engine.SelectContext(canceledCtx, sel, &sl))
+	_, err = engine.BeginTx(canceledCtx, nil)
+	assertCanceled(err)
+
+	tx, err = engine.Begin()
+	assert.Nil(t, err)
+	defer tx.Rollback()
+	_, err = tx.ExecContext(canceledCtx, sel)
+	assertCanceled(err)
+	_, err = tx.QueryContext(canceledCtx, sel)
+	assertCanceled(err)
+	assertCanceled(tx.QueryRowContext(canceledCtx, sel).Scan(&value))
+	assertCanceled(tx.GetContext(canceledCtx, sel, &s))
+	assertCanceled(tx.SelectContext(canceledCtx, sel, &sl))
+}
+
+func TestEngineStmtCache(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+	engine.DB().SetMaxOpenConns(1)
+	engine.SetStmtCacheSize(10)
+
+	usersTable := qb.Table(
+		"users",
+		qb.Column("id", qb.Int()).PrimaryKey(),
+		qb.Column("full_name", qb.Varchar()).NotNull(),
+	)
+	_, err = engine.DB().Exec(usersTable.Create(engine.Dialect()))
+	assert.Nil(t, err)
+
+	for i := 1; i <= 3; i++ {
+		_, err = engine.Exec(usersTable.Insert().Values(map[string]interface{}{"id": i, "full_name": "Al Pacino"}))
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, qb.StmtCacheStats{Hits: 2, Misses: 1}, engine.StmtCacheStats())
+
+	var count int
+	sel := qb.Select(qb.Count(usersTable.C("id"))).From(usersTable)
+	assert.Nil(t, engine.Get(sel, &count))
+	assert.Nil(t, engine.QueryRow(sel).Scan(&count))
+	assert.Equal(t, 3, count)
+	assert.Equal(t, qb.StmtCacheStats{Hits: 3, Misses: 2}, engine.StmtCacheStats())
+
+	// a transaction has its own cache
+	tx, err := engine.Begin()
+	assert.Nil(t, err)
+	_, err = tx.Exec(usersTable.Delete().Where(usersTable.C("id").Eq(1)))
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Get(sel, &count))
+	assert.Nil(t, tx.Get(sel, &count))
+	assert.Equal(t, 2, count)
+	assert.Nil(t, tx.Commit())
+	assert.Equal(t, qb.StmtCacheStats{Hits: 4, Misses: 4}, engine.StmtCacheStats())
+
+	var names []string
+	assert.Nil(t, engine.Select(qb.Select(usersTable.C("full_name")).From(usersTable), &names))
+	assert.Equal(t, []string{"Al Pacino", "Al Pacino"}, names)
+
+	engine.SetStmtCacheSize(0)
+	assert.Nil(t, engine.Get(sel, &count))
+	assert.Equal(t, qb.StmtCacheStats{Hits: 4, Misses: 5}, engine.StmtCacheStats())
+}
+
+func TestEngineArgs(t *testing.T) {
+	engine, err := qb.New("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer engine.Close()
+	engine.DB().SetMaxOpenConns(1)
+
+	usersTable := qb.Table(
+		"users",
+		qb.Column("name", qb.Varchar()).NotNull(),
+		qb.Column("age", qb.Int()).NotNull(),
+	)
+	_, err = engine.DB().Exec(usersTable.Create(engine.Dialect()))
+	assert.Nil(t, err)
+
+	ins := usersTable.Insert().Values(map[string]interface{}{"name": qb.Param("name"), "age": qb.Param("age")})
+	_, err = engine.Exec(ins, qb.Args{"name": "Al Pacino", "age": 79}, qb.Args{"age": 17})
+	assert.Nil(t, err)
+	tx, err := engine.Begin()
+	assert.Nil(t, err)
+	_, err = tx.Exec(ins, qb.Args{"name": "Robert De Niro", "age": 76})
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit())
+
+	sel := qb.Select(usersTable.C("name")).
+		From(usersTable).
+		Where(qb.Or(usersTable.C("age").Gt(qb.Param("min_age")), usersTable.C("name").Eq(qb.Param("name"))))
+	var names []string
+	assert.Nil(t, engine.Select(sel, &names, qb.Args{"min_age": 18, "name": "Al Pacino"}))
+	assert.Equal(t, []string{"Al Pacino", "Robert De Niro"}, names)
+
+	rows, err := engine.Query(sel, qb.Args{"min_age": 18, "name": ""})
+	assert.Nil(t, err)
+	assert.True(t, rows.Next())
+	assert.False(t, rows.Next())
+
+	var count int
+	countSel := qb.Select(qb.Count(usersTable.C("name"))).
+		From(usersTable).
+		Where(qb.And(usersTable.C("age").Gt(qb.Param("age")), usersTable.C("age").Lt(qb.Param("age"))))
+	assert.Nil(t, engine.QueryRow(countSel, qb.Args{"age": 17}).Scan(&count))
+	assert.Equal(t, 0, count)
+
+	// an unbound parameter is reported before running the query
+	err = engine.Get(sel, &names, qb.Args{"min_age": 18})
+	assert.Equal(t, qb.ErrInterface, err.(qb.Error).Code)
+	assert.EqualError(t, err, "Interface error: Missing value of the parameter name")
+	_, err = engine.Query(sel)
+	assert.NotNil(t, err)
+	assert.NotNil(t, engine.QueryRow(sel).Scan(&count))
+	_, err = engine.Exec(ins, qb.Args{"name": "Al Pacino"})
+	assert.EqualError(t, err, "Interface error: Missing value of the parameter age")
+}
@@ -1,5 +1,11 @@
 package qb
 
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
 // ErrorCode discriminates the types of errors that qb wraps, mainly the
 // constraint errors
 // The different kind of errors are based on the python dbapi errors
@@ -17,8 +23,15 @@ const (
 	ErrDatabase ErrorCode = 1 << 9
 )
 
+// Interface error codes are in bits 5 to 7
+const (
+	// ErrCanceled is when a query was aborted because its context was
+	// canceled or its deadline exceeded
+	ErrCanceled ErrorCode = ErrInterface | (iota + 1<<5)
+)
+
 // Database error codes are in bits 5 to 7, leaving bits 0 to 4 for detailed
-// codes in a later version
+// codes
 const (
 	// ErrData is for errors that are due to problems with the processed data
 	// like division by zero, numeric value out of range, etc.
@@ -30,7 +43,8 @@ const (
 	// during processing, etc.
 	ErrOperational
 	// ErrIntegrity is when the relational integrity of the database is
-	// affected, e.g. a foreign key check fails
+	// affected, e.g. a foreign key check fails. The dialects detail it with
+	// the integrity error codes in Error.Detail when they can
 	ErrIntegrity
 	// ErrInternal is when the database encounters an internal error, e.g. the
 	// cursor is not valid anymore, the transaction is out of sync, etc.
@@ -46,6 +60,80 @@ const (
 	ErrNotSupported
 )
 
+// Detailed error codes are in bits 0 to 4. They are the Detail of the
+// errors, whose Code stays the one of their category: the integrity error
+// codes detail ErrIntegrity
+const (
+	// ErrUniqueViolation is when a unique or primary key constraint fails
+	ErrUniqueViolation ErrorCode = iota + 1
+	// ErrForeignKeyViolation is when a foreign key constraint fails
+	ErrForeignKeyViolation
+	// ErrNotNullViolation is when a null value is stored in a NOT NULL column
+	ErrNotNullViolation
+	// ErrCheckViolation is when a check constraint fails
+	ErrCheckViolation
+)
+
+// Sentinel errors, matched by the qb.Error of their category with errors.Is
+var (
+	// ErrIntegrityViolation matches all the integrity errors, including the
+	// detailed ones
+	ErrIntegrityViolation error = ErrIntegrity
+	// ErrNotFound matches the sql.ErrNoRows errors wrapped by Row.Scan or
+	// Get, when the query returned no rows
+	ErrNotFound = errors.New("Not found")
+)
+
+// Error describes the kind of errors having the code, so the codes can be
+// matched by errors.Is:
+//
+//	if errors.Is(err, qb.ErrUniqueViolation) {
+//		...
+//	}
+func (err ErrorCode) Error() string {
+	if description := err.description(); description != "" {
+		return description
+	}
+	return fmt.Sprintf("Error code %d", int(err))
+}
+
+// description returns the description of the code, or an empty string if
+// the code is unknown
+func (err ErrorCode) description() string {
+	switch err {
+	case ErrAny:
+		return "Uncategorized error"
+	case ErrInterface:
+		return "Interface error"
+	case ErrCanceled:
+		return "Query canceled"
+	case ErrDatabase:
+		return "Database error"
+	case ErrData:
+		return "Database data error"
+	case ErrOperational:
+		return "Database operational error"
+	case ErrIntegrity:
+		return "Database integrity error"
+	case ErrUniqueViolation:
+		return "Database unique violation"
+	case ErrForeignKeyViolation:
+		return "Database foreign key violation"
+	case ErrNotNullViolation:
+		return "Database not null violation"
+	case ErrCheckViolation:
+		return "Database check violation"
+	case ErrInternal:
+		return "Database internal error"
+	case ErrProgramming:
+		return "Database programming error"
+	case ErrNotSupported:
+		return "Not supported"
+	default:
+		return ""
+	}
+}
+
 // IsInterfaceError returns true if the error is a Interface error
 func (err ErrorCode) IsInterfaceError() bool {
 	return err&ErrInterface != 0
@@ -58,8 +146,13 @@ func (err ErrorCode) IsDatabaseError() bool {
 
 // Error wraps driver errors. It helps handling constraint error in
 // a generic way, while still giving access to the original error
+// Detail is a detailed code of the error, like ErrUniqueViolation for an
+// ErrIntegrity, or ErrAny if the dialect could not tell it
+// Table, Column and Constraint are filled by the dialects when the driver
+// error tells them, mainly for the integrity errors
 type Error struct {
 	Code       ErrorCode
+	Detail     ErrorCode
 	Orig       error // The native error from the driver
 	Table      string
 	Column     string
@@ -67,24 +160,42 @@ type Error struct {
 }
 
 func (err Error) Error() string {
-	switch err.Code {
-	case ErrAny:
-		return "Uncategorized error: " + err.Orig.Error()
+	code := err.Code
+	if err.Detail != ErrAny {
+		code = err.Detail
+	}
+	if description := code.description(); description != "" {
+		return description + ": " + err.Orig.Error()
+	}
+	return err.Orig.Error()
+}
+
+// Unwrap returns the native error from the driver
+func (err Error) Unwrap() error {
+	return err.Orig
+}
+
+// Is tells if the error matches target, which is either:
+//   - an ErrorCode, matching the errors having the code or the detailed code
+//     (e.g. ErrIntegrity or ErrUniqueViolation). The ErrInterface and
+//     ErrDatabase masks match all the errors of their kind.
+//   - ErrNotFound, matching the sql.ErrNoRows errors
+//
+// The native error is matched by errors.Is through Unwrap.
+func (err Error) Is(target error) bool {
+	if target == ErrNotFound {
+		return errors.Is(err.Orig, sql.ErrNoRows)
+	}
+	code, ok := target.(ErrorCode)
+	if !ok {
+		return false
+	}
+	switch code {
 	case ErrInterface:
-		return "Interface error: " + err.Orig.Error()
+		return err.Code.IsInterfaceError()
 	case ErrDatabase:
-		return "Database error: " + err.Orig.Error()
-	case ErrData:
-		return "Database data error: " + err.Orig.Error()
-	case ErrOperational:
-		return "Database operational error: " + err.Orig.Error()
-	case ErrIntegrity:
-		return "Database integrity error: " + err.Orig.Error()
-	case ErrInternal:
-		return "Database internal error: " + err.Orig.Error()
-	case ErrProgramming:
-		return "Database programming error: " + err.Orig.Error()
+		return err.Code.IsDatabaseError()
 	default:
-		return err.Orig.Error()
+		return err.Code == code || (err.Detail != ErrAny && err.Detail == code)
 	}
 }
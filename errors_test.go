@@ -1,7 +1,9 @@
 package qb
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,23 +16,42 @@ func TestErrorMessages(t *testing.T) {
 	}{
 		{ErrAny, "Uncategorized error: xxx"},
 		{ErrInterface, "Interface error: xxx"},
+		{ErrCanceled, "Query canceled: xxx"},
 		{ErrDatabase, "Database error: xxx"},
 		{ErrData, "Database data error: xxx"},
 		{ErrOperational, "Database operational error: xxx"},
 		{ErrIntegrity, "Database integrity error: xxx"},
 		{ErrInternal, "Database internal error: xxx"},
 		{ErrProgramming, "Database programming error: xxx"},
+		{ErrNotSupported, "Not supported: xxx"},
 		{54, "xxx"},
 	}
 	for _, tt := range tests {
 		assert.Equal(t, tt.expected, Error{Code: tt.code, Orig: errors.New("xxx")}.Error())
 	}
+
+	details := []struct {
+		detail   ErrorCode
+		expected string
+	}{
+		{ErrUniqueViolation, "Database unique violation: xxx"},
+		{ErrForeignKeyViolation, "Database foreign key violation: xxx"},
+		{ErrNotNullViolation, "Database not null violation: xxx"},
+		{ErrCheckViolation, "Database check violation: xxx"},
+	}
+	for _, tt := range details {
+		err := Error{Code: ErrIntegrity, Detail: tt.detail, Orig: errors.New("xxx")}
+		assert.Equal(t, tt.expected, err.Error())
+	}
 }
 
 func TestErrorCode(t *testing.T) {
 	assert.True(t, ErrInterface.IsInterfaceError())
 	assert.False(t, ErrInterface.IsDatabaseError())
 
+	assert.True(t, ErrCanceled.IsInterfaceError())
+	assert.False(t, ErrCanceled.IsDatabaseError())
+
 	assert.True(t, ErrDatabase.IsDatabaseError())
 	assert.False(t, ErrDatabase.IsInterfaceError())
 
@@ -48,4 +69,50 @@ func TestErrorCode(t *testing.T) {
 
 	assert.True(t, ErrProgramming.IsDatabaseError())
 	assert.False(t, ErrProgramming.IsInterfaceError())
+
+	for _, code := range []ErrorCode{
+		ErrUniqueViolation,
+		ErrForeignKeyViolation,
+		ErrNotNullViolation,
+		ErrCheckViolation,
+	} {
+		// the detailed codes are in bits 0 to 4
+		assert.True(t, code > ErrAny && code < 1<<5)
+	}
+}
+
+func TestErrorIs(t *testing.T) {
+	orig := errors.New("xxx")
+	err := fmt.Errorf("insert failed: %w", Error{Code: ErrIntegrity, Detail: ErrUniqueViolation, Orig: orig})
+
+	assert.True(t, errors.Is(err, orig))
+	assert.True(t, errors.Is(err, ErrUniqueViolation))
+	assert.True(t, errors.Is(err, ErrIntegrity))
+	assert.True(t, errors.Is(err, ErrIntegrityViolation))
+	assert.True(t, errors.Is(err, ErrDatabase))
+	assert.False(t, errors.Is(err, ErrInterface))
+	assert.False(t, errors.Is(err, ErrForeignKeyViolation))
+	assert.False(t, errors.Is(err, ErrNotFound))
+
+	var qbErr Error
+	assert.True(t, errors.As(err, &qbErr))
+	assert.Equal(t, ErrIntegrity, qbErr.Code)
+	assert.Equal(t, ErrUniqueViolation, qbErr.Detail)
+
+	err = Error{Code: ErrCanceled, Orig: orig}
+	assert.True(t, errors.Is(err, ErrCanceled))
+	assert.True(t, errors.Is(err, ErrInterface))
+	assert.False(t, errors.Is(err, ErrDatabase))
+
+	err = Error{Code: ErrIntegrity, Orig: orig}
+	assert.True(t, errors.Is(err, ErrIntegrity))
+	assert.False(t, errors.Is(err, ErrUniqueViolation))
+
+	err = Error{Orig: sql.ErrNoRows}
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+	assert.False(t, errors.Is(err, ErrIntegrityViolation))
+
+	assert.Equal(t, "Database integrity error", ErrIntegrity.Error())
+	assert.Equal(t, "Error code 54", ErrorCode(54).Error())
 }
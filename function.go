@@ -0,0 +1,190 @@
+package qb
+
+import "fmt"
+
+// Func generates a call of a SQL function. The arguments that are not
+// clauses are bound values
+// Func("DATE_TRUNC", "day", events.C("created_at"))
+func Func(name string, args ...interface{}) FuncClause {
+	clauses := make([]Clause, len(args))
+	for i, arg := range args {
+		clauses[i] = GetClauseFrom(arg)
+	}
+	return FuncClause{Name: name, Args: clauses}
+}
+
+// FuncClause is a call of a SQL function.
+// The portable functions COALESCE, LOWER, UPPER, NOW, LENGTH and CONCAT are
+// compiled by each dialect to their native spelling
+type FuncClause struct {
+	Name string
+	Args []Clause
+}
+
+// Accept calls the compiler VisitFunc function
+func (c FuncClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitFunc(context, c)
+}
+
+// Coalesce generates a COALESCE(args...) function, returning the first
+// argument that is not null
+func Coalesce(args ...interface{}) FuncClause {
+	return Func("COALESCE", args...)
+}
+
+// Lower generates a LOWER(arg) function
+func Lower(arg interface{}) FuncClause {
+	return Func("LOWER", arg)
+}
+
+// Upper generates a UPPER(arg) function
+func Upper(arg interface{}) FuncClause {
+	return Func("UPPER", arg)
+}
+
+// Now generates the current timestamp function
+func Now() FuncClause {
+	return Func("NOW")
+}
+
+// Length generates a function returning the number of characters of a
+// string
+func Length(arg interface{}) FuncClause {
+	return Func("LENGTH", arg)
+}
+
+// Concat generates the concatenation of strings, with the || operator or
+// the CONCAT function depending on the dialect
+func Concat(args ...interface{}) FuncClause {
+	return Func("CONCAT", args...)
+}
+
+// Cast generates a CAST(arg AS type) expression
+func Cast(arg interface{}, t TypeElem) CastClause {
+	return CastClause{GetClauseFrom(arg), t}
+}
+
+// CastClause is the conversion of an expression to a type
+type CastClause struct {
+	Clause Clause
+	Type   TypeElem
+}
+
+// Accept calls the compiler VisitCast function
+func (c CastClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitCast(context, c)
+}
+
+// Arithmetic generates an arithmetic expression. The right value is bound
+// if it is not a clause
+func Arithmetic(left Clause, op string, right interface{}) ArithmeticClause {
+	return ArithmeticClause{left, op, GetClauseFrom(right)}
+}
+
+// Add generates a left + value expression
+func Add(left Clause, value interface{}) ArithmeticClause {
+	return Arithmetic(left, "+", value)
+}
+
+// Sub generates a left - value expression
+func Sub(left Clause, value interface{}) ArithmeticClause {
+	return Arithmetic(left, "-", value)
+}
+
+// Mul generates a left * value expression
+func Mul(left Clause, value interface{}) ArithmeticClause {
+	return Arithmetic(left, "*", value)
+}
+
+// Div generates a left / value expression
+func Div(left Clause, value interface{}) ArithmeticClause {
+	return Arithmetic(left, "/", value)
+}
+
+// Mod generates a left % value expression
+func Mod(left Clause, value interface{}) ArithmeticClause {
+	return Arithmetic(left, "%", value)
+}
+
+// ArithmeticClause is an arithmetic expression: LEFT <op> RIGHT
+type ArithmeticClause struct {
+	Left  Clause
+	Op    string
+	Right Clause
+}
+
+// Accept compiles the expression as a binary expression, the operands being
+// parenthesized if they are arithmetic expressions too
+func (c ArithmeticClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitBinary(context, BinaryExpression(
+		parenthesizeArithmetic(c.Left),
+		c.Op,
+		parenthesizeArithmetic(c.Right),
+	))
+}
+
+// parenthesizedClause is a clause compiled between parenthesis
+type parenthesizedClause struct {
+	clause Clause
+}
+
+func (c parenthesizedClause) Accept(context *CompilerContext) string {
+	return fmt.Sprintf("(%s)", c.clause.Accept(context))
+}
+
+func parenthesizeArithmetic(clause Clause) Clause {
+	if _, ok := clause.(ArithmeticClause); ok {
+		return parenthesizedClause{clause}
+	}
+	return clause
+}
+
+// Add wraps the Add(left ArithmeticClause, value interface{})
+func (c ArithmeticClause) Add(value interface{}) ArithmeticClause {
+	return Add(c, value)
+}
+
+// Sub wraps the Sub(left ArithmeticClause, value interface{})
+func (c ArithmeticClause) Sub(value interface{}) ArithmeticClause {
+	return Sub(c, value)
+}
+
+// Mul wraps the Mul(left ArithmeticClause, value interface{})
+func (c ArithmeticClause) Mul(value interface{}) ArithmeticClause {
+	return Mul(c, value)
+}
+
+// Div wraps the Div(left ArithmeticClause, value interface{})
+func (c ArithmeticClause) Div(value interface{}) ArithmeticClause {
+	return Div(c, value)
+}
+
+// Mod wraps the Mod(left ArithmeticClause, value interface{})
+func (c ArithmeticClause) Mod(value interface{}) ArithmeticClause {
+	return Mod(c, value)
+}
+
+// Add wraps the Add(fn FuncClause, value interface{})
+func (c FuncClause) Add(value interface{}) ArithmeticClause {
+	return Add(c, value)
+}
+
+// Sub wraps the Sub(fn FuncClause, value interface{})
+func (c FuncClause) Sub(value interface{}) ArithmeticClause {
+	return Sub(c, value)
+}
+
+// Mul wraps the Mul(fn FuncClause, value interface{})
+func (c FuncClause) Mul(value interface{}) ArithmeticClause {
+	return Mul(c, value)
+}
+
+// Div wraps the Div(fn FuncClause, value interface{})
+func (c FuncClause) Div(value interface{}) ArithmeticClause {
+	return Div(c, value)
+}
+
+// Mod wraps the Mod(fn FuncClause, value interface{})
+func (c FuncClause) Mod(value interface{}) ArithmeticClause {
+	return Mod(c, value)
+}
@@ -0,0 +1,86 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFunctions(t *testing.T) {
+	dialect := NewDefaultDialect()
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("first_name", Varchar()),
+		Column("last_name", Varchar()),
+		Column("nickname", Varchar()),
+	)
+
+	sel := Select(
+		Coalesce(users.C("nickname"), users.C("first_name"), "anonymous"),
+		Lower(users.C("first_name")),
+		Upper(users.C("last_name")),
+		Length(users.C("last_name")),
+		users.C("first_name").Concat(" ", users.C("last_name")),
+		Now(),
+		Func("MD5", users.C("first_name")),
+		Cast(users.C("id"), Varchar().Size(20)),
+	).From(users)
+	statement := sel.Build(dialect)
+	assert.Equal(t, "SELECT "+
+		"COALESCE(nickname, first_name, ?), "+
+		"LOWER(first_name), "+
+		"UPPER(last_name), "+
+		"CHAR_LENGTH(last_name), "+
+		"first_name || ? || last_name, "+
+		"CURRENT_TIMESTAMP, "+
+		"MD5(first_name), "+
+		"CAST(id AS VARCHAR(20))\n"+
+		"FROM users;", statement.SQL())
+	assert.Equal(t, []interface{}{"anonymous", " "}, statement.Bindings())
+
+	sel = Select(users.C("id")).From(users).Where(Eq(Lower(users.C("nickname")), "joe"))
+	statement = sel.Build(dialect)
+	assert.Equal(t, "SELECT id\nFROM users\nWHERE LOWER(nickname) = ?;", statement.SQL())
+	assert.Equal(t, []interface{}{"joe"}, statement.Bindings())
+}
+
+func TestArithmetic(t *testing.T) {
+	dialect := NewDefaultDialect()
+	items := Table(
+		"items",
+		Column("id", Int()).PrimaryKey(),
+		Column("price", Int()),
+		Column("quantity", Int()),
+		Column("discount", Int()),
+		Column("label", Varchar()),
+	)
+
+	sel := Select(
+		items.C("price").Mul(items.C("quantity")).Sub(items.C("discount")),
+		items.C("price").Add(1),
+		items.C("price").Div(items.C("quantity").Add(1)),
+		items.C("id").Mod(2),
+		Length(items.C("label")).Mul(2),
+	).From(items).Where(Gt(items.C("price").Mul(items.C("quantity")), 100))
+	statement := sel.Build(dialect)
+	assert.Equal(t, "SELECT "+
+		"(price * quantity) - discount, "+
+		"price + ?, "+
+		"price / (quantity + ?), "+
+		"id % ?, "+
+		"CHAR_LENGTH(label) * ?\n"+
+		"FROM items\n"+
+		"WHERE price * quantity > ?;", statement.SQL())
+	assert.Equal(t, []interface{}{1, 1, 2, 2, 100}, statement.Bindings())
+
+	upd := Update(items).Values(map[string]interface{}{"quantity": items.C("quantity").Sub(1)})
+	statement = upd.Build(dialect)
+	assert.Equal(t, "UPDATE items\nSET quantity = quantity - ?;", statement.SQL())
+	assert.Equal(t, []interface{}{1}, statement.Bindings())
+
+	sel = Select(Add(Count(items.C("id")), 1), Mod(Sum(items.C("quantity")), 2)).From(items)
+	statement = sel.Build(dialect)
+	assert.Equal(t, "SELECT COUNT(id) + ?, SUM(quantity) % ?\nFROM items;", statement.SQL())
+	assert.Equal(t, []interface{}{1, 2}, statement.Bindings())
+}
@@ -22,9 +22,14 @@ type IndexElem struct {
 	Table   string
 	Name    string
 	Columns []string
+	Unique  bool
 }
 
 // String returns the index element as an sql clause
 func (i IndexElem) String(dialect Dialect) string {
-	return fmt.Sprintf("CREATE INDEX %s ON %s(%s);", dialect.Escape(i.Name), dialect.Escape(i.Table), strings.Join(dialect.EscapeAll(i.Columns), ", "))
+	create := "CREATE INDEX"
+	if i.Unique {
+		create = "CREATE UNIQUE INDEX"
+	}
+	return fmt.Sprintf("%s %s ON %s(%s);", create, dialect.Escape(i.Name), dialect.Escape(i.Table), strings.Join(dialect.EscapeAll(i.Columns), ", "))
 }
@@ -1,30 +1,228 @@
 package qb
 
+import (
+	"fmt"
+	"reflect"
+)
+
 // Insert generates an insert statement and returns it
 // Insert(usersTable).Values(map[string]interface{}{"id": 1})
 func Insert(table TableElem) InsertStmt {
 	return InsertStmt{
 		table:     table,
-		values:    map[string]interface{}{},
 		returning: []ColumnElem{},
 	}
 }
 
 // InsertStmt is the base struct for any insert statements
 type InsertStmt struct {
-	table     TableElem
-	values    map[string]interface{}
-	returning []ColumnElem
+	with          []CTEClause
+	table         TableElem
+	values        ValueSet
+	rows          []ValueSet
+	columns       []ColumnElem
+	fromSelect    Selectable
+	defaultValues bool
+	returning     []ColumnElem
+	generatedKey  *generatedKey
+	err           error
+}
+
+// InsertReturning is an optional Dialect capability telling if the insert
+// statements can return the generated keys with a RETURNING clause. The
+// other dialects rely on LastInsertId
+type InsertReturning interface {
+	SupportsReturning() bool
 }
 
-// Values accepts map[string]interface{} and forms the values map of insert statement
+// BatchLimiter is an optional Dialect capability giving the limits of a
+// single statement of the database, so the rows inserted by
+// Engine.InsertBatch can be split in batches
+type BatchLimiter interface {
+	// BatchLimits returns the maximum number of bind parameters and the
+	// maximum size in bytes of a statement, 0 meaning no limit
+	BatchLimits(engine *Engine) (maxParams int, maxBytes int, err error)
+}
+
+// Values accepts map[string]interface{} and forms the values map of insert statement.
+// The values are inserted in the declaration order of the columns.
+// A value that is a clause, like users.C("score").Add(1), is compiled
+// instead of being bound.
 func (s InsertStmt) Values(values map[string]interface{}) InsertStmt {
-	for k, v := range values {
-		s.values[k] = v
+	s.values = s.values.SetMap(s.table, values)
+	return s
+}
+
+// Set sets the value of a column, the values being inserted in the order
+// they are set
+func (s InsertStmt) Set(column string, value interface{}) InsertStmt {
+	s.values = s.values.Set(column, value)
+	return s
+}
+
+// ValuesFrom forms the values map of insert statement from the fields of a
+// struct, mapped to the columns as with TableFromStruct.
+// The auto increment primary keys, the fields tagged 'readonly', and the
+// fields tagged 'omitempty' holding a zero value are skipped.
+// If model is a pointer, the generated primary key is written back into the
+// struct when the statement is executed by the Engine or a Tx
+func (s InsertStmt) ValuesFrom(model interface{}) InsertStmt {
+	values, err := structValues(model, func(field structField, value reflect.Value) bool {
+		return isGeneratedKey(field)
+	})
+	if err != nil {
+		s.err = err
+		return s
+	}
+	s.generatedKey = structGeneratedKey(model)
+	s.values = s.values.Merge(values)
+	return s
+}
+
+// ValuesList appends rows to the insert statement, so it inserts them all
+// at once. The values given to Values, if any, are the first row.
+// The columns are the ones of all the rows, a column missing from a row is
+// inserted as NULL.
+func (s InsertStmt) ValuesList(rows []map[string]interface{}) InsertStmt {
+	s.rows = s.rows[:len(s.rows):len(s.rows)]
+	for _, row := range rows {
+		s.rows = append(s.rows, ValueSet{}.SetMap(s.table, row))
+	}
+	return s
+}
+
+// ValuesListFrom appends the rows of a slice of structs or struct pointers,
+// with the fields mapped as with ValuesFrom. The generated keys are not
+// written back.
+func (s InsertStmt) ValuesListFrom(models interface{}) InsertStmt {
+	v := reflect.ValueOf(models)
+	if v.Kind() != reflect.Slice {
+		s.err = fmt.Errorf("Cannot get the values of %T, a slice is expected", models)
+		return s
+	}
+	rows := s.rows[:len(s.rows):len(s.rows)]
+	for i := 0; i < v.Len(); i++ {
+		values, err := structValues(v.Index(i).Interface(), func(field structField, value reflect.Value) bool {
+			return isGeneratedKey(field)
+		})
+		if err != nil {
+			s.err = err
+			return s
+		}
+		rows = append(rows, values)
+	}
+	s.rows = rows
+	return s
+}
+
+// rowList returns the rows inserted by the statement
+func (s InsertStmt) rowList() []ValueSet {
+	if len(s.values) == 0 {
+		return s.rows
+	}
+	return append([]ValueSet{s.values}, s.rows...)
+}
+
+// insertColumns returns the names of the columns of the rows, in the order
+// of their first appearance
+func insertColumns(rows []ValueSet) []string {
+	seen := map[string]bool{}
+	var cols []string
+	for _, row := range rows {
+		for _, cv := range row {
+			if !seen[cv.Column] {
+				seen[cv.Column] = true
+				cols = append(cols, cv.Column)
+			}
+		}
+	}
+	return cols
+}
+
+// bindSize estimates the size of a bound value in a statement
+func bindSize(value interface{}) int {
+	switch v := value.(type) {
+	case nil:
+		return 4
+	case string:
+		return len(v) + 2
+	case []byte:
+		return len(v) + 2
+	default:
+		return len(fmt.Sprint(v))
+	}
+}
+
+// Batches splits the rows of the insert statement in several statements,
+// each one having at most maxParams bind parameters and an estimated size of
+// at most maxBytes. A limit of 0 means no limit.
+func (s InsertStmt) Batches(maxParams int, maxBytes int) []InsertStmt {
+	rows := s.rowList()
+	cols := insertColumns(rows)
+	if len(rows) <= 1 || len(cols) == 0 {
+		return []InsertStmt{s}
+	}
+
+	header := len(s.table.Name) + 32
+	for _, col := range cols {
+		header += len(col) + 2
 	}
+
+	var batches []InsertStmt
+	start, size := 0, header
+	for i, row := range rows {
+		rowSize := 4
+		for _, col := range cols {
+			value, _ := row.Get(col)
+			rowSize += bindSize(value) + 2
+		}
+		full := (maxParams > 0 && (i-start+1)*len(cols) > maxParams) ||
+			(maxBytes > 0 && size+rowSize > maxBytes)
+		if full && i > start {
+			batches = append(batches, s.batch(rows[start:i]))
+			start, size = i, header
+		}
+		size += rowSize
+	}
+	return append(batches, s.batch(rows[start:]))
+}
+
+// batch returns a copy of the statement inserting some rows
+func (s InsertStmt) batch(rows []ValueSet) InsertStmt {
+	s.values = nil
+	s.rows = rows
+	return s
+}
+
+// Columns sets the columns filled by FromSelect
+func (s InsertStmt) Columns(cols ...ColumnElem) InsertStmt {
+	s.columns = append(s.columns[:len(s.columns):len(s.columns)], cols...)
 	return s
 }
 
+// FromSelect makes the statement insert the rows of a select statement,
+// as in INSERT INTO t(cols) SELECT ...
+// On postgres, it can be combined with Returning. On mysql, the
+// LastInsertId of the result is the first generated key, while on sqlite it
+// is the last one.
+func (s InsertStmt) FromSelect(sel Selectable) InsertStmt {
+	s.fromSelect = sel
+	return s
+}
+
+// DefaultValues makes the statement insert a single row of default values,
+// as in INSERT INTO t DEFAULT VALUES
+func (s InsertStmt) DefaultValues() InsertStmt {
+	s.defaultValues = true
+	return s
+}
+
+// IsDefaultValues returns true if the statement inserts a row of default
+// values
+func (s InsertStmt) IsDefaultValues() bool {
+	return s.defaultValues
+}
+
 // Returning accepts the column names as strings and forms the returning array of insert statement
 // NOTE: Please use it in only postgres dialect, otherwise it'll crash
 func (s InsertStmt) Returning(cols ...ColumnElem) InsertStmt {
@@ -34,6 +232,13 @@ func (s InsertStmt) Returning(cols ...ColumnElem) InsertStmt {
 	return s
 }
 
+// With appends common table expressions to the WITH clause of the insert
+// statement
+func (s InsertStmt) With(ctes ...CTEClause) InsertStmt {
+	s.with = append(s.with[:len(s.with):len(s.with)], ctes...)
+	return s
+}
+
 // Accept implements Clause.Accept
 func (s InsertStmt) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitInsert(context, s)
@@ -42,8 +247,23 @@ func (s InsertStmt) Accept(context *CompilerContext) string {
 // Build generates a statement out of InsertStmt object
 func (s InsertStmt) Build(dialect Dialect) *Stmt {
 	statement := Statement()
+	err := s.err
+	if err == nil && (s.fromSelect != nil || s.defaultValues) && len(s.rowList()) != 0 {
+		err = fmt.Errorf("Cannot insert values in %s with FromSelect or DefaultValues", s.table.Name)
+	} else if err == nil && s.fromSelect != nil && s.defaultValues {
+		err = fmt.Errorf("Cannot insert in %s with both FromSelect and DefaultValues", s.table.Name)
+	}
+	if err != nil {
+		statement.SetError(err)
+		return statement
+	}
 	context := NewCompilerContext(dialect)
-	statement.AddSQLClause(s.Accept(context))
+	sql := s.Accept(context)
+	if err := context.Err(); err != nil {
+		statement.SetError(err)
+		return statement
+	}
+	statement.AddSQLClause(sql)
 	statement.AddBinding(context.Binds...)
 
 	return statement
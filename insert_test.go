@@ -44,3 +44,123 @@ func TestInsert(t *testing.T) {
 	assert.Contains(t, sql, "RETURNING id, email")
 	assert.Contains(t, binds, "9883cf81-3b56-4151-ae4e-3903c5bc436d", "al@pacino.com")
 }
+
+func TestInsertValuesList(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	dialect := NewDefaultDialect()
+
+	stmt := Insert(users).
+		Values(map[string]interface{}{"id": 1, "email": "al@pacino.com"}).
+		ValuesList([]map[string]interface{}{
+			{"id": 2, "email": "robert@deniro.com"},
+			{"id": 3},
+		}).
+		Build(dialect)
+	assert.Equal(t, "INSERT INTO users(id, email)\nVALUES(?, ?), (?, ?), (?, ?);", stmt.SQL())
+	assert.Equal(t, []interface{}{1, "al@pacino.com", 2, "robert@deniro.com", 3, nil}, stmt.Bindings())
+
+	type user struct {
+		ID    int `qb:"pk;autoincrement"`
+		Email string
+	}
+	stmt = Insert(users).ValuesListFrom([]*user{{Email: "al@pacino.com"}, {Email: "robert@deniro.com"}}).Build(dialect)
+	assert.Equal(t, "INSERT INTO users(email)\nVALUES(?), (?);", stmt.SQL())
+	assert.Equal(t, []interface{}{"al@pacino.com", "robert@deniro.com"}, stmt.Bindings())
+
+	assert.NotNil(t, Insert(users).ValuesListFrom(user{}).Build(dialect).Err())
+	assert.NotNil(t, Insert(users).ValuesListFrom([]int{1}).Build(dialect).Err())
+}
+
+func insertRowMaps(s InsertStmt) []map[string]interface{} {
+	var rows []map[string]interface{}
+	for _, row := range s.rowList() {
+		rows = append(rows, row.Map())
+	}
+	return rows
+}
+
+func TestInsertBatches(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	var rows []map[string]interface{}
+	for i := 0; i < 10; i++ {
+		rows = append(rows, map[string]interface{}{"id": i, "email": "al@pacino.com"})
+	}
+	ins := Insert(users).ValuesList(rows)
+
+	assert.Equal(t, []InsertStmt{ins}, ins.Batches(0, 0))
+	single := Insert(users).Values(rows[0])
+	assert.Equal(t, []InsertStmt{single}, single.Batches(2, 10))
+
+	batches := ins.Batches(8, 0)
+	assert.Len(t, batches, 3)
+	assert.Equal(t, rows[0:4], insertRowMaps(batches[0]))
+	assert.Equal(t, rows[4:8], insertRowMaps(batches[1]))
+	assert.Equal(t, rows[8:], insertRowMaps(batches[2]))
+
+	// the header is estimated to 48 bytes, and a row to 24 bytes
+	batches = ins.Batches(0, 48+3*24)
+	assert.Len(t, batches, 4)
+	assert.Len(t, batches[0].rowList(), 3)
+	assert.Len(t, batches[3].rowList(), 1)
+
+	// a row is never split, even if it is too big
+	batches = ins.Batches(1, 1)
+	assert.Len(t, batches, 10)
+
+	batches = Insert(users).Values(rows[0]).ValuesList(rows[1:3]).Batches(4, 0)
+	assert.Len(t, batches, 2)
+	assert.Equal(t, rows[0:2], insertRowMaps(batches[0]))
+	assert.Equal(t, rows[2:3], insertRowMaps(batches[1]))
+}
+
+func TestInsertFromSelect(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	archive := Table(
+		"archived_users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+	)
+	dialect := NewDefaultDialect()
+
+	sel := Select(users.C("id"), users.C("email")).From(users).Where(users.C("id").Gt(10))
+	stmt := Insert(archive).Columns(archive.C("id"), users.C("email")).FromSelect(sel).Build(dialect)
+	assert.Nil(t, stmt.Err())
+	assert.Equal(t, "INSERT INTO archived_users(id, email)\nSELECT id, email\nFROM users\nWHERE id > ?;", stmt.SQL())
+	assert.Equal(t, []interface{}{10}, stmt.Bindings())
+
+	stmt = Insert(archive).FromSelect(sel).Returning(archive.C("id")).Build(dialect)
+	assert.Equal(t, "INSERT INTO archived_users\nSELECT id, email\nFROM users\nWHERE id > ?\nRETURNING id;", stmt.SQL())
+
+	stmt = Insert(archive).FromSelect(sel).Values(map[string]interface{}{"id": 1}).Build(dialect)
+	assert.EqualError(t, stmt.Err(), "Cannot insert values in archived_users with FromSelect or DefaultValues")
+	stmt = Insert(archive).FromSelect(sel).DefaultValues().Build(dialect)
+	assert.EqualError(t, stmt.Err(), "Cannot insert in archived_users with both FromSelect and DefaultValues")
+}
+
+func TestInsertDefaultValues(t *testing.T) {
+	users := Table("users", Column("id", Int()).PrimaryKey().AutoIncrement())
+	dialect := NewDefaultDialect()
+
+	ins := Insert(users).DefaultValues()
+	assert.True(t, ins.IsDefaultValues())
+	assert.False(t, Insert(users).IsDefaultValues())
+
+	stmt := ins.Returning(users.C("id")).Build(dialect)
+	assert.Equal(t, "INSERT INTO users DEFAULT VALUES\nRETURNING id;", stmt.SQL())
+	assert.Empty(t, stmt.Bindings())
+
+	stmt = ins.ValuesList([]map[string]interface{}{{"id": 1}}).Build(dialect)
+	assert.NotNil(t, stmt.Err())
+}
@@ -22,7 +22,8 @@ func (m *MetaDataElem) AddTable(table TableElem) {
 	m.tables = append(m.tables, table)
 }
 
-// Table returns the metadata registered table object. It returns nil if table is not found
+// Table returns the metadata registered table object. If the table is not
+// found, the statements using the returned table fail to build
 func (m *MetaDataElem) Table(name string) TableElem {
 	for _, t := range m.tables {
 		if t.Name == name {
@@ -30,7 +31,11 @@ func (m *MetaDataElem) Table(name string) TableElem {
 		}
 	}
 
-	panic(fmt.Errorf("Table %s not found", name))
+	return TableElem{
+		Name:        name,
+		columnIndex: map[string]int{},
+		err:         Error{Code: ErrInterface, Orig: fmt.Errorf("Table %s not found", name)},
+	}
 }
 
 // Tables returns the current tables slice
@@ -46,6 +51,10 @@ func (m *MetaDataElem) CreateAll(engine *Engine) error {
 	}
 
 	for _, t := range m.tables {
+		if err = t.Err(engine.Dialect()); err != nil {
+			tx.Rollback()
+			return err
+		}
 		_, err = tx.Exec(t.Create(engine.Dialect()))
 		if err != nil {
 			return err
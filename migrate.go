@@ -0,0 +1,418 @@
+package qb
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MigrationsTableName is the name of the table recording the applied
+// migrations
+const MigrationsTableName = "qb_migrations"
+
+var migrationsTable = Table(
+	MigrationsTableName,
+	Column("version", BigInt()).PrimaryKey(),
+	Column("name", Varchar()).NotNull(),
+	Column("checksum", Varchar().Size(64)).NotNull(),
+	Column("applied_at", Timestamp()).NotNull(),
+)
+
+// TransactionalDDL is an optional Dialect capability telling if the DDL
+// statements are transactional, i.e. can be rolled back
+type TransactionalDDL interface {
+	SupportsTransactionalDDL() bool
+}
+
+// ForeignKeysSwitch is an optional Dialect capability of the dialects whose
+// schema changes can break the foreign keys while they are applied, and
+// whose foreign keys enforcement cannot be disabled in a transaction, like
+// sqlite and its table rebuilds.
+// The migrator disables the enforcement on the connection of a migration
+// before beginning its transaction, and checks the foreign keys before
+// committing it.
+type ForeignKeysSwitch interface {
+	// SwitchForeignKeys enables or disables the foreign keys enforcement on
+	// a connection, and returns true if it was enabled
+	SwitchForeignKeys(conn *sql.Conn, on bool) (bool, error)
+	// CheckForeignKeys returns an error if a row violates a foreign key
+	CheckForeignKeys(tx *Tx) error
+}
+
+// Migration is a versioned change of the database schema
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *Tx) error
+	Down    func(tx *Tx) error
+	// Checksum identifies the content of the migration, so the changes of
+	// an already applied migration can be detected.
+	// It is computed from the SQL of the SQL migrations. The Go functions
+	// cannot be checksummed: the Checksum of the other migrations should be
+	// set and changed with their code, else it defaults to their version and
+	// name, their changes are not detected and the migrator logs a warning.
+	Checksum string
+}
+
+// NewMigration returns a migration made of Go functions. down can be nil if
+// the migration cannot be reverted.
+// The changes of the functions are not detected, see Migration.Checksum.
+func NewMigration(version int64, name string, up func(tx *Tx) error, down func(tx *Tx) error) Migration {
+	return Migration{
+		Version: version,
+		Name:    name,
+		Up:      up,
+		Down:    down,
+	}
+}
+
+// SQLMigration returns a migration made of raw SQL. down can be empty if the
+// migration cannot be reverted.
+// The statements must be separated by a semicolon at the end of a line. The
+// semicolons in quoted strings and identifiers, comments and dollar-quoted
+// bodies are skipped, but not the ones of the BEGIN ... END blocks: a
+// statement containing such a block, like a trigger, must not have a
+// semicolon at the end of its inner lines.
+func SQLMigration(version int64, name string, up string, down string) Migration {
+	migration := Migration{
+		Version:  version,
+		Name:     name,
+		Up:       sqlMigrationFunc(up),
+		Checksum: checksum(up + "\n" + down),
+	}
+	if down != "" {
+		migration.Down = sqlMigrationFunc(down)
+	}
+	return migration
+}
+
+// LoadSQLMigrations returns the SQL migrations of a directory.
+// The migrations are read from the files named
+// "<version>_<name>.up.sql" and "<version>_<name>.down.sql",
+// the down file being optional.
+// The files are split in statements as described by SQLMigration.
+func LoadSQLMigrations(dir string) ([]Migration, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, path := range paths {
+		base := strings.TrimSuffix(filepath.Base(path), ".up.sql")
+		parts := strings.SplitN(base, "_", 2)
+		version, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || len(parts) != 2 {
+			return nil, migrationError("Invalid migration file name %s, expected <version>_<name>.up.sql", path)
+		}
+
+		up, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		down, err := ioutil.ReadFile(filepath.Join(dir, base+".down.sql"))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		migrations = append(migrations, SQLMigration(version, parts[1], string(up), string(down)))
+	}
+	return migrations, nil
+}
+
+// migrationError returns an ErrInterface Error about the migrations
+func migrationError(format string, args ...interface{}) Error {
+	return Error{Code: ErrInterface, Orig: fmt.Errorf(format, args...)}
+}
+
+func checksum(content string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(content)))
+}
+
+// sqlMigrationFunc returns a migration function running raw SQL statements
+func sqlMigrationFunc(sql string) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		for _, statement := range splitSQLStatements(sql) {
+			if _, err := tx.Tx().Exec(statement); err != nil {
+				return tx.engine.TranslateError(err)
+			}
+		}
+		return nil
+	}
+}
+
+// splitSQLStatements splits a SQL script on the semicolons ending a line,
+// skipping the ones in quoted strings and identifiers, comments and
+// dollar-quoted bodies
+func splitSQLStatements(sql string) []string {
+	var statements []string
+	flush := func(statement string) {
+		statement = strings.TrimSpace(statement)
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	start := 0
+	// end is the end of the quoted string, comment or dollar-quoted body
+	// being skipped
+	end := ""
+	for i := 0; i < len(sql); i++ {
+		if end != "" {
+			if strings.HasPrefix(sql[i:], end) {
+				i += len(end) - 1
+				end = ""
+			}
+			continue
+		}
+		switch c := sql[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			end = string(c)
+		case strings.HasPrefix(sql[i:], "--"):
+			end = "\n"
+		case strings.HasPrefix(sql[i:], "/*"):
+			end = "*/"
+			i++
+		case c == '$' && (i == 0 || !isIdentifierByte(sql[i-1])):
+			if tag := dollarQuoteTag.FindString(sql[i:]); tag != "" {
+				end = tag
+				i += len(tag) - 1
+			}
+		case c == ';' && strings.TrimLeft(strings.SplitN(sql[i+1:], "\n", 2)[0], " \t\r") == "":
+			flush(sql[start : i+1])
+			start = i + 1
+		}
+	}
+	flush(sql[start:])
+	return statements
+}
+
+// dollarQuoteTag matches the tag opening a dollar-quoted body, like $$ or
+// $body$
+var dollarQuoteTag = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// AppliedMigration is a migration recorded in the migrations table
+type AppliedMigration struct {
+	Version  int64
+	Name     string
+	Checksum string
+}
+
+// NewMigrator returns a Migrator of the engine database
+func NewMigrator(engine *Engine, migrations ...Migration) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	for i, migration := range sorted {
+		if migration.Checksum == "" {
+			engine.Logger().Printf(
+				"WARNING: migration %d_%s has no checksum, the changes of its functions will not be detected\n",
+				migration.Version, migration.Name,
+			)
+			sorted[i].Checksum = checksum(fmt.Sprintf("%d_%s", migration.Version, migration.Name))
+		}
+	}
+	return &Migrator{engine: engine, migrations: sorted}
+}
+
+// Migrator applies or reverts the migrations, one transaction per migration,
+// and records the applied ones in the migrations table.
+// It refuses to run if an applied migration was changed or is unknown.
+// The dialect must be a Reflector, which tells if the migrations table
+// has to be created.
+type Migrator struct {
+	engine     *Engine
+	migrations []Migration
+}
+
+// Applied returns the applied migrations, as recorded in the migrations
+// table, ordered by version
+func (m *Migrator) Applied() ([]AppliedMigration, error) {
+	if err := m.createMigrationsTable(); err != nil {
+		return nil, err
+	}
+
+	var applied []AppliedMigration
+	sel := Select(migrationsTable.C("version"), migrationsTable.C("name"), migrationsTable.C("checksum")).
+		From(migrationsTable).
+		OrderBy(migrationsTable.C("version"))
+	if err := m.engine.Select(sel, &applied); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// createMigrationsTable creates the migrations table if it does not exist
+func (m *Migrator) createMigrationsTable() error {
+	reflector, ok := m.engine.Dialect().(Reflector)
+	if !ok {
+		return Error{
+			Code: ErrNotSupported,
+			Orig: fmt.Errorf("The %s dialect does not support schema reflection, the migrations table cannot be found", m.engine.Dialect().Driver()),
+		}
+	}
+	names, err := reflector.ReflectTableNames(m.engine)
+	if err != nil {
+		return translateReflectError(m.engine, err)
+	}
+	for _, name := range names {
+		if name == MigrationsTableName {
+			return nil
+		}
+	}
+	if _, err := m.engine.DB().Exec(migrationsTable.Create(m.engine.Dialect())); err != nil {
+		return m.engine.TranslateError(err)
+	}
+	return nil
+}
+
+// Pending returns the migrations that are not applied yet, after checking
+// the applied ones were not changed
+func (m *Migrator) Pending() ([]Migration, error) {
+	applied, err := m.check()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// check returns the applied migrations by version, after checking they
+// match the migrator ones
+func (m *Migrator) check() (map[int64]AppliedMigration, error) {
+	for i := 1; i < len(m.migrations); i++ {
+		if m.migrations[i].Version == m.migrations[i-1].Version {
+			return nil, migrationError("Duplicate migration version %d", m.migrations[i].Version)
+		}
+	}
+
+	applied, err := m.Applied()
+	if err != nil {
+		return nil, err
+	}
+	migrations := map[int64]Migration{}
+	for _, migration := range m.migrations {
+		migrations[migration.Version] = migration
+	}
+	byVersion := map[int64]AppliedMigration{}
+	for _, a := range applied {
+		migration, ok := migrations[a.Version]
+		if !ok {
+			return nil, migrationError("Applied migration %d_%s is unknown", a.Version, a.Name)
+		}
+		if migration.Checksum != a.Checksum {
+			return nil, migrationError("Applied migration %d_%s has changed", a.Version, a.Name)
+		}
+		byVersion[a.Version] = a
+	}
+	return byVersion, nil
+}
+
+// Up applies the pending migrations, in version order
+func (m *Migrator) Up() error {
+	pending, err := m.Pending()
+	if err != nil {
+		return err
+	}
+	for _, migration := range pending {
+		err := m.run(migration, migration.Up, Insert(migrationsTable).Values(map[string]interface{}{
+			"version":    migration.Version,
+			"name":       migration.Name,
+			"checksum":   migration.Checksum,
+			"applied_at": time.Now().UTC(),
+		}))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Down reverts the last applied migration
+func (m *Migrator) Down() error {
+	applied, err := m.check()
+	if err != nil {
+		return err
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == nil {
+			return migrationError("Migration %d_%s cannot be reverted", migration.Version, migration.Name)
+		}
+		return m.run(migration, migration.Down, Delete(migrationsTable).
+			Where(migrationsTable.C("version").Eq(migration.Version)))
+	}
+	return nil
+}
+
+// run runs a migration function and updates the migrations table in a
+// transaction
+func (m *Migrator) run(migration Migration, fn func(tx *Tx) error, record Builder) error {
+	ctx := context.Background()
+	conn, err := m.engine.DB().Conn(ctx)
+	if err != nil {
+		return m.engine.TranslateError(err)
+	}
+	defer conn.Close()
+
+	switcher, ok := m.engine.Dialect().(ForeignKeysSwitch)
+	if ok {
+		enabled, err := switcher.SwitchForeignKeys(conn, false)
+		if err != nil {
+			return m.engine.TranslateError(err)
+		}
+		if enabled {
+			defer switcher.SwitchForeignKeys(conn, true)
+		} else {
+			switcher = nil
+		}
+	}
+
+	if t, ok := m.engine.Dialect().(TransactionalDDL); !ok || !t.SupportsTransactionalDDL() {
+		m.engine.Logger().Printf(
+			"WARNING: the %s dialect does not support transactional DDL, migration %d_%s may be partially applied if it fails\n",
+			m.engine.Dialect().Driver(), migration.Version, migration.Name,
+		)
+	}
+
+	tx, err := m.engine.beginConnTx(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(record); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if switcher != nil {
+		if err := switcher.CheckForeignKeys(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,33 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSQLStatements(t *testing.T) {
+	assert.Equal(t, []string{
+		"CREATE TABLE users (\n\tid INTEGER\n);",
+		"INSERT INTO users VALUES ('a;\nb', \"c;\nd\", `e;\nf`);",
+		"-- a comment;\n/* another\none; */ SELECT 1; SELECT 2;",
+		"SELECT $1",
+	}, splitSQLStatements(
+		"CREATE TABLE users (\n\tid INTEGER\n);\n"+
+			"INSERT INTO users VALUES ('a;\nb', \"c;\nd\", `e;\nf`);\n"+
+			"-- a comment;\n/* another\none; */ SELECT 1; SELECT 2;  \n\n"+
+			"SELECT $1",
+	))
+
+	function := "CREATE FUNCTION touch() RETURNS trigger AS $body$\n" +
+		"BEGIN\n" +
+		"\tNEW.updated_at = now();\n" +
+		"\tRETURN NEW;\n" +
+		"END;\n" +
+		"$body$ LANGUAGE plpgsql;"
+	block := "DO $$\nBEGIN\n\tPERFORM 1;\nEND\n$$;"
+	assert.Equal(t, []string{function, block}, splitSQLStatements(function+"\n"+block+"\n"))
+
+	assert.Equal(t, []string{"SELECT 'it''s;\n';"}, splitSQLStatements("SELECT 'it''s;\n';"))
+	assert.Empty(t, splitSQLStatements(" \n"))
+}
@@ -0,0 +1,184 @@
+package qb_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slicebit/qb"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMigrateEngine(t *testing.T) (*qb.Engine, func()) {
+	dir, err := ioutil.TempDir("", "qb_migrate")
+	assert.Nil(t, err)
+	engine, err := qb.New("sqlite3", filepath.Join(dir, "qb_migrate.db"))
+	assert.Nil(t, err)
+	return engine, func() {
+		engine.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func tableNames(t *testing.T, engine *qb.Engine) []string {
+	var names []string
+	err := engine.DB().Select(&names, "SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name")
+	assert.Nil(t, err)
+	return names
+}
+
+func TestMigrator(t *testing.T) {
+	engine, cleanup := newMigrateEngine(t)
+	defer cleanup()
+
+	users := qb.Table("users", qb.Column("id", qb.Int()).PrimaryKey())
+	migrations := []qb.Migration{
+		qb.SQLMigration(2, "create_sessions",
+			"CREATE TABLE sessions (\n\tid INTEGER\n);\nCREATE INDEX i_sessions ON sessions(id);\n",
+			"DROP TABLE sessions;",
+		),
+		qb.NewMigration(1, "create_users",
+			func(tx *qb.Tx) error {
+				_, err := tx.Tx().Exec(users.Create(engine.Dialect()))
+				return err
+			},
+			func(tx *qb.Tx) error {
+				_, err := tx.Tx().Exec(users.Drop(engine.Dialect()))
+				return err
+			},
+		),
+	}
+	migrator := qb.NewMigrator(engine, migrations...)
+
+	pending, err := migrator.Pending()
+	assert.Nil(t, err)
+	assert.Len(t, pending, 2)
+	assert.Equal(t, int64(1), pending[0].Version)
+
+	assert.Nil(t, migrator.Up())
+	assert.Equal(t, []string{"qb_migrations", "sessions", "users"}, tableNames(t, engine))
+
+	applied, err := migrator.Applied()
+	assert.Nil(t, err)
+	assert.Len(t, applied, 2)
+	assert.Equal(t, int64(1), applied[0].Version)
+	assert.Equal(t, "create_users", applied[0].Name)
+	assert.Equal(t, "create_sessions", applied[1].Name)
+
+	pending, err = migrator.Pending()
+	assert.Nil(t, err)
+	assert.Len(t, pending, 0)
+	assert.Nil(t, migrator.Up())
+
+	assert.Nil(t, migrator.Down())
+	assert.Equal(t, []string{"qb_migrations", "users"}, tableNames(t, engine))
+	assert.Nil(t, migrator.Down())
+	assert.Equal(t, []string{"qb_migrations"}, tableNames(t, engine))
+	assert.Nil(t, migrator.Down())
+
+	applied, err = migrator.Applied()
+	assert.Nil(t, err)
+	assert.Len(t, applied, 0)
+}
+
+func TestMigratorFailure(t *testing.T) {
+	engine, cleanup := newMigrateEngine(t)
+	defer cleanup()
+
+	migrator := qb.NewMigrator(engine,
+		qb.SQLMigration(1, "create_users", "CREATE TABLE users (id INTEGER);", ""),
+		qb.SQLMigration(2, "broken", "CREATE TABLE sessions (id INTEGER);\nCREATE TABLE users (id INTEGER);", ""),
+	)
+	err := migrator.Up()
+	assert.NotNil(t, err)
+
+	// the failed migration is rolled back
+	assert.Equal(t, []string{"qb_migrations", "users"}, tableNames(t, engine))
+	applied, err := migrator.Applied()
+	assert.Nil(t, err)
+	assert.Len(t, applied, 1)
+
+	err = migrator.Down()
+	assert.Equal(t, qb.Error{Code: qb.ErrInterface, Orig: errors.New("Migration 1_create_users cannot be reverted")}, err)
+}
+
+func TestMigratorChecks(t *testing.T) {
+	engine, cleanup := newMigrateEngine(t)
+	defer cleanup()
+
+	assert.Nil(t, qb.NewMigrator(engine,
+		qb.SQLMigration(1, "create_users", "CREATE TABLE users (id INTEGER);", "DROP TABLE users;"),
+	).Up())
+
+	changed := qb.NewMigrator(engine,
+		qb.SQLMigration(1, "create_users", "CREATE TABLE users (id BIGINT);", "DROP TABLE users;"),
+	)
+	_, err := changed.Pending()
+	assert.Equal(t, qb.Error{Code: qb.ErrInterface, Orig: errors.New("Applied migration 1_create_users has changed")}, err)
+	assert.Equal(t, err, changed.Up())
+	assert.Equal(t, err, changed.Down())
+
+	_, err = qb.NewMigrator(engine).Pending()
+	assert.Equal(t, qb.Error{Code: qb.ErrInterface, Orig: errors.New("Applied migration 1_create_users is unknown")}, err)
+
+	_, err = qb.NewMigrator(engine,
+		qb.SQLMigration(1, "create_users", "CREATE TABLE users (id INTEGER);", "DROP TABLE users;"),
+		qb.SQLMigration(2, "a", "SELECT 1;", ""),
+		qb.SQLMigration(2, "b", "SELECT 2;", ""),
+	).Pending()
+	assert.Equal(t, qb.Error{Code: qb.ErrInterface, Orig: errors.New("Duplicate migration version 2")}, err)
+
+	var logs bytes.Buffer
+	engine.SetLogger(&qb.DefaultLogger{Logger: log.New(&logs, "", 0)})
+	createUsers := qb.SQLMigration(1, "create_users", "CREATE TABLE users (id INTEGER);", "DROP TABLE users;")
+	noop := qb.NewMigration(2, "noop", func(tx *qb.Tx) error { return nil }, nil)
+	assert.Nil(t, qb.NewMigrator(engine, createUsers, noop).Up())
+	assert.Contains(t, logs.String(), "WARNING: migration 2_noop has no checksum")
+	assert.NotContains(t, logs.String(), "1_create_users")
+
+	// the changes of the functions of a Go migration are not detected...
+	changedNoop := qb.NewMigration(2, "noop", func(tx *qb.Tx) error { return errors.New("changed") }, nil)
+	pending, err := qb.NewMigrator(engine, createUsers, changedNoop).Pending()
+	assert.Nil(t, err)
+	assert.Empty(t, pending)
+
+	// ...unless its checksum is changed too
+	changedNoop.Checksum = "noop v2"
+	_, err = qb.NewMigrator(engine, createUsers, changedNoop).Pending()
+	assert.Equal(t, qb.Error{Code: qb.ErrInterface, Orig: errors.New("Applied migration 2_noop has changed")}, err)
+}
+
+func TestLoadSQLMigrations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "qb_migrations")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"0002_add_email.up.sql":      "ALTER TABLE users ADD COLUMN email VARCHAR(255);",
+		"0001_create_users.up.sql":   "CREATE TABLE users (id INTEGER);",
+		"0001_create_users.down.sql": "DROP TABLE users;",
+		"README":                     "not a migration",
+	}
+	for name, content := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	migrations, err := qb.LoadSQLMigrations(dir)
+	assert.Nil(t, err)
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_users", migrations[0].Name)
+	assert.NotNil(t, migrations[0].Down)
+	assert.Equal(t, int64(2), migrations[1].Version)
+	assert.Equal(t, "add_email", migrations[1].Name)
+	assert.Nil(t, migrations[1].Down)
+	assert.NotEqual(t, migrations[0].Checksum, migrations[1].Checksum)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "create_posts.up.sql"), []byte("SELECT 1;"), 0644))
+	_, err = qb.LoadSQLMigrations(dir)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,348 @@
+package qb
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/serenize/snaker"
+)
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	bytesType       = reflect.TypeOf([]byte{})
+	nullStringType  = reflect.TypeOf(sql.NullString{})
+	nullInt64Type   = reflect.TypeOf(sql.NullInt64{})
+	nullFloat64Type = reflect.TypeOf(sql.NullFloat64{})
+	nullBoolType    = reflect.TypeOf(sql.NullBool{})
+)
+
+// TableFromStruct builds a table from the exported fields of a struct.
+// The column names are the snake case field names, as mapped by the engine,
+// or the name given in the 'db' tag.
+// The columns are defined by the 'qb' tag, which is a list of options
+// separated by semicolons:
+//
+//	type User struct {
+//		ID    int64  `qb:"pk;autoincrement"`
+//		Email string `qb:"type:varchar(64);notnull;unique"`
+//		Role  string `qb:"default:member;index"`
+//		Group int64  `qb:"fk:groups.id"`
+//		Timestamps
+//	}
+//
+// The type is guessed from the field type if not set. The fields of the
+// embedded structs are columns of the table, and the fields tagged with
+// `qb:"-"` or `db:"-"` are skipped. The 'omitempty' and 'readonly' options are used by
+// the ValuesFrom methods of the insert, update and upsert statements.
+func TableFromStruct(name string, model interface{}) (TableElem, error) {
+	t := reflect.TypeOf(model)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return TableElem{}, fmt.Errorf("Cannot build table %s from %T, a struct is expected", name, model)
+	}
+	fields, err := structFields(name, t)
+	if err != nil {
+		return TableElem{}, err
+	}
+	if len(fields) == 0 {
+		return TableElem{}, fmt.Errorf("Cannot build table %s from %T, it has no exported field", name, model)
+	}
+
+	var clauses []TableSQLClause
+	var indices []string
+	for _, field := range fields {
+		col := Column(field.name, fieldType(field.typ))
+		for _, option := range field.options {
+			switch option.key {
+			case "type":
+				col.Type = ParseType(option.value)
+			case "pk":
+				col = col.PrimaryKey()
+			case "autoincrement":
+				col = col.AutoIncrement()
+			case "notnull":
+				col = col.NotNull()
+			case "unique":
+				col = col.Unique()
+			case "default":
+				col = col.Default(option.value)
+			case "index":
+				indices = append(indices, field.name)
+			case "fk":
+				ref := strings.SplitN(option.value, ".", 2)
+				if len(ref) != 2 || ref[0] == "" || ref[1] == "" {
+					return TableElem{}, fmt.Errorf("Invalid foreign key '%s' of %s.%s, expected fk:table.column", option.value, name, field.name)
+				}
+				clauses = append(clauses, ForeignKey(field.name).References(ref[0], ref[1]))
+			}
+		}
+		if col.Type.Name == "" {
+			return TableElem{}, fmt.Errorf("Cannot guess the type of %s.%s from %s, a type option is expected", name, field.name, field.typ)
+		}
+		clauses = append(clauses, col)
+	}
+
+	table := Table(name, clauses...)
+	for _, col := range indices {
+		table = table.Index(col)
+	}
+	return table, nil
+}
+
+// AddStruct builds a table from a struct with TableFromStruct and appends it
+// to the tables
+func (m *MetaDataElem) AddStruct(name string, model interface{}) (TableElem, error) {
+	table, err := TableFromStruct(name, model)
+	if err != nil {
+		return TableElem{}, err
+	}
+	m.AddTable(table)
+	return table, nil
+}
+
+// structTagOptions are the options allowed in the 'qb' tag
+var structTagOptions = map[string]bool{
+	"type": true, "pk": true, "autoincrement": true, "notnull": true,
+	"unique": true, "default": true, "index": true, "fk": true,
+	"omitempty": true, "readonly": true,
+}
+
+type structTagOption struct {
+	key   string
+	value string
+}
+
+// structField is a field of a struct mapped to a column
+type structField struct {
+	name    string
+	typ     reflect.Type
+	index   []int
+	options []structTagOption
+}
+
+// has returns true if the field has an option in its 'qb' tag
+func (f structField) has(key string) bool {
+	for _, option := range f.options {
+		if option.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// structFields returns the fields of a struct type that are mapped to
+// columns, including the fields of its embedded structs.
+// The owner is used in the error messages.
+func structFields(owner string, t reflect.Type) ([]structField, error) {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("qb")
+		dbName := strings.Split(field.Tag.Get("db"), ",")[0]
+		if tag == "-" || dbName == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			embedded, err := structFields(owner, field.Type)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range embedded {
+				f.index = append([]int{i}, f.index...)
+				fields = append(fields, f)
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			// unexported field
+			continue
+		}
+
+		f := structField{
+			name:  snaker.CamelToSnake(field.Name),
+			typ:   field.Type,
+			index: []int{i},
+		}
+		if dbName != "" {
+			f.name = dbName
+		}
+		for _, option := range strings.Split(tag, ";") {
+			option = strings.TrimSpace(option)
+			if option == "" {
+				continue
+			}
+			key, value := option, ""
+			if i := strings.Index(option, ":"); i != -1 {
+				key, value = strings.TrimSpace(option[:i]), strings.TrimSpace(option[i+1:])
+			}
+			key = strings.ToLower(key)
+			if !structTagOptions[key] {
+				return nil, fmt.Errorf("Unknown option '%s' in the qb tag of %s.%s", key, owner, f.name)
+			}
+			f.options = append(f.options, structTagOption{key, value})
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// structValue returns the struct value of a model, which can be a struct or
+// a pointer to a struct
+func structValue(model interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return v, fmt.Errorf("Cannot get the values of %T, a struct is expected", model)
+	}
+	return v, nil
+}
+
+// structValues returns the values of the fields of a struct, in the order
+// of the fields. The readonly fields, the omitempty fields holding a zero value and the
+// fields for which skip returns true are left out.
+func structValues(model interface{}, skip func(field structField, value reflect.Value) bool) (ValueSet, error) {
+	v, err := structValue(model)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := structFields(v.Type().Name(), v.Type())
+	if err != nil {
+		return nil, err
+	}
+	var values ValueSet
+	for _, field := range fields {
+		value := v.FieldByIndex(field.index)
+		if field.has("readonly") || (field.has("omitempty") && value.IsZero()) || skip(field, value) {
+			continue
+		}
+		values = values.Set(field.name, value.Interface())
+	}
+	return values, nil
+}
+
+// isGeneratedKey returns true if the field is an auto increment primary key
+func isGeneratedKey(field structField) bool {
+	return field.has("pk") && field.has("autoincrement")
+}
+
+// generatedKey is the auto increment primary key field of a struct, which
+// receives the key generated by an insert
+type generatedKey struct {
+	column string
+	value  reflect.Value
+}
+
+// structGeneratedKey returns the generated key of a model, if it is a
+// pointer to a struct having an auto increment primary key field
+func structGeneratedKey(model interface{}) *generatedKey {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	v = v.Elem()
+	fields, err := structFields(v.Type().Name(), v.Type())
+	if err != nil {
+		return nil
+	}
+	for _, field := range fields {
+		if isGeneratedKey(field) {
+			return &generatedKey{column: field.name, value: v.FieldByIndex(field.index)}
+		}
+	}
+	return nil
+}
+
+// set writes the key returned by LastInsertId
+func (k *generatedKey) set(id int64) error {
+	switch k.value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		k.value.SetInt(id)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		k.value.SetUint(uint64(id))
+	default:
+		return fmt.Errorf("Cannot write the generated key %d into a %s field", id, k.value.Type())
+	}
+	return nil
+}
+
+// get returns the key read by a RETURNING clause, for LastInsertId
+func (k *generatedKey) get() (int64, error) {
+	switch k.value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return k.value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(k.value.Uint()), nil
+	default:
+		return 0, fmt.Errorf("Cannot return the generated key of a %s field as an int64", k.value.Type())
+	}
+}
+
+// returningResult is the sql.Result of an insert whose generated key was
+// read with a RETURNING clause
+type returningResult struct {
+	key *generatedKey
+}
+
+// LastInsertId returns the generated key
+func (r returningResult) LastInsertId() (int64, error) {
+	return r.key.get()
+}
+
+// RowsAffected returns 1, as a single row is inserted
+func (r returningResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+// fieldType guesses the column type of a struct field type. It returns an
+// empty type if there is no obvious column type
+func fieldType(t reflect.Type) TypeElem {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return Timestamp()
+	case bytesType:
+		return Blob()
+	case nullStringType:
+		return Varchar()
+	case nullInt64Type:
+		return BigInt()
+	case nullFloat64Type:
+		return Float()
+	case nullBoolType:
+		return Boolean()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return Varchar()
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int8:
+		return TinyInt()
+	case reflect.Int16:
+		return SmallInt()
+	case reflect.Int32:
+		return Int()
+	case reflect.Int, reflect.Int64:
+		return BigInt()
+	case reflect.Uint8:
+		return TinyInt().Unsigned()
+	case reflect.Uint16:
+		return SmallInt().Unsigned()
+	case reflect.Uint32:
+		return Int().Unsigned()
+	case reflect.Uint, reflect.Uint64:
+		return BigInt().Unsigned()
+	case reflect.Float32, reflect.Float64:
+		return Float()
+	}
+	return TypeElem{}
+}
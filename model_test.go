@@ -0,0 +1,152 @@
+package qb
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type modelTimestamps struct {
+	CreatedAt time.Time  `qb:"notnull"`
+	DeletedAt *time.Time `db:"removed_at"`
+}
+
+type modelUser struct {
+	ID       int64  `qb:"pk;autoincrement"`
+	Email    string `qb:"type:varchar(64);notnull;unique"`
+	Role     string `qb:"default:member;index"`
+	GroupID  int    `qb:"fk:groups.id"`
+	Nickname sql.NullString
+	Avatar   []byte
+	Score    float64
+	Active   bool
+	Password string `qb:"-"`
+	Token    string `db:"-"`
+	internal string
+	modelTimestamps
+}
+
+func TestTableFromStruct(t *testing.T) {
+	dialect := NewDefaultDialect()
+	metadata := MetaData()
+	users, err := metadata.AddStruct("users", &modelUser{})
+	assert.Nil(t, err)
+	assert.Equal(t, []TableElem{users}, metadata.Tables())
+
+	assert.Equal(t, "users", users.Name)
+	assert.Equal(t, []string{
+		"id", "email", "role", "group_id", "nickname", "avatar", "score",
+		"active", "created_at", "removed_at",
+	}, users.ColumnNames())
+	assert.Equal(t, []string{"id"}, users.PrimaryKeyConstraint.Columns)
+	assert.True(t, users.C("id").Options.AutoIncrement)
+	assert.Equal(t, "BIGINT", dialect.CompileType(users.C("id").Type))
+	assert.Equal(t, "email VARCHAR(64) NOT NULL UNIQUE", users.C("email").String(dialect))
+	assert.Equal(t, "role VARCHAR(255) DEFAULT 'member'", users.C("role").String(dialect))
+	assert.Equal(t, "group_id BIGINT", users.C("group_id").String(dialect))
+	assert.Equal(t, "nickname VARCHAR(255)", users.C("nickname").String(dialect))
+	assert.Equal(t, "avatar BLOB", users.C("avatar").String(dialect))
+	assert.Equal(t, "score FLOAT", users.C("score").String(dialect))
+	assert.Equal(t, "active BOOLEAN", users.C("active").String(dialect))
+	assert.Equal(t, "created_at TIMESTAMP NOT NULL", users.C("created_at").String(dialect))
+	assert.Equal(t, "removed_at TIMESTAMP", users.C("removed_at").String(dialect))
+	assert.NotContains(t, users.ColumnNames(), "password")
+	assert.NotContains(t, users.ColumnNames(), "token")
+	assert.NotContains(t, users.ColumnNames(), "-")
+	assert.NotContains(t, users.ColumnNames(), "internal")
+
+	assert.Equal(t, []ForeignKeyConstraint{
+		{Cols: []string{"group_id"}, RefTable: "groups", RefCols: []string{"id"}},
+	}, users.ForeignKeyConstraints.FKeys)
+	assert.Len(t, users.Indices, 1)
+	assert.Equal(t, "CREATE INDEX i_role ON users(role);", users.Indices[0].String(dialect))
+}
+
+func TestTableFromStructErrors(t *testing.T) {
+	_, err := TableFromStruct("users", 42)
+	assert.NotNil(t, err)
+
+	_, err = TableFromStruct("users", struct{ id int }{})
+	assert.NotNil(t, err)
+
+	_, err = TableFromStruct("users", struct {
+		ID int `qb:"primary"`
+	}{})
+	assert.EqualError(t, err, "Unknown option 'primary' in the qb tag of users.id")
+
+	_, err = TableFromStruct("users", struct {
+		GroupID int `qb:"fk:groups"`
+	}{})
+	assert.EqualError(t, err, "Invalid foreign key 'groups' of users.group_id, expected fk:table.column")
+
+	_, err = TableFromStruct("users", struct {
+		Tags []string
+	}{})
+	assert.EqualError(t, err, "Cannot guess the type of users.tags from []string, a type option is expected")
+
+	metadata := MetaData()
+	_, err = metadata.AddStruct("users", nil)
+	assert.NotNil(t, err)
+	assert.Len(t, metadata.Tables(), 0)
+}
+
+type modelAccount struct {
+	ID        int64  `qb:"pk;autoincrement"`
+	Email     string `qb:"notnull"`
+	Nickname  string `qb:"omitempty"`
+	CreatedAt string `qb:"readonly"`
+	Secret    string `qb:"-"`
+	Session   string `db:"-"`
+}
+
+func TestValuesFrom(t *testing.T) {
+	accounts, err := TableFromStruct("accounts", modelAccount{})
+	assert.Nil(t, err)
+	account := modelAccount{ID: 3, Email: "al@pacino.com", CreatedAt: "now", Secret: "x", Session: "y"}
+
+	ins := Insert(accounts).ValuesFrom(&account)
+	assert.Equal(t, ValueSet{{"email", "al@pacino.com"}}, ins.values)
+	assert.Equal(t, "id", ins.generatedKey.column)
+	assert.Nil(t, ins.generatedKey.set(42))
+	assert.Equal(t, int64(42), account.ID)
+
+	ins = Insert(accounts).ValuesFrom(account)
+	assert.Nil(t, ins.generatedKey)
+
+	account.Nickname = "Al"
+	upd := Update(accounts).ValuesFrom(account)
+	assert.Equal(t, ValueSet{{"email", "al@pacino.com"}, {"nickname", "Al"}}, upd.values)
+
+	ups := Upsert(accounts).ValuesFrom(account)
+	assert.Equal(t, ValueSet{{"id", int64(42)}, {"email", "al@pacino.com"}, {"nickname", "Al"}}, ups.ValueSet)
+	account.ID = 0
+	ups = Upsert(accounts).ValuesFrom(account)
+	assert.Equal(t, ValueSet{{"email", "al@pacino.com"}, {"nickname", "Al"}}, ups.ValueSet)
+
+	dialect := NewDefaultDialect()
+	stmt := Insert(accounts).ValuesFrom("account").Build(dialect)
+	assert.EqualError(t, stmt.Err(), "Cannot get the values of string, a struct is expected")
+	assert.Equal(t, "", stmt.SQL())
+	assert.NotNil(t, Update(accounts).ValuesFrom(nil).Build(dialect).Err())
+	assert.NotNil(t, Upsert(accounts).ValuesFrom(struct {
+		ID int `qb:"primary"`
+	}{}).Build(dialect).Err())
+
+	key := generatedKey{value: reflect.ValueOf(&struct{ ID string }{}).Elem().Field(0)}
+	assert.NotNil(t, key.set(1))
+	_, err = returningResult{&key}.LastInsertId()
+	assert.NotNil(t, err)
+
+	// the result of an insert using RETURNING gives the key it read
+	account.ID = 7
+	res := returningResult{Insert(accounts).ValuesFrom(&account).generatedKey}
+	id, err := res.LastInsertId()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(7), id)
+	count, err := res.RowsAffected()
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
@@ -0,0 +1,95 @@
+package qb
+
+// Asc returns an ascending ordering term
+// OrderBy(Asc(users.C("name")).NullsFirst(), Desc(Count(sessions.C("id"))))
+func Asc(clause Clause) OrderTermClause {
+	return OrderTermClause{Clause: clause, Direction: "ASC"}
+}
+
+// Desc returns a descending ordering term
+func Desc(clause Clause) OrderTermClause {
+	return OrderTermClause{Clause: clause, Direction: "DESC"}
+}
+
+// OrderTermClause is a term of an ORDER BY: any clause, with its direction
+// and the position of the nulls
+type OrderTermClause struct {
+	Clause Clause
+	// Direction is ASC, DESC, or empty for the default direction
+	Direction string
+	// Nulls is FIRST, LAST, or empty for the default position of the nulls
+	Nulls string
+
+	// defaultDirection is true if the direction was not given to the term
+	defaultDirection bool
+}
+
+// NullsFirst sorts the nulls before the other values
+func (t OrderTermClause) NullsFirst() OrderTermClause {
+	t.Nulls = "FIRST"
+	return t
+}
+
+// NullsLast sorts the nulls after the other values
+func (t OrderTermClause) NullsLast() OrderTermClause {
+	t.Nulls = "LAST"
+	return t
+}
+
+// Accept calls the compiler VisitOrderTerm function
+func (t OrderTermClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitOrderTerm(context, t)
+}
+
+// orderTerms returns the ordering terms of clauses. The clauses that are not
+// terms yet are given the default direction
+func orderTerms(clauses []Clause, direction string) []OrderTermClause {
+	terms := make([]OrderTermClause, len(clauses))
+	for i, clause := range clauses {
+		if term, ok := clause.(OrderTermClause); ok {
+			terms[i] = term
+		} else {
+			terms[i] = OrderTermClause{Clause: clause, Direction: direction, defaultDirection: true}
+		}
+	}
+	return terms
+}
+
+// setDirection returns the terms with the direction set, except the ones
+// that were given a direction with Asc or Desc
+func setDirection(terms []OrderTermClause, direction string) []OrderTermClause {
+	directed := make([]OrderTermClause, len(terms))
+	for i, term := range terms {
+		if term.Direction == "" || term.defaultDirection {
+			term.Direction = direction
+			term.defaultDirection = true
+		}
+		directed[i] = term
+	}
+	return directed
+}
+
+// OrderByClause is the base struct for generating order by clauses when using select
+// It satisfies SQLClause interface
+type OrderByClause struct {
+	Terms []OrderTermClause
+}
+
+// orderBy returns an ORDER BY of clauses, ascending by default
+func orderBy(clauses []Clause) *OrderByClause {
+	return &OrderByClause{orderTerms(clauses, "ASC")}
+}
+
+// direction returns a copy of the ORDER BY having its terms without
+// explicit direction sorted in a direction, or nil if there is no ORDER BY
+func (c *OrderByClause) direction(direction string) *OrderByClause {
+	if c == nil {
+		return nil
+	}
+	return &OrderByClause{setDirection(c.Terms, direction)}
+}
+
+// Accept generates an order by clause
+func (c OrderByClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitOrderBy(context, c)
+}
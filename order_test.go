@@ -0,0 +1,62 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderBy(t *testing.T) {
+	dialect := NewDefaultDialect()
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("name", Varchar()),
+		Column("last_login", Timestamp()).Null(),
+	)
+
+	sel := Select(users.C("id")).From(users).OrderBy(
+		Desc(users.C("last_login")).NullsLast(),
+		Asc(Lower(users.C("name"))).NullsFirst(),
+		users.C("id"),
+	)
+	assert.Equal(t, "SELECT id\nFROM users\n"+
+		"ORDER BY last_login DESC NULLS LAST, LOWER(name) ASC NULLS FIRST, id ASC;",
+		sel.Build(dialect).SQL())
+
+	sel = Select(users.C("name"), Count(users.C("id"))).
+		From(users).
+		GroupBy(users.C("name")).
+		OrderBy(Desc(Count(users.C("id"))), Case().When(users.C("name").Eq("admin"), 0).Else(1))
+	statement := sel.Build(dialect)
+	assert.Equal(t, "SELECT name, COUNT(id)\nFROM users\nGROUP BY name\n"+
+		"ORDER BY COUNT(id) DESC, CASE WHEN name = ? THEN ? ELSE ? END ASC;", statement.SQL())
+	assert.Equal(t, []interface{}{"admin", 0, 1}, statement.Bindings())
+
+	sel = Select(users.C("id")).From(users).OrderBy(users.C("name"), users.C("id")).Desc()
+	assert.Equal(t, "SELECT id\nFROM users\nORDER BY name DESC, id DESC;", sel.Build(dialect).SQL())
+
+	// the terms having an explicit direction keep it
+	sel = Select(users.C("id")).From(users).OrderBy(Asc(users.C("name")), users.C("id")).Desc()
+	assert.Equal(t, "SELECT id\nFROM users\nORDER BY name ASC, id DESC;", sel.Build(dialect).SQL())
+	sel = sel.Asc()
+	assert.Equal(t, "SELECT id\nFROM users\nORDER BY name ASC, id ASC;", sel.Build(dialect).SQL())
+	window := Window().OrderBy(Desc(users.C("last_login")), users.C("id")).Asc()
+	assert.Equal(t, "SELECT ROW_NUMBER() OVER (ORDER BY last_login DESC, id ASC)\nFROM users;",
+		Select(RowNumber().Over(window)).From(users).Build(dialect).SQL())
+
+	// Asc and Desc without OrderBy are ignored
+	sel = Select(users.C("id")).From(users).Desc()
+	assert.Equal(t, "SELECT id\nFROM users;", sel.Build(dialect).SQL())
+	compound := Union(Select(users.C("id")).From(users), Select(users.C("id")).From(users)).Asc()
+	assert.Nil(t, compound.OrderByClause)
+
+	// the direction does not leak into copies of the statement
+	base := Select(users.C("id")).From(users).OrderBy(users.C("id"))
+	base.Desc()
+	assert.Equal(t, "SELECT id\nFROM users\nORDER BY id ASC;", base.Build(dialect).SQL())
+
+	sel = Select(RowNumber().Over(Window().OrderBy(Desc(users.C("last_login")).NullsLast(), users.C("id")))).From(users)
+	assert.Equal(t, "SELECT ROW_NUMBER() OVER (ORDER BY last_login DESC NULLS LAST, id)\nFROM users;",
+		sel.Build(dialect).SQL())
+}
@@ -0,0 +1,146 @@
+package qb
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Param returns a named parameter, whose value is given to
+// PreparedStmt.Bind, or in the Args of the engine and transaction methods
+//
+//	engine.Query(sel.Where(users.C("age").Gt(qb.Param("min_age"))), qb.Args{"min_age": 18})
+func Param(name string) ParamClause {
+	return ParamClause{Name: name}
+}
+
+// ParamClause is a named parameter of a statement. It is compiled as a
+// bound value, so its placeholder is the one of the dialect. A dialect
+// having numbered placeholders can use the same placeholder for all the
+// occurrences of a parameter.
+type ParamClause struct {
+	Name string
+}
+
+// Accept calls the compiler VisitBind method
+func (c ParamClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitBind(context, Bind(c))
+}
+
+// Prepare compiles a statement once into a reusable template. The values of
+// its Param markers are given to PreparedStmt.Bind, which does not run the
+// compiler again
+//
+//	stmt := qb.Prepare(qb.Select(users.C("email")).From(users).Where(users.C("id").Eq(qb.Param("id"))), dialect)
+//	engine.Get(stmt.Bind(map[string]interface{}{"id": 5}), &email)
+func Prepare(builder Builder, dialect Dialect) *PreparedStmt {
+	return prepareStmt(builder.Build(dialect))
+}
+
+// prepareStmt returns the prepared statement of a built statement
+func prepareStmt(statement *Stmt) *PreparedStmt {
+	prepared := &PreparedStmt{
+		clauses:   statement.SQLClauses(),
+		bindings:  statement.Bindings(),
+		params:    map[string][]int{},
+		delimiter: statement.delimiter,
+		err:       statement.Err(),
+	}
+	for i, value := range prepared.bindings {
+		if param, ok := value.(ParamClause); ok {
+			prepared.params[param.Name] = append(prepared.params[param.Name], i)
+		}
+	}
+	return prepared
+}
+
+// PreparedStmt is a compiled statement having named parameters
+type PreparedStmt struct {
+	clauses   []string
+	bindings  []interface{}
+	params    map[string][]int
+	delimiter string
+	err       error
+}
+
+// Params returns the sorted names of the parameters of the statement
+func (p *PreparedStmt) Params() []string {
+	var names []string
+	for name := range p.params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Err returns the error that prevented the statement from being built, if
+// any
+func (p *PreparedStmt) Err() error {
+	return p.err
+}
+
+// SQL returns the sql of the statement
+func (p *PreparedStmt) SQL() string {
+	return p.statement().SQL()
+}
+
+// statement returns a statement having the sql of the prepared statement
+func (p *PreparedStmt) statement() *Stmt {
+	statement := Statement()
+	statement.SetDelimiter(p.delimiter)
+	statement.clauses = p.clauses[:len(p.clauses):len(p.clauses)]
+	return statement
+}
+
+// Bind returns the statement with the values of its parameters bound.
+// The statement holds an ErrInterface Error if a parameter has no value, or
+// if a value is given for an unknown parameter.
+func (p *PreparedStmt) Bind(values map[string]interface{}) *Stmt {
+	statement := p.statement()
+	if p.err != nil {
+		statement.SetError(p.err)
+		return statement
+	}
+
+	bindings := make([]interface{}, len(p.bindings))
+	copy(bindings, p.bindings)
+	for _, name := range p.Params() {
+		value, ok := values[name]
+		if !ok {
+			statement.SetError(Error{Code: ErrInterface, Orig: fmt.Errorf("Missing value of the parameter %s", name)})
+			return statement
+		}
+		for _, i := range p.params[name] {
+			bindings[i] = value
+		}
+	}
+	for name := range values {
+		if _, ok := p.params[name]; !ok {
+			statement.SetError(Error{Code: ErrInterface, Orig: fmt.Errorf("Unknown parameter %s", name)})
+			return statement
+		}
+	}
+	statement.AddBinding(bindings...)
+	return statement
+}
+
+// Args are the values of the named parameters of a statement
+type Args map[string]interface{}
+
+// bindArgs binds the values of the named parameters of a built statement.
+// A statement having no parameter is returned as is if there is no args.
+func bindArgs(statement *Stmt, args []Args) *Stmt {
+	if statement.Err() != nil {
+		return statement
+	}
+	prepared := prepareStmt(statement)
+	if len(prepared.params) == 0 && len(args) == 0 {
+		return statement
+	}
+	values := map[string]interface{}{}
+	for _, a := range args {
+		for name, value := range a {
+			values[name] = value
+		}
+	}
+	return prepared.Bind(values)
+}
@@ -0,0 +1,55 @@
+package qb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepare(t *testing.T) {
+	dialect := NewDefaultDialect()
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("email", Varchar()),
+		Column("role", Varchar()),
+	)
+
+	sel := Select(users.C("email")).
+		From(users).
+		Where(And(
+			users.C("id").Gt(Param("id")),
+			users.C("role").Eq("admin"),
+			users.C("id").NotEq(Param("id")),
+		))
+	stmt := Prepare(sel, dialect)
+	assert.Nil(t, stmt.Err())
+	assert.Equal(t, []string{"id"}, stmt.Params())
+	assert.Equal(t, "SELECT email\nFROM users\nWHERE (id > ? AND role = ? AND id != ?);", stmt.SQL())
+
+	bound := stmt.Bind(map[string]interface{}{"id": 5})
+	assert.Nil(t, bound.Err())
+	assert.Equal(t, stmt.SQL(), bound.SQL())
+	assert.Equal(t, []interface{}{5, "admin", 5}, bound.Bindings())
+	assert.Equal(t, bound, bound.Build(dialect))
+
+	bound = stmt.Bind(map[string]interface{}{"id": 6})
+	assert.Equal(t, []interface{}{6, "admin", 6}, bound.Bindings())
+
+	assert.EqualError(t, stmt.Bind(nil).Err(), "Interface error: Missing value of the parameter id")
+	assert.EqualError(t, stmt.Bind(map[string]interface{}{"id": 1, "name": "x"}).Err(), "Interface error: Unknown parameter name")
+
+	ins := Prepare(Insert(users).Values(map[string]interface{}{"id": Param("id"), "email": Param("email")}), dialect)
+	assert.Equal(t, []string{"email", "id"}, ins.Params())
+	bound = ins.Bind(map[string]interface{}{"id": 1, "email": "al@pacino.com"})
+	assert.Equal(t, "INSERT INTO users(id, email)\nVALUES(?, ?);", bound.SQL())
+	assert.Equal(t, []interface{}{1, "al@pacino.com"}, bound.Bindings())
+
+	err := errors.New("invalid")
+	invalid := Statement()
+	invalid.SetError(err)
+	failed := Prepare(invalid, dialect)
+	assert.Equal(t, err, failed.Err())
+	assert.Equal(t, err, failed.Bind(nil).Err())
+}
@@ -0,0 +1,134 @@
+package qb
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Reflector is an optional Dialect capability: the dialects implementing it
+// can build the table definitions from the schema of a live database
+type Reflector interface {
+	// ReflectTableNames returns the names of the tables of the database
+	ReflectTableNames(engine *Engine) ([]string, error)
+	// ReflectTable returns the definition of a table, with its columns,
+	// primary key, foreign keys, unique constraints and indices.
+	// If the table does not exist, the returned table has no columns.
+	ReflectTable(engine *Engine, name string) (TableElem, error)
+}
+
+// Reflect returns a new metadata populated with all the tables of the
+// engine database
+func Reflect(engine *Engine) (*MetaDataElem, error) {
+	metadata := MetaData()
+	if err := metadata.Reflect(engine); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// Reflect adds the given tables, as found in the engine database, to the
+// metadata. All the tables of the database are reflected if no name is
+// given.
+// The reflected tables replace the already registered ones of the same name,
+// and are added so that the tables come after the ones they refer to.
+// If the dialect is not a Reflector, an Error with the ErrNotSupported code
+// is returned.
+func (m *MetaDataElem) Reflect(engine *Engine, tableNames ...string) error {
+	reflector, ok := engine.Dialect().(Reflector)
+	if !ok {
+		return Error{
+			Code: ErrNotSupported,
+			Orig: fmt.Errorf("The %s dialect does not support schema reflection", engine.Dialect().Driver()),
+		}
+	}
+
+	if len(tableNames) == 0 {
+		var err error
+		tableNames, err = reflector.ReflectTableNames(engine)
+		if err != nil {
+			return translateReflectError(engine, err)
+		}
+	}
+
+	var tables []TableElem
+	for _, name := range tableNames {
+		table, err := reflector.ReflectTable(engine, name)
+		if err != nil {
+			return translateReflectError(engine, err)
+		}
+		if len(table.Columns) == 0 {
+			return Error{
+				Code:  ErrProgramming,
+				Orig:  fmt.Errorf("Table %s not found", name),
+				Table: name,
+			}
+		}
+		tables = append(tables, table)
+	}
+
+	for _, table := range sortTablesByDependency(tables) {
+		replaced := false
+		for i, t := range m.tables {
+			if t.Name == table.Name {
+				m.tables[i] = table
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			m.AddTable(table)
+		}
+	}
+	return nil
+}
+
+// translateReflectError wraps the driver errors returned by a Reflector
+func translateReflectError(engine *Engine, err error) error {
+	if _, ok := err.(Error); ok {
+		return err
+	}
+	return engine.TranslateError(err)
+}
+
+// sortTablesByDependency sorts the tables by name, then moves the tables
+// referenced by foreign keys before the tables referring to them.
+// The tables involved in a reference cycle are kept in name order.
+func sortTablesByDependency(tables []TableElem) []TableElem {
+	pending := make([]TableElem, len(tables))
+	copy(pending, tables)
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Name < pending[j].Name
+	})
+
+	var sorted []TableElem
+	added := map[string]bool{}
+	for len(pending) != 0 {
+		var remaining []TableElem
+		for _, table := range pending {
+			ready := true
+			for _, fkey := range table.ForeignKeyConstraints.FKeys {
+				if fkey.RefTable == table.Name || added[fkey.RefTable] {
+					continue
+				}
+				for _, other := range pending {
+					if other.Name == fkey.RefTable {
+						ready = false
+					}
+				}
+			}
+			if ready {
+				sorted = append(sorted, table)
+				added[table.Name] = true
+			} else {
+				remaining = append(remaining, table)
+			}
+		}
+		if len(remaining) == len(pending) {
+			// reference cycle
+			sorted = append(sorted, remaining...)
+			break
+		}
+		pending = remaining
+	}
+	return sorted
+}
@@ -0,0 +1,98 @@
+package qb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reflectorDialect struct {
+	DefaultDialect
+	tables map[string]TableElem
+	err    error
+}
+
+func (d *reflectorDialect) ReflectTableNames(engine *Engine) ([]string, error) {
+	var names []string
+	for name := range d.tables {
+		names = append(names, name)
+	}
+	return names, d.err
+}
+
+func (d *reflectorDialect) ReflectTable(engine *Engine, name string) (TableElem, error) {
+	return d.tables[name], d.err
+}
+
+func (d *reflectorDialect) WrapError(err error) Error {
+	return Error{Code: ErrDatabase, Orig: err}
+}
+
+func TestReflect(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("group_id", Int()),
+		ForeignKey("group_id").References("groups", "id"),
+	)
+	groups := Table(
+		"groups",
+		Column("id", Int()).PrimaryKey(),
+		Column("parent_id", Int()),
+		ForeignKey("parent_id").References("groups", "id"),
+	)
+	sessions := Table(
+		"sessions",
+		Column("user_id", Int()),
+		ForeignKey("user_id").References("users", "id"),
+	)
+	dialect := &reflectorDialect{tables: map[string]TableElem{
+		"users":    users,
+		"groups":   groups,
+		"sessions": sessions,
+	}}
+	engine := &Engine{dialect: dialect}
+
+	metadata, err := Reflect(engine)
+	assert.Nil(t, err)
+	assert.Equal(t, []TableElem{groups, users, sessions}, metadata.Tables())
+
+	metadata = MetaData()
+	metadata.AddTable(Table("users", Column("email", Varchar())))
+	assert.Nil(t, metadata.Reflect(engine, "sessions", "users"))
+	assert.Equal(t, []TableElem{users, sessions}, metadata.Tables())
+
+	err = metadata.Reflect(engine, "unknown")
+	assert.Equal(t, Error{
+		Code:  ErrProgramming,
+		Orig:  errors.New("Table unknown not found"),
+		Table: "unknown",
+	}, err)
+
+	dialect.err = errors.New("connection lost")
+	metadata, err = Reflect(engine)
+	assert.Nil(t, metadata)
+	assert.Equal(t, Error{Code: ErrDatabase, Orig: dialect.err}, err)
+
+	dialect.err = Error{Code: ErrOperational, Orig: errors.New("connection lost")}
+	err = MetaData().Reflect(engine, "users")
+	assert.Equal(t, dialect.err, err)
+}
+
+func TestReflectNotSupported(t *testing.T) {
+	engine := &Engine{dialect: NewDefaultDialect()}
+
+	metadata, err := Reflect(engine)
+	assert.Nil(t, metadata)
+	assert.Equal(t, ErrNotSupported, err.(Error).Code)
+}
+
+func TestSortTablesByDependency(t *testing.T) {
+	a := Table("a", Column("b_id", Int()), ForeignKey("b_id").References("b", "id"))
+	b := Table("b", Column("a_id", Int()), ForeignKey("a_id").References("a", "id"))
+	c := Table("c", Column("id", Int()))
+	d := Table("d", Column("c_id", Int()), ForeignKey("c_id").References("c", "id"))
+
+	assert.Equal(t, []TableElem{c, d, a, b}, sortTablesByDependency([]TableElem{d, b, a, c}))
+}
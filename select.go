@@ -1,6 +1,9 @@
 package qb
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // Selectable is any clause from which we can select columns and is suitable
 // as a FROM clause element
@@ -16,18 +19,20 @@ type Selectable interface {
 func Select(clauses ...Clause) SelectStmt {
 	return SelectStmt{
 		SelectList:    clauses,
-		GroupByClause: []ColumnElem{},
+		GroupByClause: []Clause{},
 		HavingClause:  []HavingClause{},
 	}
 }
 
 // SelectStmt is the base struct for building select statements
 type SelectStmt struct {
+	WithClause      []CTEClause
 	SelectList      []Clause
 	FromClause      Selectable
-	GroupByClause   []ColumnElem
+	GroupByClause   []Clause
 	OrderByClause   *OrderByClause
 	HavingClause    []HavingClause
+	NamedWindows    []NamedWindowClause
 	WhereClause     *WhereClause
 	ForUpdateClause *ForUpdateClause
 	OffsetValue     *int
@@ -40,6 +45,13 @@ func (s SelectStmt) Select(clauses ...Clause) SelectStmt {
 	return s
 }
 
+// With appends common table expressions to the WITH clause of the select
+// statement
+func (s SelectStmt) With(ctes ...CTEClause) SelectStmt {
+	s.WithClause = append(s.WithClause[:len(s.WithClause):len(s.WithClause)], ctes...)
+	return s
+}
+
 // From sets the from selectable of select statement
 func (s SelectStmt) From(selectable Selectable) SelectStmt {
 	s.FromClause = selectable
@@ -73,37 +85,75 @@ func (s SelectStmt) RightJoin(right Selectable, onClause ...Clause) SelectStmt {
 	return s.From(Join("RIGHT OUTER JOIN", s.FromClause, right, onClause...))
 }
 
+// FullOuterJoin appends a full outer join clause to select statement
+func (s SelectStmt) FullOuterJoin(right Selectable, onClause ...Clause) SelectStmt {
+	return s.From(Join("FULL OUTER JOIN", s.FromClause, right, onClause...))
+}
+
+// JoinUsing appends an inner join clause on the columns having the same name
+// in both tables to select statement
+// Select(orders.C("id")).From(orders).JoinUsing(customers, "customer_id")
+func (s SelectStmt) JoinUsing(right Selectable, columns ...string) SelectStmt {
+	return s.From(JoinUsing("INNER JOIN", s.FromClause, right, columns...))
+}
+
+// NaturalJoin appends a natural join clause to select statement, joining
+// the tables on all their columns having the same name
+func (s SelectStmt) NaturalJoin(right Selectable) SelectStmt {
+	return s.From(JoinUsing("NATURAL JOIN", s.FromClause, right))
+}
+
 // OrderBy generates an OrderByClause and sets select statement's orderbyclause
 // OrderBy(usersTable.C("id")).Asc()
 // OrderBy(usersTable.C("email")).Desc()
-func (s SelectStmt) OrderBy(columns ...ColumnElem) SelectStmt {
-	s.OrderByClause = &OrderByClause{columns, "ASC"}
+// Any clause can be used as an ordering term, like a CASE expression, and
+// each term can have its own direction with Asc and Desc
+// OrderBy(Desc(usersTable.C("last_login")).NullsLast(), usersTable.C("id"))
+// The terms having no direction are ascending
+func (s SelectStmt) OrderBy(columns ...Clause) SelectStmt {
+	s.OrderByClause = orderBy(columns)
 	return s
 }
 
-// Asc sets the t type of current order by clause
+// Asc sorts the terms of current order by clause in ascending order, except
+// the terms given a direction with the Asc and Desc functions
 // NOTE: Please use it after calling OrderBy()
 func (s SelectStmt) Asc() SelectStmt {
-	s.OrderByClause.t = "ASC"
+	s.OrderByClause = s.OrderByClause.direction("ASC")
 	return s
 }
 
-// Desc sets the t type of current order by clause
+// Desc sorts the terms of current order by clause in descending order, except
+// the terms given a direction with the Asc and Desc functions
 // NOTE: Please use it after calling OrderBy()
 func (s SelectStmt) Desc() SelectStmt {
-	s.OrderByClause.t = "DESC"
+	s.OrderByClause = s.OrderByClause.direction("DESC")
+	return s
+}
+
+// GroupBy appends columns, or expressions like a CASE, to group by clause
+// of the select statement.
+// An expression having bound values that is also in the select list is
+// grouped by its position, so the database sees the same expression
+// Select(bucket, Count(orders.C("id"))).From(orders).GroupBy(bucket)
+// gives SELECT CASE ... END, COUNT(id) FROM orders GROUP BY 1
+// The ORDER BY terms are compiled the same way, except the ones having
+// NULLS FIRST or NULLS LAST on the dialects emulating them
+func (s SelectStmt) GroupBy(cols ...Clause) SelectStmt {
+	s.GroupByClause = append(s.GroupByClause[:len(s.GroupByClause):len(s.GroupByClause)], cols...)
 	return s
 }
 
-// GroupBy appends columns to group by clause of the select statement
-func (s SelectStmt) GroupBy(cols ...ColumnElem) SelectStmt {
-	s.GroupByClause = append(s.GroupByClause, cols...)
+// Window appends a named window definition to the WINDOW clause of the
+// select statement. The window can then be referenced with WindowName
+func (s SelectStmt) Window(name string, window WindowClause) SelectStmt {
+	s.NamedWindows = append(s.NamedWindows[:len(s.NamedWindows):len(s.NamedWindows)], NamedWindowClause{name, window})
 	return s
 }
 
 // Having appends a having clause to select statement
 func (s SelectStmt) Having(aggregate AggregateClause, op string, value interface{}) SelectStmt {
-	s.HavingClause = append(s.HavingClause, HavingClause{aggregate, op, value})
+	s.HavingClause = append(s.HavingClause[:len(s.HavingClause):len(s.HavingClause)], HavingClause{aggregate, op, value})
 	return s
 }
 
@@ -141,64 +191,160 @@ func (s SelectStmt) Accept(context *CompilerContext) string {
 func (s SelectStmt) Build(dialect Dialect) *Stmt {
 	context := NewCompilerContext(dialect)
 	statement := Statement()
-	statement.AddSQLClause(s.Accept(context))
+	sql := s.Accept(context)
+	if err := context.Err(); err != nil {
+		statement.SetError(err)
+		return statement
+	}
+	statement.AddSQLClause(sql)
 	statement.AddBinding(context.Binds...)
 
 	return statement
 }
 
+// All returns the columns of the select list, so the select statement can
+// be used as a sub query
+func (s SelectStmt) All() []Clause {
+	var clauses []Clause
+	for _, col := range s.ColumnList() {
+		clauses = append(clauses, col)
+	}
+	return clauses
+}
+
+// ColumnList returns the columns of the select list, with an empty "Table"
+// field. Alias() the statement to refer to them with a table name
+func (s SelectStmt) ColumnList() []ColumnElem {
+	return selectListColumns(s.SelectList, "")
+}
+
+// C returns the select list column with the given name. An unknown column
+// makes the statements using it fail to build
+func (s SelectStmt) C(name string) ColumnElem {
+	for _, col := range s.ColumnList() {
+		if col.Name == name {
+			return col
+		}
+	}
+	return unknownColumn(name, "", "No such column '%s' in select statement", name)
+}
+
+// DefaultName returns an empty string, a sub query having no name
+func (s SelectStmt) DefaultName() string {
+	return ""
+}
+
+// selectListColumns returns the columns of a select list, with their
+// "Table" field set to the given table name.
+// The labeled expressions are columns named by their label. The other
+// expressions have no name, they are unknown columns whose error is
+// returned by the Build of the statements using them.
+func selectListColumns(selectList []Clause, table string) []ColumnElem {
+	var cols []ColumnElem
+	for i, clause := range selectList {
+		switch c := clause.(type) {
+		case ColumnElem:
+			c.Table = table
+			cols = append(cols, c)
+		case LabeledClause:
+			col := Column(c.Name, TypeElem{})
+			col.Table = table
+			cols = append(cols, col)
+		default:
+			cols = append(cols, unknownColumn("", table, "The expression %d of the select list has no name, it must be Labeled() to be a column", i+1))
+		}
+	}
+	return cols
+}
+
 type joinOnClauseCandidate struct {
-	source TableElem
+	source Selectable
 	fkey   ForeignKeyConstraint
-	target TableElem
+	target Selectable
 }
 
+// joinedSelectables returns the selectables joined by a join clause, or the
+// selectable itself if it is not a join
+func joinedSelectables(sel Selectable) []Selectable {
+	if join, ok := sel.(JoinClause); ok {
+		return append(joinedSelectables(join.Left), joinedSelectables(join.Right)...)
+	}
+	return []Selectable{sel}
+}
+
+// getTable returns the table of a selectable, which may be an aliased table
 func getTable(sel Selectable) (TableElem, bool) {
 	switch t := sel.(type) {
 	case TableElem:
 		return t, true
 	case *TableElem:
 		return *t, true
+	case AliasClause:
+		return getTable(t.Selectable)
 	default:
 		return TableElem{}, false
 	}
 }
 
-// GuessJoinOnClause finds a join 'ON' clause between two tables
-func GuessJoinOnClause(left Selectable, right Selectable) Clause {
-	leftTable, ok := getTable(left)
-	if !ok {
-		panic("left Selectable is not a Table: Cannot guess join onClause")
+// GuessJoinOnClause finds a join 'ON' clause between two tables, or aliased
+// tables, from the foreign key linking them. If left is a join, the foreign
+// key is searched between right and all the joined tables.
+// A table joined with itself is joined on the foreign keys of the left
+// side, so categories joined with Alias("parent", categories) is joined on
+// categories.parent_id = parent.id
+func GuessJoinOnClause(left Selectable, right Selectable) (Clause, error) {
+	return guessJoinOnClause(left, right, "")
+}
+
+// guessJoinOnClause finds a join 'ON' clause between two tables from their
+// foreign keys, or from the foreign key named fkeyName if not empty
+func guessJoinOnClause(left Selectable, right Selectable, fkeyName string) (Clause, error) {
+	var lefts []Selectable
+	for _, sel := range joinedSelectables(left) {
+		if _, ok := getTable(sel); ok {
+			lefts = append(lefts, sel)
+		}
+	}
+	if len(lefts) == 0 {
+		return nil, fmt.Errorf("left Selectable is not a Table: Cannot guess join onClause")
 	}
 	rightTable, ok := getTable(right)
 	if !ok {
-		panic("right Selectable is not a Table: Cannot guess join onClause")
+		return nil, fmt.Errorf("right Selectable is not a Table: Cannot guess join onClause")
 	}
 
 	var candidates []joinOnClauseCandidate
-
-	for _, fkey := range leftTable.ForeignKeyConstraints.FKeys {
-		if fkey.RefTable != rightTable.Name {
-			continue
+	addCandidates := func(source Selectable, sourceTable TableElem, target Selectable, targetTable TableElem) {
+		for _, fkey := range sourceTable.ForeignKeyConstraints.FKeys {
+			if fkey.RefTable != targetTable.Name || (fkeyName != "" && fkey.Name != fkeyName) {
+				continue
+			}
+			candidates = append(
+				candidates,
+				joinOnClauseCandidate{source, fkey, target})
 		}
-		candidates = append(
-			candidates,
-			joinOnClauseCandidate{leftTable, fkey, rightTable})
 	}
-
-	for _, fkey := range rightTable.ForeignKeyConstraints.FKeys {
-		if fkey.RefTable != leftTable.Name {
-			continue
+	var leftNames []string
+	for _, left := range lefts {
+		leftTable, _ := getTable(left)
+		leftNames = append(leftNames, leftTable.Name)
+		addCandidates(left, leftTable, right, rightTable)
+		if leftTable.Name != rightTable.Name {
+			addCandidates(right, rightTable, left, leftTable)
 		}
-		candidates = append(
-			candidates,
-			joinOnClauseCandidate{rightTable, fkey, leftTable})
 	}
+	leftName := strings.Join(leftNames, ", ")
+
 	switch len(candidates) {
 	case 0:
-		panic(fmt.Sprintf(
+		if fkeyName != "" {
+			return nil, fmt.Errorf(
+				"No foreign key %s found between %s and %s",
+				fkeyName, leftName, rightTable.Name)
+		}
+		return nil, fmt.Errorf(
 			"No foreign keys found between %s and %s",
-			leftTable.Name, rightTable.Name))
+			leftName, rightTable.Name)
 	case 1:
 		candidate := candidates[0]
 		var clauses []Clause
@@ -210,47 +356,105 @@ func GuessJoinOnClause(left Selectable, right Selectable) Clause {
 			)
 		}
 		if len(clauses) == 1 {
-			return clauses[0]
+			return clauses[0], nil
 		}
-		return And(clauses...)
+		return And(clauses...), nil
 	default:
-		panic(fmt.Sprintf(
-			"Found %d foreign keys between %s and %s",
-			len(candidates), leftTable.Name, rightTable.Name))
+		return nil, fmt.Errorf(
+			"Found %d foreign keys between %s and %s, use JoinOnForeignKey to pick one by its name",
+			len(candidates), leftName, rightTable.Name)
 	}
 }
 
+// JoinOnForeignKey returns a join condition on the foreign key of the given
+// name, for the tables having several foreign keys between them
+// Select(messages.C("body")).From(messages).InnerJoin(users, JoinOnForeignKey("fk_messages_sender"))
+func JoinOnForeignKey(name string) ForeignKeyJoinClause {
+	return ForeignKeyJoinClause{name}
+}
+
+// ForeignKeyJoinClause is a join condition on a named foreign key. It is
+// replaced by the columns of the foreign key when the join is made
+type ForeignKeyJoinClause struct {
+	Name string
+}
+
+// Accept records an error, as the clause can only be a join condition
+func (c ForeignKeyJoinClause) Accept(context *CompilerContext) string {
+	context.AddError(Error{
+		Code: ErrInterface,
+		Orig: fmt.Errorf("JoinOnForeignKey(%s) can only be a join condition", c.Name),
+	})
+	return ""
+}
+
 // MakeJoinOnClause assemble a 'ON' clause for a join from either:
-// 0 clause: attempt to guess the join clause (only if left & right are tables),
-//           otherwise panics
-// 1 clause: returns it
-// 2 clauses: returns a Eq() of both
-// otherwise if panics
-func MakeJoinOnClause(left Selectable, right Selectable, onClause ...Clause) Clause {
+// 0 clause: attempt to guess the join clause (only if left & right are tables)
+// 1 clause: returns it, or the condition of a JoinOnForeignKey
+// 2 clauses that are not conditions, like columns: returns a Eq() of both
+// Several conditions must be combined with And(), as the clauses are
+// otherwise ambiguous: an error is returned
+func MakeJoinOnClause(left Selectable, right Selectable, onClause ...Clause) (Clause, error) {
 	switch len(onClause) {
 	case 0:
 		return GuessJoinOnClause(left, right)
 	case 1:
-		return onClause[0]
+		if fkey, ok := onClause[0].(ForeignKeyJoinClause); ok {
+			return guessJoinOnClause(left, right, fkey.Name)
+		}
+		return onClause[0], nil
 	case 2:
-		return Eq(onClause[0], onClause[1])
+		if !isCondition(onClause[0]) && !isCondition(onClause[1]) {
+			return Eq(onClause[0], onClause[1]), nil
+		}
+	}
+	return nil, fmt.Errorf(
+		"Cannot join %s and %s on %d clauses, the conditions must be combined with And()",
+		left.DefaultName(), right.DefaultName(), len(onClause),
+	)
+}
+
+// isCondition returns true if a clause is a boolean condition
+func isCondition(clause Clause) bool {
+	switch clause.(type) {
+	case BinaryExpressionClause, InClause, ILikeClause, BetweenClause,
+		NotClause, ExistsClause, CombinerClause, ForeignKeyJoinClause:
+		return true
 	default:
-		panic("Cannot make a join condition with more than 2 clauses")
+		return false
 	}
 }
 
 // Join returns a new JoinClause
 // onClause can be one of:
-// - 0 clause: attempt to guess the join clause (only if left & right are tables),
-//             otherwise panics
-// - 1 clause: use it directly
-// - 2 clauses: use a Eq() of both
+// - 0 clause: attempt to guess the join clause (only if left & right are tables)
+// - 1 clause: use it directly, or the foreign key of a JoinOnForeignKey
+// - 2 clauses that are not conditions, like columns: use a Eq() of both
+// Several conditions must be combined with And()
+// Join("INNER JOIN", users, sessions, And(users.C("id").Eq(sessions.C("user_id")), sessions.C("active").Eq(true)))
+// If the join clause cannot be made, the error is returned by the Build
+// of the statement
 func Join(joinType string, left Selectable, right Selectable, onClause ...Clause) JoinClause {
+	join := JoinClause{
+		JoinType: joinType,
+		Left:     left,
+		Right:    right,
+	}
+	join.OnClause, join.err = MakeJoinOnClause(left, right, onClause...)
+	if join.err != nil {
+		join.err = Error{Code: ErrInterface, Orig: join.err}
+	}
+	return join
+}
+
+// JoinUsing returns a new JoinClause on the columns having the same name on
+// both sides
+func JoinUsing(joinType string, left Selectable, right Selectable, columns ...string) JoinClause {
 	return JoinClause{
 		JoinType: joinType,
 		Left:     left,
 		Right:    right,
-		OnClause: MakeJoinOnClause(left, right, onClause...),
+		Using:    columns,
 	}
 }
 
@@ -261,6 +465,16 @@ type JoinClause struct {
 	Left     Selectable
 	Right    Selectable
 	OnClause Clause
+	// Using are the columns of a JOIN ... USING (...)
+	Using []string
+
+	err error
+}
+
+// Err returns the error that prevented the join clause from being made, if
+// any
+func (c JoinClause) Err() error {
+	return c.err
 }
 
 // Accept calls the compiler VisitJoin method
@@ -281,13 +495,14 @@ func (c JoinClause) ColumnList() []ColumnElem {
 // C returns the first column with the given name
 // If columns from both sides of the join match the name,
 // the one from the left side will be returned.
+// An unknown column makes the statements using it fail to build
 func (c JoinClause) C(name string) ColumnElem {
 	for _, c := range c.ColumnList() {
 		if c.Name == name {
 			return c
 		}
 	}
-	panic(fmt.Sprintf("No such column '%s' in join %v", name, c))
+	return unknownColumn(name, "", "No such column '%s' in join", name)
 }
 
 // DefaultName returns an empty string because Joins have no name by default
@@ -295,18 +510,6 @@ func (c JoinClause) DefaultName() string {
 	return ""
 }
 
-// OrderByClause is the base struct for generating order by clauses when using select
-// It satisfies SQLClause interface
-type OrderByClause struct {
-	columns []ColumnElem
-	t       string
-}
-
-// Accept generates an order by clause
-func (c OrderByClause) Accept(context *CompilerContext) string {
-	return context.Compiler.VisitOrderBy(context, c)
-}
-
 // HavingClause is the base struct for generating having clauses when using select
 // It satisfies SQLClause interface
 type HavingClause struct {
@@ -330,6 +533,27 @@ func (s ForUpdateClause) Accept(context *CompilerContext) string {
 	return context.Compiler.VisitForUpdate(context, s)
 }
 
+// Labeled names an expression of a select list, as in
+// SELECT COUNT(id) AS total. The labeled expressions are columns of the
+// select statement, named by their label
+func Labeled(name string, clause Clause) LabeledClause {
+	return LabeledClause{
+		Name:   name,
+		Clause: clause,
+	}
+}
+
+// LabeledClause is an expression of a select list named by a label
+type LabeledClause struct {
+	Name   string
+	Clause Clause
+}
+
+// Accept calls the compiler VisitLabeled function
+func (c LabeledClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitLabeled(context, c)
+}
+
 // Alias returns a new AliasClause
 func Alias(name string, selectable Selectable) AliasClause {
 	return AliasClause{
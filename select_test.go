@@ -43,6 +43,15 @@ func (suite *SelectTestSuite) TestSelectSimple() {
 	assert.Equal(suite.T(), "SELECT id\nFROM users", sel.Accept(suite.ctx))
 }
 
+func (suite *SelectTestSuite) TestSelectUnknownColumn() {
+	stmt := Select(suite.users.C("name")).From(suite.users).Build(suite.dialect)
+	assert.Equal(suite.T(), "", stmt.SQL())
+	qbErr, ok := stmt.Err().(Error)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), ErrInterface, qbErr.Code)
+	assert.Contains(suite.T(), qbErr.Error(), "name")
+}
+
 func (suite *SelectTestSuite) TestSelectAggregate() {
 	sel := Select(suite.users.C("id")).From(suite.users)
 	selCount := sel.Select(Count(suite.users.C("id")))
@@ -118,7 +127,7 @@ func (suite *SelectTestSuite) TestSelectInnerJoin() {
 	binds := suite.ctx.Binds
 
 	assert.Equal(suite.T(), suite.sessions.C("user_id"), selInnerJoin.FromClause.C("user_id"))
-	assert.Panics(suite.T(), func() { selInnerJoin.FromClause.C("invalid") })
+	assert.NotNil(suite.T(), selInnerJoin.FromClause.C("invalid").Err())
 	assert.Equal(suite.T(), len(suite.sessions.All())+len(suite.users.All()), len(selInnerJoin.FromClause.All()))
 
 	assert.Equal(suite.T(), "SELECT sessions.id, sessions.auth_token\nFROM sessions\nINNER JOIN users ON sessions.user_id = users.id\nWHERE sessions.user_id = ?", sql)
@@ -177,6 +186,33 @@ func (suite *SelectTestSuite) TestSelectGroupByHaving() {
 	assert.Equal(suite.T(), []interface{}{4}, binds)
 }
 
+func (suite *SelectTestSuite) TestSelectFromBase() {
+	sessions := suite.sessions
+	ctes := []CTEClause{
+		With("a", Select(sessions.C("id")).From(sessions)),
+		With("b", Select(sessions.C("id")).From(sessions)),
+		With("c", Select(sessions.C("id")).From(sessions)),
+		With("d", Select(sessions.C("id")).From(sessions)),
+	}
+	base := Select(Count(sessions.C("id"))).
+		From(sessions).
+		With(ctes[0]).With(ctes[1]).With(ctes[2]).
+		GroupBy(sessions.C("id")).GroupBy(sessions.C("user_id")).GroupBy(sessions.C("auth_token")).
+		Having(Count(sessions.C("id")), ">", 1).Having(Count(sessions.C("id")), ">", 2).Having(Count(sessions.C("id")), ">", 3)
+
+	first := base.With(ctes[3]).GroupBy(sessions.C("id")).Having(Count(sessions.C("id")), ">", 4)
+	second := base.With(ctes[0]).GroupBy(sessions.C("user_id")).Having(Count(sessions.C("id")), ">", 5)
+	assert.Equal(suite.T(), ctes[3], first.WithClause[3])
+	assert.Equal(suite.T(), ctes[0], second.WithClause[3])
+	assert.Equal(suite.T(), sessions.C("id"), first.GroupByClause[3])
+	assert.Equal(suite.T(), sessions.C("user_id"), second.GroupByClause[3])
+	assert.Equal(suite.T(), 4, first.HavingClause[3].value)
+	assert.Equal(suite.T(), 5, second.HavingClause[3].value)
+	assert.Len(suite.T(), base.WithClause, 3)
+	assert.Len(suite.T(), base.GroupByClause, 3)
+	assert.Len(suite.T(), base.HavingClause, 3)
+}
+
 func (suite *SelectTestSuite) TestSelectAliasFrom() {
 	sessionAlias := Alias("newname", suite.sessions)
 
@@ -238,31 +274,311 @@ func (suite *SelectTestSuite) TestSelectGuessJoinOnClause() {
 		ForeignKey("c1", "c2").References("t1", "c1", "c2"),
 	)
 
-	assert.Panics(suite.T(), func() {
-		GuessJoinOnClause(t1, Alias("tt", t3))
-	})
+	guess := func(left Selectable, right Selectable) string {
+		clause, err := GuessJoinOnClause(left, right)
+		assert.Nil(suite.T(), err)
+		return clause.Accept(suite.ctx)
+	}
 
-	assert.Panics(suite.T(), func() {
-		GuessJoinOnClause(Alias("tt", t3), t2)
-	})
+	_, err := GuessJoinOnClause(t1, Select(t3.C("c1")).From(t3))
+	assert.NotNil(suite.T(), err)
 
-	assert.Panics(suite.T(), func() {
-		GuessJoinOnClause(t1, &t2)
-	})
+	_, err = GuessJoinOnClause(Alias("tt", t3), t2)
+	assert.EqualError(suite.T(), err, "Found 2 foreign keys between t3 and t2, use JoinOnForeignKey to pick one by its name")
 
-	assert.Equal(suite.T(), "t3.c1 = t1.c1", GuessJoinOnClause(t3, t1).Accept(suite.ctx))
-	assert.Equal(suite.T(), "t3.c1 = t1.c1", GuessJoinOnClause(t1, t3).Accept(suite.ctx))
-	assert.Equal(suite.T(), "(t4.c1 = t1.c1 AND t4.c2 = t1.c2)", GuessJoinOnClause(t4, t1).Accept(suite.ctx))
+	assert.Equal(suite.T(), "t3.c1 = t1.c1", guess(t3, t1))
+	assert.Equal(suite.T(), "t3.c1 = t1.c1", guess(t1, t3))
+	assert.Equal(suite.T(), "tt.c1 = t1.c1", guess(t1, Alias("tt", t3)))
+	assert.Equal(suite.T(), "t3.c1 = t1.c1", guess(t1, &t3))
+	assert.Equal(suite.T(), "(t4.c1 = t1.c1 AND t4.c2 = t1.c2)", guess(t4, t1))
 
-	assert.Panics(suite.T(), func() {
-		GuessJoinOnClause(t2, t3)
-	})
+	_, err = GuessJoinOnClause(t1, t2)
+	assert.EqualError(suite.T(), err, "No foreign keys found between t1 and t2")
 }
 
 func (suite *SelectTestSuite) TestSelectMakeJoinOnClause() {
-	assert.Panics(suite.T(), func() {
-		MakeJoinOnClause(TableElem{}, TableElem{}, And(), And(), And())
-	})
+	t1 := Table("t1", Column("c1", Int()), Column("c2", Int()))
+	t2 := Table("t2", Column("c1", Int()), Column("c2", Int()))
+
+	clause, err := MakeJoinOnClause(t1, t2, t1.C("c1"), t2.C("c1"))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "t1.c1 = t2.c1", clause.Accept(suite.ctx))
+
+	clause, err = MakeJoinOnClause(t1, t2, And(Eq(t1.C("c1"), t2.C("c1")), Eq(t1.C("c2"), t2.C("c2")), Gt(t2.C("c2"), 0)))
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), "(t1.c1 = t2.c1 AND t1.c2 = t2.c2 AND t2.c2 > ?)", clause.Accept(suite.ctx))
+
+	// the conditions are not combined implicitly
+	_, err = MakeJoinOnClause(t1, t2, Eq(t1.C("c1"), t2.C("c1")), Eq(t1.C("c2"), t2.C("c2")), Gt(t2.C("c2"), 0))
+	assert.EqualError(suite.T(), err, "Cannot join t1 and t2 on 3 clauses, the conditions must be combined with And()")
+	_, err = MakeJoinOnClause(t1, t2, t1.C("c1"), Eq(t1.C("c2"), t2.C("c2")))
+	assert.EqualError(suite.T(), err, "Cannot join t1 and t2 on 2 clauses, the conditions must be combined with And()")
+
+	statement := Select(t1.C("c1")).From(t1).InnerJoin(t2, Eq(t1.C("c1"), t2.C("c1")), Eq(t1.C("c2"), t2.C("c2"))).Build(suite.dialect)
+	assert.Equal(suite.T(), ErrInterface, statement.Err().(Error).Code)
+
+	_, err = MakeJoinOnClause(t1, t2, JoinOnForeignKey("fk_t2"))
+	assert.EqualError(suite.T(), err, "No foreign key fk_t2 found between t1 and t2")
+}
+
+func (suite *SelectTestSuite) TestSelectJoins() {
+	categories := Table(
+		"categories",
+		Column("id", Int()).PrimaryKey(),
+		Column("name", Varchar()),
+		Column("parent_id", Int()).Null(),
+		ForeignKey("parent_id").References("categories", "id"),
+	)
+	users := Table(
+		"users",
+		Column("id", Int()).PrimaryKey(),
+		Column("name", Varchar()),
+	)
+	messages := Table(
+		"messages",
+		Column("id", Int()).PrimaryKey(),
+		Column("sender_id", Int()),
+		Column("recipient_id", Int()),
+		ForeignKey("sender_id").Named("fk_sender").References("users", "id"),
+		ForeignKey("recipient_id").Named("fk_recipient").References("users", "id"),
+	)
+
+	parent := Alias("parent", categories)
+	sel := Select(categories.C("name"), parent.C("name")).From(categories).LeftJoin(parent)
+	assert.Equal(suite.T(), "SELECT categories.name, parent.name\n"+
+		"FROM categories\n"+
+		"LEFT OUTER JOIN categories AS parent ON categories.parent_id = parent.id", sel.Accept(suite.ctx))
+
+	sender := Alias("sender", users)
+	recipient := Alias("recipient", users)
+	sel = Select(sender.C("name"), recipient.C("name")).
+		From(messages).
+		InnerJoin(sender, JoinOnForeignKey("fk_sender")).
+		InnerJoin(recipient, JoinOnForeignKey("fk_recipient"))
+	assert.Equal(suite.T(), "SELECT sender.name, recipient.name\n"+
+		"FROM messages\n"+
+		"INNER JOIN users AS sender ON messages.sender_id = sender.id\n"+
+		"INNER JOIN users AS recipient ON messages.recipient_id = recipient.id", sel.Accept(suite.ctx))
+
+	sel = Select(messages.C("id")).From(messages).InnerJoin(users)
+	statement := sel.Build(suite.dialect)
+	assert.Equal(suite.T(), ErrInterface, statement.Err().(Error).Code)
+	assert.Contains(suite.T(), statement.Err().Error(), "Found 2 foreign keys between messages and users")
+	assert.Equal(suite.T(), "", statement.SQL())
+
+	statement = Select(messages.C("id")).From(messages).Where(JoinOnForeignKey("fk_sender")).Build(suite.dialect)
+	assert.Equal(suite.T(), ErrInterface, statement.Err().(Error).Code)
+
+	sel = Select(users.C("name")).From(users).FullOuterJoin(messages, users.C("id"), messages.C("sender_id"))
+	assert.Equal(suite.T(), "SELECT users.name\n"+
+		"FROM users\n"+
+		"FULL OUTER JOIN messages ON users.id = messages.sender_id", sel.Accept(suite.ctx))
+
+	sel = Select(messages.C("id")).From(messages).JoinUsing(categories, "id", "name")
+	assert.Equal(suite.T(), "SELECT messages.id\n"+
+		"FROM messages\n"+
+		"INNER JOIN categories USING (id, name)", sel.Accept(suite.ctx))
+
+	sel = Select(users.C("name")).From(users).NaturalJoin(categories)
+	assert.Equal(suite.T(), "SELECT users.name\n"+
+		"FROM users\n"+
+		"NATURAL JOIN categories", sel.Accept(suite.ctx))
+}
+
+func (suite *SelectTestSuite) TestSelectSelectable() {
+	sel := Select(suite.users.C("id"), suite.users.C("email"), Count(suite.users.C("id"))).
+		From(suite.users)
+
+	assert.Equal(suite.T(), "", sel.DefaultName())
+	cols := sel.ColumnList()
+	assert.Equal(suite.T(), 3, len(cols))
+	assert.Equal(suite.T(), "id", cols[0].Name)
+	assert.Equal(suite.T(), "", cols[0].Table)
+	assert.Equal(suite.T(), BigInt(), cols[0].Type)
+	assert.Equal(suite.T(), "email", cols[1].Name)
+	assert.Nil(suite.T(), cols[1].Err())
+	// the unlabeled expressions have no name
+	assert.EqualError(suite.T(), cols[2].Err(), "Interface error: The expression 3 of the select list has no name, it must be Labeled() to be a column")
+	assert.Equal(suite.T(), 3, len(sel.All()))
+	assert.Equal(suite.T(), "email", sel.C("email").Name)
+	assert.Equal(suite.T(), "", sel.C("email").Table)
+	assert.NotNil(suite.T(), sel.C("password").Err())
+
+	sub := Alias("sub", sel)
+	stmt := Select(sub.All()...).From(sub).Build(suite.dialect)
+	assert.NotNil(suite.T(), stmt.Err())
+	assert.Equal(suite.T(), "", stmt.SQL())
+}
+
+func (suite *SelectTestSuite) TestSelectLabeled() {
+	total := Labeled("total", Count(suite.sessions.C("id")))
+	sel := Select(suite.sessions.C("user_id"), total).
+		From(suite.sessions).
+		GroupBy(suite.sessions.C("user_id"))
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT user_id, COUNT(id) AS total",
+		"FROM sessions",
+		"GROUP BY user_id",
+	}, "\n"), sel.Accept(suite.ctx))
+
+	cols := sel.ColumnList()
+	assert.Equal(suite.T(), 2, len(cols))
+	assert.Equal(suite.T(), "total", cols[1].Name)
+	assert.Nil(suite.T(), cols[1].Err())
+
+	counts := Alias("counts", sel)
+	stmt := Select(counts.All()...).
+		From(counts).
+		Where(counts.C("total").Gt(3)).
+		Build(suite.dialect)
+	assert.Nil(suite.T(), stmt.Err())
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT user_id, total",
+		"FROM (SELECT sessions.user_id, COUNT(sessions.id) AS total",
+		"FROM sessions",
+		"GROUP BY user_id) AS counts",
+		"WHERE total > ?;",
+	}, "\n"), stmt.SQL())
+
+	// the expressions with bound values are grouped by their position,
+	// labeled or not
+	bucket := Case().When(suite.sessions.C("id").Lt(100), "old").Else("new")
+	stmt = Select(Labeled("bucket", bucket), Count(suite.sessions.C("id"))).
+		From(suite.sessions).
+		GroupBy(bucket).
+		Build(suite.dialect)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT CASE WHEN id < ? THEN ? ELSE ? END AS bucket, COUNT(id)",
+		"FROM sessions",
+		"GROUP BY 1;",
+	}, "\n"), stmt.SQL())
+}
+
+func (suite *SelectTestSuite) TestSelectDerivedTable() {
+	sub := Select(suite.sessions.C("user_id")).
+		From(suite.sessions).
+		Where(suite.sessions.C("auth_token").Eq("token"))
+
+	sel := Select(sub.C("user_id")).From(sub)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT user_id",
+		"FROM (SELECT sessions.user_id",
+		"FROM sessions",
+		"WHERE sessions.auth_token = ?) AS anon_1",
+	}, "\n"), sel.Accept(suite.ctx))
+	assert.Equal(suite.T(), []interface{}{"token"}, suite.ctx.Binds)
+
+	// the columns of the derived table are not qualified, even in a sub query
+	tokens := Select(sub.C("user_id")).From(sub).Where(sub.C("user_id").Gt(1))
+	sel = Select(suite.users.C("email")).
+		From(suite.users).
+		Where(suite.users.C("id").In(tokens))
+	suite.ctx = NewCompilerContext(suite.dialect)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT email",
+		"FROM users",
+		"WHERE id IN (SELECT user_id",
+		"FROM (SELECT sessions.user_id",
+		"FROM sessions",
+		"WHERE sessions.auth_token = ?) AS anon_1",
+		"WHERE user_id > ?)",
+	}, "\n"), sel.Accept(suite.ctx))
+
+	sel = Select(suite.users.C("email")).
+		From(suite.users).
+		InnerJoin(sub, suite.users.C("id"), sub.C("user_id")).
+		InnerJoin(tokens, suite.users.C("id"), tokens.C("user_id"))
+	suite.ctx = NewCompilerContext(suite.dialect)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT users.email",
+		"FROM users",
+		"INNER JOIN (SELECT sessions.user_id",
+		"FROM sessions",
+		"WHERE sessions.auth_token = ?) AS anon_1 ON users.id = user_id",
+		"INNER JOIN (SELECT user_id",
+		"FROM (SELECT sessions.user_id",
+		"FROM sessions",
+		"WHERE sessions.auth_token = ?) AS anon_3",
+		"WHERE user_id > ?) AS anon_2 ON users.id = user_id",
+	}, "\n"), sel.Accept(suite.ctx))
+
+	active := Alias("active", sub)
+	sel = Select(suite.users.C("email")).
+		From(suite.users).
+		InnerJoin(active, suite.users.C("id"), active.C("user_id"))
+	suite.ctx = NewCompilerContext(suite.dialect)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT users.email",
+		"FROM users",
+		"INNER JOIN (SELECT sessions.user_id",
+		"FROM sessions",
+		"WHERE sessions.auth_token = ?) AS active ON users.id = active.user_id",
+	}, "\n"), sel.Accept(suite.ctx))
+	assert.False(suite.T(), suite.ctx.InSubQuery)
+}
+
+func (suite *SelectTestSuite) TestSelectScalarSubQuery() {
+	sessionCount := Select(Count(suite.sessions.C("id"))).
+		From(suite.sessions).
+		Where(suite.sessions.C("user_id").Eq(suite.users.C("id")))
+
+	sel := Select(suite.users.C("email"), sessionCount).
+		From(suite.users).
+		Where(Gt(sessionCount, 2))
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT email, (SELECT COUNT(sessions.id)",
+		"FROM sessions",
+		"WHERE sessions.user_id = users.id)",
+		"FROM users",
+		"WHERE (SELECT COUNT(sessions.id)",
+		"FROM sessions",
+		"WHERE sessions.user_id = users.id) > ?",
+	}, "\n"), sel.Accept(suite.ctx))
+	assert.Equal(suite.T(), []interface{}{2}, suite.ctx.Binds)
+}
+
+func (suite *SelectTestSuite) TestSelectInSubQuery() {
+	sub := Select(suite.sessions.C("user_id")).From(suite.sessions)
+
+	sel := Select(suite.users.C("email")).
+		From(suite.users).
+		Where(suite.users.C("id").In(sub))
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT email",
+		"FROM users",
+		"WHERE id IN (SELECT sessions.user_id",
+		"FROM sessions)",
+	}, "\n"), sel.Accept(suite.ctx))
+
+	sel = Select(suite.users.C("email")).
+		From(suite.users).
+		Where(NotIn(suite.users.C("id"), sub.Where(Exists(
+			Select(suite.users.C("id")).
+				From(suite.users).
+				Where(suite.users.C("email").Eq("a@b.c")),
+		))))
+	suite.ctx = NewCompilerContext(suite.dialect)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT email",
+		"FROM users",
+		"WHERE id NOT IN (SELECT sessions.user_id",
+		"FROM sessions",
+		"WHERE EXISTS(SELECT users.id",
+		"FROM users",
+		"WHERE users.email = ?))",
+	}, "\n"), sel.Accept(suite.ctx))
+	assert.False(suite.T(), suite.ctx.InSubQuery)
+
+	sel = Select(suite.users.C("email")).
+		From(suite.users).
+		Where(suite.users.C("id").Eq(Select(Max(suite.sessions.C("user_id"))).From(suite.sessions)))
+	suite.ctx = NewCompilerContext(suite.dialect)
+	assert.Equal(suite.T(), strings.Join([]string{
+		"SELECT email",
+		"FROM users",
+		"WHERE id = (SELECT MAX(sessions.user_id)",
+		"FROM sessions)",
+	}, "\n"), sel.Accept(suite.ctx))
 }
 
 func TestSelectTestSuite(t *testing.T) {
@@ -23,6 +23,7 @@ type Stmt struct {
 	bindings     []interface{}
 	delimiter    string
 	bindingIndex int
+	err          error
 }
 
 // Text is for executing raw sql
@@ -64,6 +65,23 @@ func (s *Stmt) Bindings() []interface{} {
 	return s.bindings
 }
 
+// SetError marks the statement as invalid
+func (s *Stmt) SetError(err error) {
+	s.err = err
+}
+
+// Err returns the error that prevented the statement from being built, if
+// any
+func (s *Stmt) Err() error {
+	return s.err
+}
+
+// Build returns the statement itself, so that a statement returned by
+// PreparedStmt.Bind can be executed by the engine
+func (s *Stmt) Build(dialect Dialect) *Stmt {
+	return s
+}
+
 // SQL returns the query struct sql statement
 func (s *Stmt) SQL() string {
 	if len(s.clauses) > 0 {
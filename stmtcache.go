@@ -0,0 +1,236 @@
+package qb
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// StmtCacheStats are the counters of the prepared statement cache of an
+// engine, including the caches of its transactions
+type StmtCacheStats struct {
+	// Hits is the number of statements run with a cached prepared statement
+	Hits int64
+	// Misses is the number of statements that had to be prepared
+	Misses int64
+}
+
+// runner is the common interface of sqlx.DB, sqlx.Tx and stmtCache used to
+// run the statements
+type runner interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// preparer is a runner that can prepare statements
+type preparer interface {
+	runner
+	PreparexContext(ctx context.Context, query string) (*sqlx.Stmt, error)
+}
+
+// cachedStmt is a prepared statement of a stmtCache. It is closed when it
+// is evicted and no longer in use.
+type cachedStmt struct {
+	query   string
+	stmt    *sqlx.Stmt
+	refs    int
+	evicted bool
+	elem    *list.Element
+}
+
+// stmtCache is a LRU cache of prepared statements keyed by their SQL. It
+// implements runner, so the statements are run by the engine or the
+// transaction with their prepared statement.
+type stmtCache struct {
+	db    preparer
+	size  int
+	stats *StmtCacheStats
+
+	mu    sync.Mutex
+	lru   *list.List
+	stmts map[string]*cachedStmt
+}
+
+// newStmtCache returns a cache of at most size statements prepared with db.
+// The counters of stats are incremented atomically, so they can be shared.
+func newStmtCache(db preparer, size int, stats *StmtCacheStats) *stmtCache {
+	return &stmtCache{
+		db:    db,
+		size:  size,
+		stats: stats,
+		lru:   list.New(),
+		stmts: map[string]*cachedStmt{},
+	}
+}
+
+// acquire returns the prepared statement of a query, preparing it if it is
+// not in the cache. It must be released after use.
+func (c *stmtCache) acquire(ctx context.Context, query string) (*cachedStmt, error) {
+	c.mu.Lock()
+	if s, ok := c.stmts[query]; ok {
+		c.lru.MoveToFront(s.elem)
+		s.refs++
+		c.mu.Unlock()
+		atomic.AddInt64(&c.stats.Hits, 1)
+		return s, nil
+	}
+	c.mu.Unlock()
+
+	atomic.AddInt64(&c.stats.Misses, 1)
+	stmt, err := c.db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.stmts[query]; ok {
+		// prepared concurrently
+		stmt.Close()
+		c.lru.MoveToFront(s.elem)
+		s.refs++
+		return s, nil
+	}
+	s := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	s.elem = c.lru.PushFront(s)
+	c.stmts[query] = s
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back().Value.(*cachedStmt))
+	}
+	return s, nil
+}
+
+// release releases a statement returned by acquire
+func (c *stmtCache) release(s *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s.refs--
+	if s.evicted && s.refs == 0 {
+		s.stmt.Close()
+	}
+}
+
+// evict removes a statement from the cache, so the query is prepared again
+// the next time it is run
+func (c *stmtCache) evict(s *cachedStmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(s)
+}
+
+// remove evicts a statement, c.mu being locked
+func (c *stmtCache) remove(s *cachedStmt) {
+	if s.evicted {
+		return
+	}
+	s.evicted = true
+	c.lru.Remove(s.elem)
+	delete(c.stmts, s.query)
+	if s.refs == 0 {
+		s.stmt.Close()
+	}
+}
+
+// Len returns the number of statements in the cache
+func (c *stmtCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Close evicts all the statements of the cache
+func (c *stmtCache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.lru.Len() != 0 {
+		c.remove(c.lru.Back().Value.(*cachedStmt))
+	}
+}
+
+// isConnError returns true if an error is caused by a broken connection,
+// which may have lost its prepared statements
+func isConnError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
+
+// run runs a query with its prepared statement. After a connection error,
+// the statement is evicted so it is prepared again, and the query is retried
+// once if the database tells it was not sent.
+func (c *stmtCache) run(ctx context.Context, query string, f func(stmt *sqlx.Stmt) error) error {
+	for retry := false; ; retry = true {
+		s, err := c.acquire(ctx, query)
+		if err != nil {
+			return err
+		}
+		err = f(s.stmt)
+		c.release(s)
+		if err == nil || !isConnError(err) {
+			return err
+		}
+		c.evict(s)
+		if retry || !errors.Is(err, driver.ErrBadConn) {
+			return err
+		}
+	}
+}
+
+// ExecContext implements runner.ExecContext
+func (c *stmtCache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var res sql.Result
+	err := c.run(ctx, query, func(stmt *sqlx.Stmt) (err error) {
+		res, err = stmt.ExecContext(ctx, args...)
+		return err
+	})
+	return res, err
+}
+
+// QueryRowContext implements runner.QueryRowContext. As the error of the
+// row is only known when it is scanned, the query is not retried.
+func (c *stmtCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	s, err := c.acquire(ctx, query)
+	if err != nil {
+		// let the database report the error with the row
+		return c.db.QueryRowContext(ctx, query, args...)
+	}
+	defer c.release(s)
+	return s.stmt.QueryRowContext(ctx, args...)
+}
+
+// QueryContext implements runner.QueryContext
+func (c *stmtCache) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := c.run(ctx, query, func(stmt *sqlx.Stmt) (err error) {
+		rows, err = stmt.QueryContext(ctx, args...)
+		return err
+	})
+	return rows, err
+}
+
+// GetContext implements runner.GetContext
+func (c *stmtCache) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return c.run(ctx, query, func(stmt *sqlx.Stmt) error {
+		return stmt.GetContext(ctx, dest, args...)
+	})
+}
+
+// SelectContext implements runner.SelectContext
+func (c *stmtCache) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return c.run(ctx, query, func(stmt *sqlx.Stmt) error {
+		return stmt.SelectContext(ctx, dest, args...)
+	})
+}
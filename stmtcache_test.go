@@ -0,0 +1,60 @@
+package qb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStmtCache(t *testing.T) {
+	db, err := sqlx.Open("sqlite3", ":memory:")
+	assert.Nil(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	var stats StmtCacheStats
+	cache := newStmtCache(db, 2, &stats)
+
+	var n int
+	assert.Nil(t, cache.GetContext(ctx, &n, "SELECT 1"))
+	assert.Nil(t, cache.GetContext(ctx, &n, "SELECT 2"))
+	assert.Nil(t, cache.GetContext(ctx, &n, "SELECT 1"))
+	assert.Equal(t, 1, n)
+	assert.Equal(t, StmtCacheStats{Hits: 1, Misses: 2}, stats)
+
+	// SELECT 2 is the least recently used statement
+	assert.Nil(t, cache.GetContext(ctx, &n, "SELECT 3"))
+	assert.Equal(t, 2, cache.Len())
+	assert.Nil(t, cache.GetContext(ctx, &n, "SELECT 1"))
+	assert.Nil(t, cache.GetContext(ctx, &n, "SELECT 2"))
+	assert.Equal(t, StmtCacheStats{Hits: 2, Misses: 4}, stats)
+
+	// an evicted statement in use is closed when released
+	s, err := cache.acquire(ctx, "SELECT 4")
+	assert.Nil(t, err)
+	cache.Close()
+	assert.Equal(t, 0, cache.Len())
+	assert.Nil(t, s.stmt.GetContext(ctx, &n))
+	assert.Equal(t, 4, n)
+	cache.release(s)
+	assert.NotNil(t, s.stmt.GetContext(ctx, &n))
+
+	// a statement that cannot be prepared is not cached
+	assert.NotNil(t, cache.GetContext(ctx, &n, "SELECT FROM"))
+	assert.Equal(t, 0, cache.Len())
+	assert.NotNil(t, cache.QueryRowContext(ctx, "SELECT FROM").Scan(&n))
+}
+
+func TestIsConnError(t *testing.T) {
+	assert.True(t, isConnError(driver.ErrBadConn))
+	assert.True(t, isConnError(fmt.Errorf("read: %w", io.ErrUnexpectedEOF)))
+	assert.False(t, isConnError(errors.New("syntax error")))
+	assert.False(t, isConnError(nil))
+}
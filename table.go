@@ -9,7 +9,7 @@ import (
 func Table(name string, clauses ...TableSQLClause) TableElem {
 	table := TableElem{
 		Name:                  name,
-		Columns:               map[string]ColumnElem{},
+		columnIndex:           map[string]int{},
 		ForeignKeyConstraints: ForeignKeyConstraints{},
 		Indices:               []IndexElem{},
 	}
@@ -24,7 +24,7 @@ func Table(name string, clauses ...TableSQLClause) TableElem {
 				pkeyCols = append(pkeyCols, col)
 			}
 			col.Table = name
-			table.Columns[col.Name] = col
+			table.setColumn(col)
 			break
 		case PrimaryKeyConstraint:
 			table.PrimaryKeyConstraint = clause.(PrimaryKeyConstraint)
@@ -49,9 +49,11 @@ func Table(name string, clauses ...TableSQLClause) TableElem {
 	}
 
 	if len(pkeyCols) > 0 && table.PrimaryKeyConstraint.Columns != nil {
-		panic(fmt.Sprintf("Table %s has both 'PrimaryKey()' columns (%#v) and a PrimaryKeyConstraint. Only only should be set", name, pkeyCols))
-	}
-	if len(pkeyCols) > 0 {
+		table.err = Error{
+			Code: ErrInterface,
+			Orig: fmt.Errorf("Table %s has both 'PrimaryKey()' columns (%#v) and a PrimaryKeyConstraint. Only only should be set", name, pkeyCols),
+		}
+	} else if len(pkeyCols) > 0 {
 		var pkeyNames []string
 		for _, col := range pkeyCols {
 			pkeyNames = append(pkeyNames, col.Name)
@@ -61,12 +63,12 @@ func Table(name string, clauses ...TableSQLClause) TableElem {
 
 	// Make sure the columns are flagged as primary key
 	for _, name := range table.PrimaryKeyConstraint.Columns {
-		table.Columns[name] = table.Columns[name].PrimaryKey()
+		table.setColumn(table.C(name).PrimaryKey())
 	}
 	if len(table.PrimaryKeyConstraint.Columns) == 1 {
 		// Make sure the column will inline the primary key
 		name := table.PrimaryKeyConstraint.Columns[0]
-		table.Columns[name] = table.Columns[name].inlinePrimaryKey()
+		table.setColumn(table.C(name).inlinePrimaryKey())
 	}
 
 	return table
@@ -74,12 +76,16 @@ func Table(name string, clauses ...TableSQLClause) TableElem {
 
 // TableElem is the definition of any sql table
 type TableElem struct {
-	Name                  string
-	Columns               map[string]ColumnElem
+	Name string
+	// Columns are the columns of the table, in declaration order
+	Columns               []ColumnElem
+	columnIndex           map[string]int
 	PrimaryKeyConstraint  PrimaryKeyConstraint
 	ForeignKeyConstraints ForeignKeyConstraints
 	UniqueKeyConstraint   UniqueKeyConstraint
 	Indices               []IndexElem
+
+	err error
 }
 
 // DefaultName returns the name of the table
@@ -87,6 +93,17 @@ func (t TableElem) DefaultName() string {
 	return t.Name
 }
 
+// setColumn replaces the column having the same name, or appends the
+// column if the table has no such column
+func (t *TableElem) setColumn(col ColumnElem) {
+	if i, ok := t.columnIndex[col.Name]; ok {
+		t.Columns[i] = col
+		return
+	}
+	t.columnIndex[col.Name] = len(t.Columns)
+	t.Columns = append(t.Columns, col)
+}
+
 // All returns all columns of table as a column slice
 func (t TableElem) All() []Clause {
 	cols := []Clause{}
@@ -105,6 +122,16 @@ func (t TableElem) ColumnList() []ColumnElem {
 	return cols
 }
 
+// ColumnNames returns the names of the columns of the table, in
+// declaration order
+func (t TableElem) ColumnNames() []string {
+	names := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
 // Index appends an IndexElem to current table without giving table name
 func (t TableElem) Index(cols ...string) TableElem {
 	t.Indices = append(t.Indices, Index(t.Name, cols...))
@@ -151,14 +178,61 @@ func (t TableElem) Create(dialect Dialect) string {
 	return strings.Join(sqls, "\n")
 }
 
-// Build generates a Statement object out of table ddl
+// Build generates a Statement object out of table ddl.
+// The statement holds an error if the table definition is invalid
 func (t TableElem) Build(dialect Dialect) *Stmt {
-	sql := t.Create(dialect)
 	statement := Statement()
+	if d, ok := dialect.(*DefaultDialect); ok && d.err != nil {
+		statement.SetError(d.err)
+		return statement
+	}
+	if err := t.Err(dialect); err != nil {
+		statement.SetError(err)
+		return statement
+	}
+	sql := t.Create(dialect)
 	statement.AddSQLClause(strings.Trim(sql, ";")) // TODO: Remove this ugly hack
 	return statement
 }
 
+// ColumnChecker is an optional Dialect capability: the dialects not
+// supporting some column definitions tell why, so the tables having them
+// fail to build
+type ColumnChecker interface {
+	CheckColumn(column ColumnElem) error
+}
+
+// Err returns the error that makes the table definition invalid for a
+// dialect, if any
+func (t TableElem) Err(dialect Dialect) error {
+	if t.err != nil {
+		return t.err
+	}
+	for _, fkey := range t.ForeignKeyConstraints.FKeys {
+		if err := fkey.Err(); err != nil {
+			return err
+		}
+	}
+	for _, col := range t.Columns {
+		if err := ColumnErr(dialect, col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ColumnErr returns the error that makes the column definition invalid for
+// a dialect, if any
+func ColumnErr(dialect Dialect, column ColumnElem) error {
+	if column.err != nil {
+		return column.err
+	}
+	if checker, ok := dialect.(ColumnChecker); ok {
+		return checker.CheckColumn(column)
+	}
+	return nil
+}
+
 // PrimaryCols returns the columns that are primary key to the table
 func (t TableElem) PrimaryCols() []ColumnElem {
 	primaryCols := []ColumnElem{}
@@ -178,7 +252,26 @@ func (t TableElem) Drop(dialect Dialect) string {
 
 // C returns the column name given col
 func (t TableElem) C(name string) ColumnElem {
-	return t.Columns[name]
+	col, ok := t.Lookup(name)
+	if !ok {
+		return unknownColumn(name, t.Name, "No such column '%s' in table %s", name, t.Name)
+	}
+	return col
+}
+
+// Lookup returns the column having the given name, and whether the table
+// has such a column
+func (t TableElem) Lookup(name string) (ColumnElem, bool) {
+	if i, ok := t.columnIndex[name]; ok && i < len(t.Columns) && t.Columns[i].Name == name {
+		return t.Columns[i], true
+	}
+	// the table was not built by Table
+	for _, col := range t.Columns {
+		if col.Name == name {
+			return col, true
+		}
+	}
+	return ColumnElem{}, false
 }
 
 // query starters
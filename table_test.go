@@ -33,6 +33,32 @@ func (suite *TableTestSuite) TestTableSimpleCreate() {
 	assert.Equal(suite.T(), []interface{}{}, statement.Bindings())
 }
 
+func (suite *TableTestSuite) TestTableColumnsOrder() {
+	usersTable := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("name", Varchar()),
+		Column("age", Int()),
+		PrimaryKey("id"),
+	)
+	assert.Equal(suite.T(), []string{"id", "email", "name", "age"}, usersTable.ColumnNames())
+	assert.Equal(suite.T(),
+		"CREATE TABLE users (\n\tid INT PRIMARY KEY,\n\temail VARCHAR(255),\n\tname VARCHAR(255),\n\tage INT\n);",
+		usersTable.Create(suite.dialect))
+
+	col, ok := usersTable.Lookup("name")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "users", col.Table)
+	_, ok = usersTable.Lookup("nickname")
+	assert.False(suite.T(), ok)
+	assert.NotNil(suite.T(), usersTable.C("nickname").Err())
+
+	// a table that is not built by Table has no index
+	copied := TableElem{Name: "users", Columns: usersTable.Columns}
+	assert.Equal(suite.T(), usersTable.C("age"), copied.C("age"))
+}
+
 func (suite *TableTestSuite) TestTableSimpleDrop() {
 	usersTable := Table("users", Column("id", Varchar().Size(40)))
 
@@ -63,6 +89,18 @@ func (suite *TableTestSuite) TestTablePrimaryForeignKey() {
 	assert.Contains(suite.T(), ddl, ");")
 }
 
+func (suite *TableTestSuite) TestTableInvalidForeignKeyAction() {
+	stmt := Table(
+		"sessions",
+		Column("user_id", BigInt()),
+		ForeignKey("user_id").References("users", "id").OnDelete("explode"),
+	).Build(suite.dialect)
+	assert.Equal(suite.T(), "", stmt.SQL())
+	qbErr, ok := stmt.Err().(Error)
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), ErrInterface, qbErr.Code)
+}
+
 func (suite *TableTestSuite) TestTableSimplePrimaryKey() {
 	users := Table(
 		"users",
@@ -88,13 +126,13 @@ func (suite *TableTestSuite) TestTableCompositePrimaryKey() {
 	ddl := users.Create(suite.dialect)
 	assert.Contains(suite.T(), ddl, "PRIMARY KEY(fname, lname)")
 
-	assert.Panics(suite.T(), func() {
-		Table(
-			"users",
-			Column("id", Varchar().Size(40)).PrimaryKey(),
-			PrimaryKey("id"),
-		)
-	})
+	stmt := Table(
+		"users",
+		Column("id", Varchar().Size(40)).PrimaryKey(),
+		PrimaryKey("id"),
+	).Build(suite.dialect)
+	assert.Equal(suite.T(), "", stmt.SQL())
+	assert.Error(suite.T(), stmt.Err())
 }
 
 func (suite *TableTestSuite) TestTableUniqueCompositeUnique() {
@@ -123,6 +161,7 @@ func (suite *TableTestSuite) TestTableIndex() {
 		Index("users", "id"),
 		Index("users", "email"),
 		Index("users", "id", "email"),
+		IndexElem{Table: "users", Name: "u_email", Columns: []string{"email"}, Unique: true},
 	)
 	ddl := usersTable.Create(suite.dialect)
 	assert.Contains(suite.T(), ddl, "CREATE TABLE users (")
@@ -132,9 +171,10 @@ func (suite *TableTestSuite) TestTableIndex() {
 	assert.Contains(suite.T(), ddl, "CREATE INDEX i_id ON users(id)")
 	assert.Contains(suite.T(), ddl, "CREATE INDEX i_email ON users(email)")
 	assert.Contains(suite.T(), ddl, "CREATE INDEX i_id_email ON users(id, email);")
+	assert.Contains(suite.T(), ddl, "CREATE UNIQUE INDEX u_email ON users(email);")
 
 	assert.Equal(suite.T(), ColumnElem{Name: "id", Type: Varchar().Size(40), Table: "users"}, usersTable.C("id"))
-	assert.Zero(suite.T(), usersTable.C("nonExisting"))
+	assert.NotNil(suite.T(), usersTable.C("nonExisting").Err())
 }
 
 func (suite *TableTestSuite) TestTableIndexChain() {
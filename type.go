@@ -2,6 +2,7 @@ package qb
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -134,6 +135,44 @@ func DefaultCompileType(t TypeElem, supportsUnsigned bool) string {
 	return name
 }
 
+// ParseType parses a SQL type declaration like "VARCHAR(255)" or
+// "DECIMAL(10, 2) UNSIGNED" into a TypeElem.
+// It is a helper for the dialects implementing the schema reflection.
+func ParseType(decl string) TypeElem {
+	decl = strings.TrimSpace(decl)
+	name := decl
+	unsigned := false
+	if strings.HasSuffix(strings.ToUpper(name), " UNSIGNED") {
+		unsigned = true
+		name = strings.TrimSpace(name[:len(name)-len(" UNSIGNED")])
+	}
+
+	var args []int
+	if i := strings.Index(name, "("); i != -1 && strings.HasSuffix(name, ")") {
+		for _, arg := range strings.Split(name[i+1:len(name)-1], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(arg))
+			if err != nil {
+				// not a size or precision, like in ENUM('a', 'b')
+				return Type(decl)
+			}
+			args = append(args, n)
+		}
+		name = strings.TrimSpace(name[:i])
+	}
+
+	t := Type(strings.ToUpper(name))
+	switch len(args) {
+	case 1:
+		t = t.Size(args[0])
+	case 2:
+		t = t.Precision(args[0], args[1])
+	}
+	if unsigned {
+		t = t.Unsigned()
+	}
+	return t
+}
+
 // Size adds size constraint to column type
 func (t TypeElem) Size(size int) TypeElem {
 	t.size = size
@@ -44,6 +44,16 @@ func (suite *TypeTestSuite) TestUnsigned() {
 	assert.Equal(suite.T(), "BIGINT", DefaultCompileType(BigInt().Unsigned(), false))
 }
 
+func (suite *TypeTestSuite) TestParseType() {
+	assert.Equal(suite.T(), Int(), ParseType("int"))
+	assert.Equal(suite.T(), Varchar(), ParseType("VARCHAR(255)"))
+	assert.Equal(suite.T(), Decimal().Precision(10, 2), ParseType(" decimal(10, 2) "))
+	assert.Equal(suite.T(), BigInt().Size(20).Unsigned(), ParseType("bigint(20) unsigned"))
+	assert.Equal(suite.T(), Type("DOUBLE PRECISION"), ParseType("double precision"))
+	assert.Equal(suite.T(), Type("enum('a', 'b')"), ParseType("enum('a', 'b')"))
+	assert.Equal(suite.T(), Type(""), ParseType(""))
+}
+
 func TestTypeTestSuite(t *testing.T) {
 	suite.Run(t, new(TypeTestSuite))
 }
@@ -1,5 +1,7 @@
 package qb
 
+import "reflect"
+
 // Update generates an update statement and returns it
 // qb.Update(usersTable).
 // Values(map[string]interface{}{"id": 1}).
@@ -7,17 +9,25 @@ package qb
 func Update(table TableElem) UpdateStmt {
 	return UpdateStmt{
 		table:     table,
-		values:    map[string]interface{}{},
 		returning: []ColumnElem{},
 	}
 }
 
 // UpdateStmt is the base struct for any update statements
 type UpdateStmt struct {
+	with      []CTEClause
 	table     TableElem
-	values    map[string]interface{}
+	values    ValueSet
 	returning []ColumnElem
 	where     *WhereClause
+	err       error
+}
+
+// With appends common table expressions to the WITH clause of the update
+// statement
+func (s UpdateStmt) With(ctes ...CTEClause) UpdateStmt {
+	s.with = append(s.with[:len(s.with):len(s.with)], ctes...)
+	return s
 }
 
 // Accept implements Clause.Accept
@@ -29,17 +39,50 @@ func (s UpdateStmt) Accept(context *CompilerContext) string {
 func (s UpdateStmt) Build(dialect Dialect) *Stmt {
 	context := NewCompilerContext(dialect)
 	statement := Statement()
-	statement.AddSQLClause(s.Accept(context))
+	if s.err != nil {
+		statement.SetError(s.err)
+		return statement
+	}
+	sql := s.Accept(context)
+	if err := context.Err(); err != nil {
+		statement.SetError(err)
+		return statement
+	}
+	statement.AddSQLClause(sql)
 	statement.AddBinding(context.Binds...)
 
 	return statement
 }
 
-// Values accepts map[string]interface{} and forms the values map of insert statement
+// Values accepts map[string]interface{} and forms the values map of update statement.
+// The columns are set in their declaration order.
+// A value that is a clause, like users.C("score").Add(1), is compiled
+// instead of being bound.
 func (s UpdateStmt) Values(values map[string]interface{}) UpdateStmt {
-	for k, v := range values {
-		s.values[s.table.C(k).Name] = v
+	s.values = s.values.SetMap(s.table, values)
+	return s
+}
+
+// Set sets the value of a column, the columns being set in the order of the
+// calls
+func (s UpdateStmt) Set(column string, value interface{}) UpdateStmt {
+	s.values = s.values.Set(column, value)
+	return s
+}
+
+// ValuesFrom forms the values map of update statement from the fields of a
+// struct, mapped to the columns as with TableFromStruct.
+// The primary keys, the fields tagged 'readonly', and the fields tagged
+// 'omitempty' holding a zero value are skipped.
+func (s UpdateStmt) ValuesFrom(model interface{}) UpdateStmt {
+	values, err := structValues(model, func(field structField, value reflect.Value) bool {
+		return field.has("pk")
+	})
+	if err != nil {
+		s.err = err
+		return s
 	}
+	s.values = s.values.Merge(values)
 	return s
 }
 
@@ -40,6 +40,19 @@ func (suite *UpdateTestSuite) TestUpdateSimple() {
 	assert.Equal(suite.T(), []interface{}{"robert@de.niro"}, binds)
 }
 
+func (suite *UpdateTestSuite) TestUpdateValuesOrder() {
+	sql := Update(suite.users).
+		Values(map[string]interface{}{"email": "robert@de.niro", "id": 1}).
+		Accept(suite.ctx)
+	assert.Equal(suite.T(), "UPDATE users\nSET id = ?, email = ?", sql)
+	assert.Equal(suite.T(), []interface{}{1, "robert@de.niro"}, suite.ctx.Binds)
+
+	suite.ctx = NewCompilerContext(suite.dialect)
+	sql = Update(suite.users).Set("email", "robert@de.niro").Set("id", 1).Accept(suite.ctx)
+	assert.Equal(suite.T(), "UPDATE users\nSET email = ?, id = ?", sql)
+	assert.Equal(suite.T(), []interface{}{"robert@de.niro", 1}, suite.ctx.Binds)
+}
+
 func (suite *UpdateTestSuite) TestUpdateWhereReturning() {
 	sql := Update(suite.users).
 		Values(map[string]interface{}{"email": "robert@de.niro"}).
@@ -1,10 +1,11 @@
 package qb
 
+import "reflect"
+
 // Upsert generates an insert ... on (duplicate key/conflict) update statement
 func Upsert(table TableElem) UpsertStmt {
 	return UpsertStmt{
 		Table:         table,
-		ValuesMap:     map[string]interface{}{},
 		ReturningCols: []ColumnElem{},
 	}
 }
@@ -12,15 +13,39 @@ func Upsert(table TableElem) UpsertStmt {
 // UpsertStmt is the base struct for any insert ... on conflict/duplicate key ... update ... statements
 type UpsertStmt struct {
 	Table         TableElem
-	ValuesMap     map[string]interface{}
+	ValueSet      ValueSet
 	ReturningCols []ColumnElem
+	err           error
 }
 
-// Values accepts map[string]interface{} and forms the values map of insert statement
+// Values accepts map[string]interface{} and forms the values map of insert statement.
+// The values are inserted in the declaration order of the columns.
 func (s UpsertStmt) Values(values map[string]interface{}) UpsertStmt {
-	for k, v := range values {
-		s.ValuesMap[k] = v
+	s.ValueSet = s.ValueSet.SetMap(s.Table, values)
+	return s
+}
+
+// Set sets the value of a column, the values being inserted in the order
+// they are set
+func (s UpsertStmt) Set(column string, value interface{}) UpsertStmt {
+	s.ValueSet = s.ValueSet.Set(column, value)
+	return s
+}
+
+// ValuesFrom forms the values map of upsert statement from the fields of a
+// struct, mapped to the columns as with TableFromStruct.
+// The auto increment primary keys holding a zero value, the fields tagged
+// 'readonly', and the fields tagged 'omitempty' holding a zero value are
+// skipped.
+func (s UpsertStmt) ValuesFrom(model interface{}) UpsertStmt {
+	values, err := structValues(model, func(field structField, value reflect.Value) bool {
+		return isGeneratedKey(field) && value.IsZero()
+	})
+	if err != nil {
+		s.err = err
+		return s
 	}
+	s.ValueSet = s.ValueSet.Merge(values)
 	return s
 }
 
@@ -42,7 +67,16 @@ func (s UpsertStmt) Accept(context *CompilerContext) string {
 func (s UpsertStmt) Build(dialect Dialect) *Stmt {
 	context := NewCompilerContext(dialect)
 	statement := Statement()
-	statement.AddSQLClause(s.Accept(context))
+	if s.err != nil {
+		statement.SetError(s.err)
+		return statement
+	}
+	sql := s.Accept(context)
+	if err := context.Err(); err != nil {
+		statement.SetError(err)
+		return statement
+	}
+	statement.AddSQLClause(sql)
 	statement.AddBinding(context.Binds...)
 
 	return statement
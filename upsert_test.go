@@ -25,9 +25,11 @@ func TestUpsert(t *testing.T) {
 		"created_at": now,
 	})
 
-	assert.Panics(t, func() {
-		ups.Build(def)
-	})
+	stmt := ups.Build(def)
+	assert.Equal(t, "", stmt.SQL())
+	qbErr, ok := stmt.Err().(Error)
+	assert.True(t, ok)
+	assert.Equal(t, ErrNotSupported, qbErr.Code)
 
 	ups = ups.Returning(users.C("email"))
 	assert.Equal(t, []ColumnElem{users.C("email")}, ups.ReturningCols)
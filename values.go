@@ -0,0 +1,86 @@
+package qb
+
+import "sort"
+
+// ColumnValue is the value of a column in a ValueSet
+type ColumnValue struct {
+	Column string
+	Value  interface{}
+}
+
+// ValueSet is the list of the column values of an insert, update or upsert
+// statement, in the order in which they were set
+type ValueSet []ColumnValue
+
+// Set returns a value set with the value of a column replaced, or appended
+// if the set has no value for this column
+func (vs ValueSet) Set(column string, value interface{}) ValueSet {
+	for i, cv := range vs {
+		if cv.Column == column {
+			set := append(ValueSet{}, vs...)
+			set[i].Value = value
+			return set
+		}
+	}
+	return append(vs[:len(vs):len(vs)], ColumnValue{column, value})
+}
+
+// Get returns the value of a column, and whether the set has a value for
+// this column
+func (vs ValueSet) Get(column string) (interface{}, bool) {
+	for _, cv := range vs {
+		if cv.Column == column {
+			return cv.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Columns returns the names of the columns of the set
+func (vs ValueSet) Columns() []string {
+	columns := make([]string, len(vs))
+	for i, cv := range vs {
+		columns[i] = cv.Column
+	}
+	return columns
+}
+
+// Map returns the values of the set by column name
+func (vs ValueSet) Map() map[string]interface{} {
+	values := make(map[string]interface{}, len(vs))
+	for _, cv := range vs {
+		values[cv.Column] = cv.Value
+	}
+	return values
+}
+
+// Merge returns a value set with the values of another set, set in order
+// as with Set
+func (vs ValueSet) Merge(other ValueSet) ValueSet {
+	for _, cv := range other {
+		vs = vs.Set(cv.Column, cv.Value)
+	}
+	return vs
+}
+
+// SetMap returns a value set with the values of a map set. As the order of a
+// map is random, the values are set in the declaration order of the columns
+// of the table, followed by the unknown columns sorted by name.
+func (vs ValueSet) SetMap(table TableElem, values map[string]interface{}) ValueSet {
+	for _, col := range table.Columns {
+		if v, ok := values[col.Name]; ok {
+			vs = vs.Set(col.Name, v)
+		}
+	}
+	var unknown []string
+	for name := range values {
+		if _, ok := table.Lookup(name); !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		vs = vs.Set(name, values[name])
+	}
+	return vs
+}
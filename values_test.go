@@ -0,0 +1,36 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueSet(t *testing.T) {
+	users := Table(
+		"users",
+		Column("id", Int()),
+		Column("email", Varchar()),
+		Column("name", Varchar()),
+	)
+
+	var values ValueSet
+	values = values.Set("name", "Al")
+	values = values.SetMap(users, map[string]interface{}{"zip": 1, "email": "al@pacino.com", "id": 2, "age": 3})
+	assert.Equal(t, []string{"name", "id", "email", "age", "zip"}, values.Columns())
+
+	updated := values.Set("id", 5)
+	v, ok := updated.Get("id")
+	assert.True(t, ok)
+	assert.Equal(t, 5, v)
+	v, _ = values.Get("id")
+	assert.Equal(t, 2, v)
+	_, ok = values.Get("nickname")
+	assert.False(t, ok)
+
+	merged := ValueSet{{"email", "x"}, {"role", "admin"}}.Merge(values)
+	assert.Equal(t, []string{"email", "role", "name", "id", "age", "zip"}, merged.Columns())
+	assert.Equal(t, map[string]interface{}{
+		"email": "al@pacino.com", "role": "admin", "name": "Al", "id": 2, "age": 3, "zip": 1,
+	}, merged.Map())
+}
@@ -0,0 +1,199 @@
+package qb
+
+import "fmt"
+
+// RowNumber generates a ROW_NUMBER() window function
+func RowNumber() WindowFunctionClause {
+	return WindowFunction("ROW_NUMBER")
+}
+
+// Rank generates a RANK() window function
+func Rank() WindowFunctionClause {
+	return WindowFunction("RANK")
+}
+
+// DenseRank generates a DENSE_RANK() window function
+func DenseRank() WindowFunctionClause {
+	return WindowFunction("DENSE_RANK")
+}
+
+// Lag generates a LAG(clause, offset) window function, returning the value
+// of the row at offset rows before the current row
+func Lag(clause Clause, offset int) WindowFunctionClause {
+	return WindowFunction("LAG", clause, SQLText(fmt.Sprint(offset)))
+}
+
+// Lead generates a LEAD(clause, offset) window function, returning the value
+// of the row at offset rows after the current row
+func Lead(clause Clause, offset int) WindowFunctionClause {
+	return WindowFunction("LEAD", clause, SQLText(fmt.Sprint(offset)))
+}
+
+// FirstValue generates a FIRST_VALUE(clause) window function
+func FirstValue(clause Clause) WindowFunctionClause {
+	return WindowFunction("FIRST_VALUE", clause)
+}
+
+// WindowFunction generates a window function given its name and arguments.
+// It has to be called over a window
+func WindowFunction(fn string, args ...Clause) WindowFunctionClause {
+	return WindowFunctionClause{fn, args}
+}
+
+// WindowFunctionClause is the base struct for building window functions
+type WindowFunctionClause struct {
+	fn   string
+	args []Clause
+}
+
+// Accept compiles the function call, without its window
+func (c WindowFunctionClause) Accept(context *CompilerContext) string {
+	return fmt.Sprintf("%s(%s)", c.fn, ListClause{c.args}.Accept(context))
+}
+
+// Over calls the window function over a window
+func (c WindowFunctionClause) Over(window WindowClause) OverClause {
+	return OverClause{c, window}
+}
+
+// Over calls the aggregate function over a window
+// Sum(sales.C("amount")).Over(Window().PartitionBy(sales.C("region")))
+func (c AggregateClause) Over(window WindowClause) OverClause {
+	return OverClause{c, window}
+}
+
+// OverClause is a function called over a window
+type OverClause struct {
+	Function Clause
+	Window   WindowClause
+}
+
+// Accept compiles the function followed by OVER and its window
+func (c OverClause) Accept(context *CompilerContext) string {
+	return fmt.Sprintf("%s OVER %s", c.Function.Accept(context), c.Window.Accept(context))
+}
+
+// Window starts a window specification
+// Window().PartitionBy(users.C("group_id")).OrderBy(users.C("created_at"))
+func Window() WindowClause {
+	return WindowClause{}
+}
+
+// WindowName returns a reference to a window defined by SelectStmt.Window.
+// It can be refined with OrderBy and a frame if the named window has none.
+func WindowName(name string) WindowClause {
+	return WindowClause{Name: name}
+}
+
+// WindowClause is a window specification
+type WindowClause struct {
+	// Name is the name of the window it refines, if any
+	Name      string
+	Partition []Clause
+	Order     []OrderTermClause
+	Frame     *WindowFrame
+}
+
+// PartitionBy appends clauses to the PARTITION BY of the window
+func (w WindowClause) PartitionBy(clauses ...Clause) WindowClause {
+	w.Partition = append(w.Partition[:len(w.Partition):len(w.Partition)], clauses...)
+	return w
+}
+
+// OrderBy appends terms to the ORDER BY of the window. As with
+// SelectStmt.OrderBy, the terms can be given a direction with Asc and Desc
+func (w WindowClause) OrderBy(clauses ...Clause) WindowClause {
+	w.Order = append(w.Order[:len(w.Order):len(w.Order)], orderTerms(clauses, "")...)
+	return w
+}
+
+// Asc sorts the terms of the ORDER BY of the window in ascending order,
+// except the terms given a direction with the Asc and Desc functions
+func (w WindowClause) Asc() WindowClause {
+	w.Order = setDirection(w.Order, "ASC")
+	return w
+}
+
+// Desc sorts the terms of the ORDER BY of the window in descending order,
+// except the terms given a direction with the Asc and Desc functions
+func (w WindowClause) Desc() WindowClause {
+	w.Order = setDirection(w.Order, "DESC")
+	return w
+}
+
+// Rows sets a ROWS frame on the window
+// Window().OrderBy(sales.C("day")).Rows(Preceding(6), CurrentRow)
+func (w WindowClause) Rows(start FrameBound, end FrameBound) WindowClause {
+	w.Frame = &WindowFrame{"ROWS", start, end}
+	return w
+}
+
+// Range sets a RANGE frame on the window
+func (w WindowClause) Range(start FrameBound, end FrameBound) WindowClause {
+	w.Frame = &WindowFrame{"RANGE", start, end}
+	return w
+}
+
+// Groups sets a GROUPS frame on the window
+func (w WindowClause) Groups(start FrameBound, end FrameBound) WindowClause {
+	w.Frame = &WindowFrame{"GROUPS", start, end}
+	return w
+}
+
+// Accept calls the compiler VisitWindow function
+func (w WindowClause) Accept(context *CompilerContext) string {
+	return context.Compiler.VisitWindow(context, w)
+}
+
+// WindowFrame is the frame of a window: ROWS, RANGE or GROUPS
+type WindowFrame struct {
+	Mode  string
+	Start FrameBound
+	End   FrameBound
+}
+
+// FrameBound is the start or the end of a window frame
+type FrameBound struct {
+	// Offset is the number of rows (or the range) before or after the
+	// current row, -1 meaning unbounded
+	Offset int
+	// Direction is either PRECEDING or FOLLOWING, or empty for the current
+	// row
+	Direction string
+}
+
+// The unbounded and current row frame bounds
+var (
+	UnboundedPreceding = FrameBound{-1, "PRECEDING"}
+	CurrentRow         = FrameBound{0, ""}
+	UnboundedFollowing = FrameBound{-1, "FOLLOWING"}
+)
+
+// Preceding returns the frame bound of n rows before the current row
+func Preceding(n int) FrameBound {
+	return FrameBound{n, "PRECEDING"}
+}
+
+// Following returns the frame bound of n rows after the current row
+func Following(n int) FrameBound {
+	return FrameBound{n, "FOLLOWING"}
+}
+
+// String returns the SQL of the frame bound
+func (b FrameBound) String() string {
+	switch {
+	case b.Direction == "":
+		return "CURRENT ROW"
+	case b.Offset < 0:
+		return "UNBOUNDED " + b.Direction
+	default:
+		return fmt.Sprintf("%d %s", b.Offset, b.Direction)
+	}
+}
+
+// NamedWindowClause is a window defined in the WINDOW clause of a select
+// statement
+type NamedWindowClause struct {
+	Name   string
+	Window WindowClause
+}
@@ -0,0 +1,78 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowFunctions(t *testing.T) {
+	dialect := NewDefaultDialect()
+	sales := Table(
+		"sales",
+		Column("id", Int()).PrimaryKey(),
+		Column("region", Varchar()),
+		Column("day", Timestamp()),
+		Column("amount", Int()),
+	)
+
+	sel := Select(
+		sales.C("id"),
+		RowNumber().Over(Window().PartitionBy(sales.C("region")).OrderBy(sales.C("amount")).Desc()),
+		Rank().Over(Window().OrderBy(sales.C("amount"))),
+		DenseRank().Over(Window()),
+		Lag(sales.C("amount"), 1).Over(Window().OrderBy(sales.C("day"))),
+		Lead(sales.C("amount"), 2).Over(Window().OrderBy(sales.C("day"))),
+		FirstValue(sales.C("amount")).Over(Window().PartitionBy(sales.C("region"), sales.C("day"))),
+	).From(sales)
+	assert.Equal(t, "SELECT id, "+
+		"ROW_NUMBER() OVER (PARTITION BY region ORDER BY amount DESC), "+
+		"RANK() OVER (ORDER BY amount), "+
+		"DENSE_RANK() OVER (), "+
+		"LAG(amount, 1) OVER (ORDER BY day), "+
+		"LEAD(amount, 2) OVER (ORDER BY day), "+
+		"FIRST_VALUE(amount) OVER (PARTITION BY region, day)\n"+
+		"FROM sales;", sel.Build(dialect).SQL())
+
+	sel = Select(
+		Sum(sales.C("amount")).Over(Window().OrderBy(sales.C("day")).Rows(Preceding(6), CurrentRow)),
+		Avg(sales.C("amount")).Over(Window().OrderBy(sales.C("day")).Range(UnboundedPreceding, Following(1))),
+		Max(sales.C("amount")).Over(Window().OrderBy(sales.C("day")).Groups(CurrentRow, UnboundedFollowing)),
+	).From(sales)
+	assert.Equal(t, "SELECT "+
+		"SUM(amount) OVER (ORDER BY day ROWS BETWEEN 6 PRECEDING AND CURRENT ROW), "+
+		"AVG(amount) OVER (ORDER BY day RANGE BETWEEN UNBOUNDED PRECEDING AND 1 FOLLOWING), "+
+		"MAX(amount) OVER (ORDER BY day GROUPS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING)\n"+
+		"FROM sales;", sel.Build(dialect).SQL())
+}
+
+func TestNamedWindows(t *testing.T) {
+	dialect := NewDefaultDialect()
+	sales := Table(
+		"sales",
+		Column("region", Varchar()),
+		Column("amount", Int()),
+	)
+
+	sel := Select(
+		sales.C("region"),
+		Rank().Over(WindowName("w")),
+		Sum(sales.C("amount")).Over(WindowName("w").Rows(UnboundedPreceding, CurrentRow)),
+		Count(sales.C("amount")).Over(WindowName("r")),
+	).
+		From(sales).
+		Where(sales.C("amount").Gt(10)).
+		Window("w", Window().PartitionBy(sales.C("region")).OrderBy(sales.C("amount"))).
+		Window("r", WindowName("w")).
+		OrderBy(sales.C("region"))
+	stmt := sel.Build(dialect)
+	assert.Equal(t, "SELECT region, "+
+		"RANK() OVER w, "+
+		"SUM(amount) OVER (w ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW), "+
+		"COUNT(amount) OVER r\n"+
+		"FROM sales\n"+
+		"WHERE amount > ?\n"+
+		"WINDOW w AS (PARTITION BY region ORDER BY amount), r AS (w)\n"+
+		"ORDER BY region ASC;", stmt.SQL())
+	assert.Equal(t, []interface{}{10}, stmt.Bindings())
+}